@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os/signal"
 	"sync"
@@ -13,24 +15,148 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/kdv2001/onlySubscription/internal/clients/message/telegram_bot"
+	webhook "github.com/kdv2001/onlySubscription/internal/clients/webhook/http"
+	"github.com/kdv2001/onlySubscription/internal/domain/referral"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
 	telegramHandlers "github.com/kdv2001/onlySubscription/internal/handlers/telegram_bot"
 	orderPostgres "github.com/kdv2001/onlySubscription/internal/repositories/order/postgres"
 	paymentpostgres "github.com/kdv2001/onlySubscription/internal/repositories/payment/postgres"
 	productspostgres "github.com/kdv2001/onlySubscription/internal/repositories/products/postgres"
+	referralpostgres "github.com/kdv2001/onlySubscription/internal/repositories/referral/postgres"
 	subscriptionpostgres "github.com/kdv2001/onlySubscription/internal/repositories/subscription/postgres"
 	user "github.com/kdv2001/onlySubscription/internal/repositories/user/postgres"
+	webhookpostgres "github.com/kdv2001/onlySubscription/internal/repositories/webhook/postgres"
 	orderusecase "github.com/kdv2001/onlySubscription/internal/useCase/order"
 	paymentusecase "github.com/kdv2001/onlySubscription/internal/useCase/payment"
+	privacyusecase "github.com/kdv2001/onlySubscription/internal/useCase/privacy"
 	productsusecase "github.com/kdv2001/onlySubscription/internal/useCase/products"
+	referralusecase "github.com/kdv2001/onlySubscription/internal/useCase/referral"
 	subscriptionusecase "github.com/kdv2001/onlySubscription/internal/useCase/subscription"
 	userusecase "github.com/kdv2001/onlySubscription/internal/useCase/users"
+	webhookusecase "github.com/kdv2001/onlySubscription/internal/useCase/webhook"
 	"github.com/kdv2001/onlySubscription/pkg/config"
+	"github.com/kdv2001/onlySubscription/pkg/envelope"
 	"github.com/kdv2001/onlySubscription/pkg/logger"
 )
 
 type configValues struct {
 	TelegramToken string `env:"TELEGRAM_TOKEN" json:"telegram_token"`
 	PostgresDSN   string `env:"DATABASE_DSN" json:"database_dsn"`
+	// OwnerTelegramID telegram ID владельца магазина, получает роль owner при запуске или регистрации
+	OwnerTelegramID int64 `env:"OWNER_TELEGRAM_ID" json:"owner_telegram_id"`
+	// AdminChatIDs чаты администраторов для служебных уведомлений
+	AdminChatIDs []int64 `env:"ADMIN_CHAT_IDS" json:"admin_chat_ids"`
+	// PayloadKeys мастер-ключи шифрования полезной нагрузки в base64 по идентификаторам
+	PayloadKeys map[string]string `env:"PAYLOAD_KEYS" json:"payload_keys"`
+	// PayloadActiveKeyID идентификатор ключа, которым шифруется новая полезная нагрузка
+	PayloadActiveKeyID string `env:"PAYLOAD_ACTIVE_KEY_ID" json:"payload_active_key_id"`
+	// PostSale настройки обработки проданных единиц инвентаря
+	PostSale postSaleValues `json:"post_sale"`
+	// RenewalReminders за сколько до окончания подписки напоминать о продлении, в формате time.Duration
+	RenewalReminders []string `json:"renewal_reminders"`
+	// GracePeriod льготный период после окончания подписки в формате time.Duration, пусто - без льготного периода
+	GracePeriod string `json:"grace_period"`
+	// Webhooks получатели событий жизненного цикла подписок
+	Webhooks []webhookValues `json:"webhooks"`
+	// Referral награда за приглашение пользователей
+	Referral referralValues `json:"referral"`
+}
+
+type referralValues struct {
+	// Reward награда за оплаченный заказ приглашенного: days, discount или пусто - без награды
+	Reward string `json:"reward"`
+	// BonusDays кол-во бонусных дней к подписке для награды days
+	BonusDays int `json:"bonus_days"`
+	// DiscountPercent процент скидки на следующий заказ для награды discount
+	DiscountPercent int `json:"discount_percent"`
+}
+
+// newReferralPolicy собирает настройки награды за приглашение
+func newReferralPolicy(values referralValues) (referral.Policy, error) {
+	kind, ok := referral.RewardKindFromString(values.Reward)
+	if !ok {
+		return referral.Policy{}, fmt.Errorf("unknown referral reward %q", values.Reward)
+	}
+
+	policy := referral.Policy{
+		Kind:            kind,
+		Days:            values.BonusDays,
+		DiscountPercent: values.DiscountPercent,
+	}
+
+	return policy, policy.Validate()
+}
+
+type webhookValues struct {
+	// Name уникальное название получателя
+	Name string `json:"name"`
+	// URL адрес, на который отправляются события
+	URL string `json:"url"`
+	// Secret секрет подписи событий HMAC-SHA256
+	Secret string `json:"secret"`
+}
+
+// newWebhookEndpoints собирает получателей событий
+func newWebhookEndpoints(values []webhookValues) ([]webhookusecase.Endpoint, error) {
+	endpoints := make([]webhookusecase.Endpoint, 0, len(values))
+	names := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		if v.Name == "" || v.URL == "" {
+			return nil, errors.New("webhook name and url are required")
+		}
+		if _, ok := names[v.Name]; ok {
+			return nil, fmt.Errorf("duplicate webhook name %q", v.Name)
+		}
+		names[v.Name] = struct{}{}
+
+		endpoints = append(endpoints, webhookusecase.Endpoint{
+			Name:   v.Name,
+			Sender: webhook.NewImplementation(v.URL, v.Secret),
+		})
+	}
+
+	return endpoints, nil
+}
+
+// parseDurations разбирает список длительностей в формате time.Duration
+func parseDurations(values []string) ([]time.Duration, error) {
+	res := make([]time.Duration, 0, len(values))
+	for _, v := range values {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+
+	return res, nil
+}
+
+type postSaleValues struct {
+	// PayloadRetention срок хранения полезной нагрузки после реализации в формате time.Duration, пусто - бессрочно
+	PayloadRetention string `json:"payload_retention"`
+	// NotifyURL адрес внешней системы для уведомлений о реализации, пусто - не уведомлять
+	NotifyURL string `json:"notify_url"`
+	// NotifySecret секрет подписи уведомлений, пусто - без подписи
+	NotifySecret string `json:"notify_secret"`
+}
+
+// newPostSale собирает настройки обработки проданных единиц инвентаря
+func newPostSale(values postSaleValues) (productsusecase.PostSale, error) {
+	var postSale productsusecase.PostSale
+	if values.PayloadRetention != "" {
+		retention, err := time.ParseDuration(values.PayloadRetention)
+		if err != nil {
+			return postSale, err
+		}
+		postSale.PayloadRetention = retention
+	}
+
+	if values.NotifyURL != "" {
+		postSale.Hooks = append(postSale.Hooks, productsusecase.NewNotifyHook(webhook.NewImplementation(values.NotifyURL, values.NotifySecret)))
+	}
+
+	return postSale, nil
 }
 
 const configPath = "./deploy/values.json"
@@ -99,6 +225,49 @@ func runApp(ctx context.Context) {
 		log.Fatal(err)
 	}
 
+	webhookPostgresConn, err := webhookpostgres.NewImplementation(postgresConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	referralPostgresConn, err := referralpostgres.NewImplementation(postgresConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	payloadKeyring, err := envelope.NewKeyring(values.PayloadKeys, values.PayloadActiveKeyID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	postSale, err := newPostSale(values.PostSale)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reminderOffsets, err := parseDurations(values.RenewalReminders)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var gracePeriod time.Duration
+	if values.GracePeriod != "" {
+		gracePeriod, err = time.ParseDuration(values.GracePeriod)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	webhookEndpoints, err := newWebhookEndpoints(values.Webhooks)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	referralPolicy, err := newReferralPolicy(values.Referral)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// костыль, чтобы держать только один экземпляр ТГ клиента
 	g := &getBot{}
 
@@ -106,21 +275,45 @@ func runApp(ctx context.Context) {
 	messageClient := telegram_bot.NewImplementation(g)
 
 	// usecases
-	userUC := userusecase.NewImplementation(userPostgresConn)
-	productsUseCases := productsusecase.NewImplementation(productsPostgresConn)
-	subscriptionUC := subscriptionusecase.NewImplementation(messageClient, subscriptionPostgresConn, userUC)
+	var ownerTelegramID domainUser.TelegramID
+	if values.OwnerTelegramID != 0 {
+		ownerTelegramID = domainUser.NewTelegramID(values.OwnerTelegramID)
+	}
+	userUC := userusecase.NewImplementation(userPostgresConn, messageClient, ownerTelegramID)
+	if err = userUC.BootstrapOwner(ctx); err != nil {
+		log.Fatal(err)
+	}
+	productsUseCases := productsusecase.NewImplementation(productsPostgresConn,
+		messageClient,
+		payloadKeyring,
+		values.AdminChatIDs,
+		postSale)
+	webhookUC := webhookusecase.NewImplementation(webhookPostgresConn, webhookEndpoints)
+	subscriptionUC := subscriptionusecase.NewImplementation(messageClient,
+		webhookUC,
+		subscriptionPostgresConn,
+		userUC,
+		reminderOffsets,
+		gracePeriod)
+	referralUC := referralusecase.NewImplementation(referralPostgresConn,
+		subscriptionUC,
+		userUC,
+		referralPolicy)
 	orderUC := orderusecase.NewImplementation(productsUseCases,
 		userUC,
 		subscriptionUC,
-		orderPostgresConn,
-		messageClient)
+		referralUC,
+		orderPostgresConn)
 	paymentUC := paymentusecase.NewImplementation(paymentPostgresConn, orderUC, messageClient)
+	privacyUC := privacyusecase.NewImplementation(userUC, orderUC, paymentUC, subscriptionUC)
 
 	authMW := telegramHandlers.NewAuthMiddleware(userUC)
+	roleMW := telegramHandlers.NewRoleMiddleware()
 	paymentMW := telegramHandlers.NewPaymentMiddleware(paymentUC)
 	tgBot, err := telegramBot.New(values.TelegramToken,
 		telegramBot.WithCheckInitTimeout(20*time.Second),
 		telegramBot.WithMiddlewares(authMW.Middleware),
+		telegramBot.WithMiddlewares(roleMW.Middleware),
 		telegramBot.WithMiddlewares(telegramHandlers.AddLoggerToContextMiddleware(sugarLogger)),
 		telegramBot.WithMiddlewares(paymentMW.Middleware),
 	)
@@ -145,8 +338,22 @@ func runApp(ctx context.Context) {
 	if err = paymentUC.RunBackgroundProcess(ctx, wg); err != nil {
 		log.Fatal(err)
 	}
+	if err = webhookUC.RunBackgroundProcess(ctx, wg); err != nil {
+		log.Fatal(err)
+	}
+	if err = referralUC.RunBackgroundProcess(ctx, wg); err != nil {
+		log.Fatal(err)
+	}
 
-	_ = telegramHandlers.NewImplementation(productsUseCases, userUC, orderUC, paymentUC, tgBot)
+	_ = telegramHandlers.NewImplementation(productsUseCases,
+		userUC,
+		orderUC,
+		subscriptionUC,
+		webhookUC,
+		referralUC,
+		privacyUC,
+		paymentUC,
+		tgBot)
 
 	tgBot.Start(ctx)
 
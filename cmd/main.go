@@ -2,9 +2,15 @@ package main
 
 import (
 	"context"
+	"os"
 )
 
 func main() {
 	ctx := context.Background()
+	if len(os.Args) > 1 && os.Args[1] == rotatePayloadKeysCommand {
+		runRotatePayloadKeys(ctx)
+		return
+	}
+
 	runApp(ctx)
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	productspostgres "github.com/kdv2001/onlySubscription/internal/repositories/products/postgres"
+	productsusecase "github.com/kdv2001/onlySubscription/internal/useCase/products"
+	"github.com/kdv2001/onlySubscription/pkg/envelope"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// rotatePayloadKeysCommand команда перешифрования полезной нагрузки активным ключом
+const rotatePayloadKeysCommand = "rotate-payload-keys"
+
+// runRotatePayloadKeys перешифровывает ключи данных инвентаря активным мастер-ключом.
+// Старый ключ должен оставаться в payload_keys до завершения команды
+func runRotatePayloadKeys(ctx context.Context) {
+	values, err := initFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	zapLog, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = logger.ToContext(ctx, zapLog.Sugar())
+
+	postgresConn, err := pgxpool.New(ctx, values.PostgresDSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer postgresConn.Close()
+
+	productsPostgresConn, err := productspostgres.NewImplementation(postgresConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	payloadKeyring, err := envelope.NewKeyring(values.PayloadKeys, values.PayloadActiveKeyID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	productsUseCases := productsusecase.NewImplementation(productsPostgresConn,
+		nil,
+		payloadKeyring,
+		nil,
+		productsusecase.PostSale{})
+	processed, err := productsUseCases.RotatePayloadKeys(ctx)
+	if err != nil {
+		log.Fatalf("rotated %d items before error: %v", processed, err)
+	}
+
+	logger.Infof(ctx, "rotated %d items to key %s", processed, payloadKeyring.ActiveKeyID())
+}
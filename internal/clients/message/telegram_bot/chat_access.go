@@ -0,0 +1,60 @@
+package telegram_bot
+
+import (
+	"context"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// CreateInviteLink создает одноразовую ссылку-приглашение в чат, действующую до expireAt
+func (i *Implementation) CreateInviteLink(ctx context.Context, chatID int64, expireAt time.Time) (string, error) {
+	link, err := i.b.CreateChatInviteLink(ctx, &telegramBot.CreateChatInviteLinkParams{
+		ChatID:      chatID,
+		ExpireDate:  int(expireAt.Unix()),
+		MemberLimit: 1,
+	})
+	if err != nil {
+		return "", custom_errors.NewInternalError(err)
+	}
+
+	return link.InviteLink, nil
+}
+
+// RevokeInviteLink отзывает ссылку-приглашение
+func (i *Implementation) RevokeInviteLink(ctx context.Context, chatID int64, link string) error {
+	_, err := i.b.RevokeChatInviteLink(ctx, &telegramBot.RevokeChatInviteLinkParams{
+		ChatID:     chatID,
+		InviteLink: link,
+	})
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// RemoveChatMember исключает участника из чата.
+// Бан сразу снимается, чтобы пользователь мог вернуться после новой покупки
+func (i *Implementation) RemoveChatMember(ctx context.Context, chatID int64, userID int64) error {
+	_, err := i.b.BanChatMember(ctx, &telegramBot.BanChatMemberParams{
+		ChatID: chatID,
+		UserID: userID,
+	})
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	_, err = i.b.UnbanChatMember(ctx, &telegramBot.UnbanChatMemberParams{
+		ChatID:       chatID,
+		UserID:       userID,
+		OnlyIfBanned: true,
+	})
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package telegram_bot
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	telegramBot "github.com/go-telegram/bot"
@@ -10,12 +12,17 @@ import (
 	"github.com/kdv2001/onlySubscription/internal/domain/communication"
 	domainPayment "github.com/kdv2001/onlySubscription/internal/domain/payment"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	"github.com/kdv2001/onlySubscription/pkg/callback"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 type messageClient interface {
 	SendMessage(ctx context.Context, params *telegramBot.SendMessageParams) (*models.Message, error)
 	GetStarTransactions(ctx context.Context, params *telegramBot.GetStarTransactionsParams) (*models.StarTransactions, error)
+	CreateChatInviteLink(ctx context.Context, params *telegramBot.CreateChatInviteLinkParams) (*models.ChatInviteLink, error)
+	RevokeChatInviteLink(ctx context.Context, params *telegramBot.RevokeChatInviteLinkParams) (*models.ChatInviteLink, error)
+	BanChatMember(ctx context.Context, params *telegramBot.BanChatMemberParams) (bool, error)
+	UnbanChatMember(ctx context.Context, params *telegramBot.UnbanChatMemberParams) (bool, error)
 }
 
 type Implementation struct {
@@ -29,11 +36,44 @@ func NewImplementation(getBot messageClient) *Implementation {
 }
 
 func (i *Implementation) SendMessage(ctx context.Context, message communication.Message) error {
-	_, err := i.b.SendMessage(ctx, &telegramBot.SendMessageParams{
+	params := &telegramBot.SendMessageParams{
 		ChatID: message.ChatID,
 		Text:   message.Title + "\n" + message.Description,
-	})
+	}
+
+	if len(message.Buttons) > 0 {
+		keyboard := make([][]models.InlineKeyboardButton, 0, len(message.Buttons))
+		for _, b := range message.Buttons {
+			builder := callback.New(b.Action.String())
+			if b.Payload != "" {
+				builder.ID(b.Payload)
+			}
+
+			// действие приходит извне и может не поместиться в лимит кнопки
+			data, err := builder.Build()
+			if err != nil {
+				return custom_errors.NewBadRequestError(err)
+			}
+
+			keyboard = append(keyboard, []models.InlineKeyboardButton{
+				{
+					Text:         b.Text,
+					CallbackData: data,
+				},
+			})
+		}
+
+		params.ReplyMarkup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		}
+	}
+
+	_, err := i.b.SendMessage(ctx, params)
 	if err != nil {
+		// 403 - пользователь заблокировал бота или удалил аккаунт, повтор отправки не поможет
+		if errors.Is(err, telegramBot.ErrorForbidden) {
+			return custom_errors.NewForbiddenError(fmt.Errorf("%w: %w", communication.ErrUnreachable, err))
+		}
 		return custom_errors.NewInternalError(err)
 	}
 
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// defaultTimeout таймаут запроса к внешней системе
+const defaultTimeout = 10 * time.Second
+
+const (
+	// idHeader заголовок с идентификатором события
+	idHeader = "X-Webhook-ID"
+	// timestampHeader заголовок с временем отправки в unix секундах, входит в подпись
+	timestampHeader = "X-Webhook-Timestamp"
+	// signatureHeader заголовок с подписью запроса
+	signatureHeader = "X-Webhook-Signature"
+)
+
+type Implementation struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewImplementation создает клиент отправки событий во внешнюю систему по url.
+// Если задан secret, запросы подписываются HMAC-SHA256
+func NewImplementation(url string, secret string) *Implementation {
+	return &Implementation{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+type event struct {
+	ID         string            `json:"id,omitempty"`
+	Type       string            `json:"type"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data"`
+}
+
+// SendEvent отправляет событие POST запросом в формате JSON
+func (i *Implementation) SendEvent(ctx context.Context, e communication.Event) error {
+	body, err := json.Marshal(event{
+		ID:         e.ID,
+		Type:       e.Type,
+		OccurredAt: e.OccurredAt.UTC(),
+		Data:       e.Data,
+	})
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, bytes.NewReader(body))
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.ID != "" {
+		req.Header.Set(idHeader, e.ID)
+	}
+	if len(i.secret) != 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(timestampHeader, timestamp)
+		req.Header.Set(signatureHeader, "sha256="+i.sign(timestamp, body))
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return custom_errors.NewInternalError(err).AddDetails("error send event")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return custom_errors.NewInternalError(fmt.Errorf("unexpected status code %d", resp.StatusCode))
+	}
+
+	return nil
+}
+
+// sign подписывает строку "<timestamp>.<body>" секретом получателя
+func (i *Implementation) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
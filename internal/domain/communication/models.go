@@ -1,5 +1,13 @@
 package communication
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnreachable ошибка доставки сообщения пользователю, заблокировавшему бота
+var ErrUnreachable = errors.New("chat is unreachable")
+
 // Message модель сообщения
 type Message struct {
 	// ChatID ID чата
@@ -8,4 +16,41 @@ type Message struct {
 	Title string
 	// Description описание
 	Description string
+	// Buttons кнопки действий под сообщением
+	Buttons []Button
+}
+
+// Action действие кнопки сообщения
+type Action string
+
+const (
+	// RenewAction продление подписки, Payload - ID тарифа
+	RenewAction Action = "renew"
+)
+
+// String строковое представление
+func (a Action) String() string {
+	return string(a)
+}
+
+// Button кнопка действия под сообщением
+type Button struct {
+	// Text текст кнопки
+	Text string
+	// Action действие
+	Action Action
+	// Payload параметр действия
+	Payload string
+}
+
+// Event событие для внешней системы
+type Event struct {
+	// ID идентификатор события, по которому получатель отбрасывает повторы
+	ID string
+	// Type тип события
+	Type string
+	// OccurredAt время возникновения события
+	OccurredAt time.Time
+	// Data данные события
+	Data map[string]string
 }
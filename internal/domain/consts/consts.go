@@ -14,4 +14,7 @@ const (
 	// DefaultHandlingDur время обработки платежа в минутах после
 	// которого неоюходимо запросить статус платежа
 	DefaultHandlingDur = 1 * time.Minute
+
+	// DateTimeLayout формат даты и времени (UTC) в сообщениях и формах бота
+	DateTimeLayout = "2006-01-02 15:04"
 )
@@ -0,0 +1,335 @@
+package locale
+
+// en тексты на английском языке
+var en = map[Key]string{
+	BackButton:    "Back",
+	MenuButton:    "Menu",
+	PrevButton:    "Prev",
+	NextButton:    "Next",
+	PayButton:     "Pay",
+	RenewButton:   "Renew",
+	CancelButton:  "Cancel",
+	DefaultError:  "Something went wrong.\nPlease try again and report it to the administrator.\n\nError code: %s",
+	Forbidden:     "You are not allowed to do this.",
+	ButtonExpired: "This button is out of date. Open the menu and try again.",
+
+	NoPeriod:   "no period",
+	PeriodText: "%d %s",
+	DayUnit:    "day|days",
+	WeekUnit:   "week|weeks",
+	MonthUnit:  "month|months",
+	YearUnit:   "year|years",
+
+	Registered:           "Hi! You have successfully registered.",
+	WelcomeBack:          "Welcome back!",
+	NotRegistered:        "You are not registered in the bot. Run /start and then try again",
+	AccountBlocked:       "Your account is blocked %s.\nReason: %s",
+	BanPermanent:         "permanently",
+	BanUntil:             "until %s (UTC)",
+	MenuText:             "Hi there! Welcome to my store!",
+	MenuProducts:         "Products",
+	MenuProfile:          "Profile",
+	MenuHelp:             "Help",
+	MenuAdmin:            "Administration",
+	ProfileText:          "Your userID: %d",
+	ProfileOrders:        "My orders",
+	ProfileSubscriptions: "My subscriptions",
+	ProfileLanguage:      "Language",
+	ProfileReferral:      "Invite friends",
+	HelpText:             "Detailed store description",
+	LanguageText:         "Interface language: %s\n\nChoose a language or follow your Telegram settings:",
+	LanguageAuto:         "Same as Telegram",
+
+	ProductsText:     "Choose a product to see its details.\n\nPage %d of %d",
+	ProductText:      "Product: %s\ndescription: %s\navailable: %s\n\nChoose a plan:",
+	StockUnlimited:   "unlimited",
+	StockPieces:      "%d pcs.",
+	TariffFree:       "%s (%s) - free",
+	TariffSale:       "%s (%s) - %s %s (instead of %s)",
+	TariffPrice:      "%s (%s) - %s %s",
+	NoTariffs:        "no plans",
+	FreeClaimed:      "A free product can be claimed only once",
+	OutOfStock:       "The product is out of stock",
+	OrderCreated:     "Order created\nproduct: %s\nplan: %s\nprice: %s %s",
+	OrderFree:        "\n\nNo payment required, the product will arrive in a separate message",
+	OrderText:        "Order: %s\ndescription: %s\n\nprice: %s %s\nproduct: %s\nplan: %s",
+	OrdersText:       "Your orders.",
+	OrderTitle:       "Order #%s",
+	OrderPaid:        "The order is paid, the product will arrive in a separate message",
+	OrderExpired:     "Cancelled because it expired",
+	OrderDelivered:   "Payload: %s\nThe subscription is valid until %s (UTC)",
+	OrderRenewal:     "\n\nSubscription renewal: %s",
+	RenewalHistory:   "Renewal history",
+	RenewalItem:      "%s: %s\norder: %s",
+	RenewalsMore:     "... and %d more",
+	RenewalsText:     "The subscription is valid until %s (UTC)\nFirst order: %s\n\nRenewals:\n%s",
+	ShowKeyButton:    "Show key",
+	KeyText:          "Order #%s\nPayload: %s",
+	PayloadPurged:    "The key is no longer stored, please contact the administrator",
+	SubscriptionText: "Subscription: %s\nPlan: %s\nStatus: %s\nValid until: %s (UTC)\nRemaining: %s",
+	SubscriptionList: "My subscriptions.",
+	NoSubscriptions:  "You have no subscriptions yet.",
+	AccessChatLink:   "\nChannel link: %s",
+	UntilAccessEnd:   "%s until access is closed",
+
+	StatePaused:      "paused",
+	StateRevoked:     "revoked",
+	StateGrace:       "expired, access is kept until %s (UTC)",
+	StateActive:      "active",
+	StateExpired:     "expired",
+	RemainingExpired: "expired",
+	RemainingDays:    "%dd %dh",
+	RemainingHours:   "%dh %dm",
+
+	ActiveUntil:        "The subscription is valid until %s (UTC)",
+	ReminderTitle:      "Your subscription expires soon",
+	GraceReminderTitle: "Access will be closed soon",
+	GraceReminderText: "The subscription expired on %s (UTC), access is kept until %s (UTC).\n" +
+		"Renew the subscription to continue without a break",
+	GraceTitle: "Your subscription has expired",
+	GraceText: "%s\nAccess is kept until %s (UTC).\n" +
+		"Renew now and the new period will continue without a break",
+	ExpiredTitle:    "Your subscription has expired",
+	RenewProduct:    "Renew the product",
+	RevokedTitle:    "Subscription revoked",
+	RevokedStatus:   "Access under the subscription has ended",
+	PausedTitle:     "Subscription paused",
+	PausedStatus:    "The remaining time is kept until the subscription is resumed",
+	ResumedTitle:    "Subscription resumed",
+	ExtendedTitle:   "Subscription extended",
+	ChangeText:      "%s\n%s\nReason: %s",
+	ChatAccessTitle: "Channel access",
+	ChatAccessText:  "One-time invite link: %s\nThe link is valid until %s (UTC)",
+
+	ReferralText: "Your link for inviting friends:\n%s\n\n%s\n\n" +
+		"Friends invited: %d\nOrders paid by friends: %d",
+	ReferralRewardDays:     "For every order paid by a friend you get %d %s added to your subscription.",
+	ReferralRewardDiscount: "For every order paid by a friend you get a %d%% discount on your next order.",
+	ReferralNoReward:       "Share the link with friends who might like the store.",
+	ReferralBonusDays:      "\nBonus days credited: %d",
+	ReferralPendingDays:    "\nBonus days waiting for a subscription: %d",
+	ReferralDiscounts:      "\nDiscounts available: %d",
+	ReferralRewardTitle:    "Referral reward",
+	ReferralDaysPending: "A friend you invited has paid an order.\n" +
+		"%d %s will be added to your subscription as soon as you get one",
+	ReferralDiscountEarned: "A friend you invited has paid an order.\n" +
+		"A %d%% discount will be applied to your next order",
+	ReferralBonusReason: "bonus for inviting a friend",
+	ReferralInvited:     "A friend invited you, welcome!",
+
+	MyDataCaption: "Your data: profile, orders, invoices and subscriptions",
+	DeleteMeText: "Your Telegram ID, username, first and last name will be deleted and unpaid orders cancelled. " +
+		"Paid orders, invoices and subscriptions will be kept without a link to your Telegram account, " +
+		"active subscriptions stay valid until they end.\n\n" +
+		"Deletion can not be undone. You can export your data first with /mydata.\n\n" +
+		"Delete your data?",
+	DeleteMeButton: "Delete my data",
+	DeleteMeDone:   "Your data has been deleted. To use the bot again, send /start.",
+	DeleteMeStaff:  "Staff data can not be deleted, the owner has to remove your role first.",
+
+	DialogStep:         "%s\nStep %d of %d",
+	DialogInvalidValue: "Invalid value: %s",
+	DialogEmptyValue:   "the value is empty",
+	DialogSkipHint:     "Send %s to skip",
+	DialogCancelled:    "%s\n\nFilling in cancelled.",
+	DialogExpired:      "The form has already been completed or cancelled.",
+
+	DialogInvalidFormat: "invalid format",
+
+	ProductFormTitle:            "New product",
+	ItemFormTitle:               "New item",
+	TariffFormTitle:             "New tariff",
+	PublicationFormTitle:        "Publication window",
+	SaleFormTitle:               "Sale",
+	StockFormTitle:              "Stock mode",
+	AccessChatFormTitle:         "Product channel",
+	TranslationFormTitle:        "Product translation",
+	DeactivateMoveFormTitle:     "Moving unsold items",
+	RoleFormTitle:               "Role assignment",
+	BanFormTitle:                "Ban a user",
+	UnbanFormTitle:              "Unban a user",
+	FindSubscriptionsFormTitle:  "User subscriptions search",
+	ChangeSubscriptionFormTitle: "Subscription change",
+	ExportUserDataFormTitle:     "User data export",
+	DeleteUserDataFormTitle:     "User data deletion",
+
+	NameField:          "Name",
+	DescriptionField:   "Description",
+	ImageField:         "Image",
+	TariffField:        "Tariff",
+	PriceField:         "Price",
+	CurrencyField:      "Currency",
+	PeriodField:        "Subscription period",
+	ProductTypeField:   "Product type",
+	PayloadField:       "Payload",
+	PublishFromField:   "Publication start",
+	PublishUntilField:  "Publication end",
+	SalePriceField:     "Sale price",
+	SaleStartField:     "Sale start",
+	SaleEndField:       "Sale end",
+	StockModeField:     "Stock mode",
+	SalesLimitField:    "Sales limit",
+	ChatIDField:        "Chat ID",
+	LanguageField:      "Language",
+	TargetProductField: "Target product ID",
+	TelegramIDField:    "Telegram ID",
+	RoleField:          "Role",
+	ReasonField:        "Reason",
+	BanUntilField:      "Ban end",
+	ActionField:        "Action",
+	DaysField:          "Days",
+
+	ImageHint:       "<image link>",
+	TariffHint:      "<name of the first tariff>",
+	PriceHint:       "<e.g. 199 or 9.99, 0 - free>",
+	SalePriceHint:   "<e.g. 149 or 4.99>",
+	CurrencyHint:    "<RUB or XTR>",
+	PeriodHint:      "<e.g. 30d, 2w, 1m, 1y or 3 months>",
+	ProductTypeHint: "<subscription>",
+	PayloadHint:     "<key, link or text, may span several lines>",
+	TimeHint:        "<YYYY-MM-DD HH:MM in UTC, e.g. 2025-01-31 18:00>",
+	StockModeHint: "unique - every sale uses a separate item\n" +
+		"unlimited - a shared payload without a sales limit\n" +
+		"capped - a shared payload with a limited number of sales\n\n" +
+		"The shared payload is set via \"Add item\"",
+	SalesLimitHint: "<number of sales for capped>",
+	ChatIDHint: "<ID of a private channel or group, e.g. -1001234567890>\n\n" +
+		"Product subscribers get a one-time invite link and are removed from the chat when the subscription ends. " +
+		"The bot must be a chat administrator allowed to invite and ban members. " +
+		"Without a value the product channel is disabled",
+	LanguageHint: "<ru or en>",
+	TranslationNameHint: "<name in the selected language>\n\n" +
+		"Customers see the product name and description in their interface language, " +
+		"the original ones are shown without a translation. Without a value the translation is deleted",
+	TranslationDescHint: "<description in the selected language, without a value the original one is shown>",
+	TargetProductHint:   "<ID of the product that receives the unsold items>",
+	TelegramIDHint:      "<numeric Telegram user ID>",
+	RoleHint: "owner - all actions and role assignment\n" +
+		"manager - products, subscriptions and webhooks\n" +
+		"support - customer subscriptions\n" +
+		"none - remove the role",
+	BanUntilHint: "<YYYY-MM-DD HH:MM in UTC, without a value - permanently>",
+	ActionHint:   "<revoke, pause, resume or extend>",
+	DaysHint:     "<number of days for extend>",
+
+	InvalidPrice:            "a non-negative number is expected, e.g. 199 or 9.99",
+	InvalidCurrency:         "RUB and XTR currencies are supported",
+	InvalidPeriod:           "a period is expected, e.g. 30d, 2w, 1m, 1y or 3 months",
+	InvalidProductType:      "the subscription type is supported",
+	InvalidTime:             "a time in the YYYY-MM-DD HH:MM format is expected, e.g. 2025-01-31 18:00",
+	InvalidStockMode:        "unique, unlimited and capped modes are supported",
+	InvalidCount:            "a positive integer is expected",
+	InvalidChatID:           "a numeric chat ID is expected, e.g. -1001234567890",
+	InvalidLanguage:         "ru and en languages are supported",
+	InvalidTelegramID:       "a numeric Telegram ID is expected",
+	InvalidRole:             "owner, manager, support and none roles are supported",
+	InvalidAction:           "revoke, pause, resume and extend actions are supported",
+	InvalidPublicationRange: "The publication end must be later than its start",
+	DaysRequired:            "Specify the number of days to extend",
+
+	ToProductButton:          "To the product",
+	ToProductsButton:         "To products",
+	ToSubscriptionButton:     "To the subscription",
+	ToStaffButton:            "To staff",
+	MoveUnsoldButton:         "Move to another product",
+	AssignRoleButton:         "Assign a role",
+	BanButton:                "Ban",
+	UnbanButton:              "Unban",
+	ChangeSubscriptionButton: "Change the subscription",
+	ExportUserDataButton:     "Export data",
+	DeleteUserDataButton:     "Delete data",
+
+	ProductCreated:       "The product has been created",
+	ItemCreated:          "The item has been created",
+	SharedPayloadUpdated: "The shared product payload has been updated",
+	TariffCreated:        "The tariff has been created",
+	PublicationUpdated:   "The publication window has been updated",
+	SaleScheduled:        "The sale is scheduled\nfrom %s to %s (UTC)",
+	StockUpdated:         "The stock mode has been updated",
+	AccessChatUpdated:    "The product channel has been updated",
+	TranslationSaved:     "The product translation has been saved",
+	TranslationDeleted:   "The product translation has been deleted",
+	DeactivateText:       "What should be done with the unsold items?",
+	ProductArchived:      "The product has been archived\nID: %s",
+	UnsoldMoved:          "\nThe unsold items have been moved to the product %s",
+	StaffText: "Staff:\n%s\n\n" +
+		"owner - all actions and role assignment, manager - products, subscriptions and webhooks, " +
+		"support - customer subscriptions.\nThe user must be registered in the bot.",
+	RoleAssigned: "The user %d has been assigned the %s role",
+	RoleRemoved:  "The role of the user %d has been removed",
+	UserNotFound: "The user is not registered in the bot",
+	BanInfoText: "A banned user can not use the bot, their unpaid orders are cancelled. " +
+		"A staff member with a role can not be banned.",
+	UserBanned:              "The user %d has been banned %s.",
+	UserOrdersCancelFailed:  "\nFailed to cancel all user orders, they will be cancelled when they expire.",
+	UserOrdersCancelled:     "\nUnpaid orders cancelled: %d",
+	UserUnbanned:            "The user %d has been unbanned.",
+	UserSubscriptions:       "Subscriptions of the user %d:",
+	UserNoSubscriptions:     "The user %d has no subscriptions.",
+	SubscriptionChanged:     "The subscription has been changed, the user has been notified.\nStatus: %s\nValid until: %s (UTC)",
+	SubscriptionChangedRace: "The subscription has been changed, open it again and retry",
+	UserDataInfoText: "The export contains the user's profile, orders, invoices and subscriptions in JSON.\n" +
+		"Deletion anonymizes the user's telegram data and cancels their unpaid orders, " +
+		"orders, invoices and subscriptions are kept. Data of a staff member with a role can not be deleted.",
+	UserDataCaption: "Data of the user %s",
+	UserDataDeleted: "Data of the user %s has been deleted.",
+	StaffDeletion:   "Remove the staff role first",
+
+	AdminText:          "Administration panel.",
+	AdminCreateProduct: "Create a product",
+	AdminProducts:      "Products",
+	AdminArchive:       "Archive",
+	AdminWebhooks:      "Webhooks",
+	AdminSubscriptions: "Subscriptions",
+	AdminBans:          "Bans",
+	AdminStaff:         "Staff",
+	AdminUserData:      "User data",
+	AdminProductsText:  "Choose a product to configure it.",
+	ProductEditText: "ID: %s\nImage: %s\nType: %s\nProduct: %s\nDescription: %s\nPublication: %s\nStock: %s\n" +
+		"Channel: %s\n\nTariffs:\n%s\n\nTranslations:\n%s",
+	DeleteTariffButton:      "Delete tariff: %s",
+	SaleButton:              "Sale: %s",
+	AddItemButton:           "Add item",
+	AddTariffButton:         "Add tariff",
+	PublicationButton:       "Publication",
+	StockButton:             "Stock",
+	AccessChatButton:        "Channel",
+	TranslationsButton:      "Translations",
+	DeleteProductButton:     "Delete product",
+	DeleteItemButton:        "Delete item",
+	ItemText:                "ID: %s\nPayload: %s\n",
+	ItemDeleted:             "Deleted\nID: %s\nPayload: %s\n",
+	TariffDeleted:           "The tariff has been deleted\nName: %s",
+	NoLimit:                 "no limit",
+	NoValue:                 "none",
+	PublicationText:         "from %s to %s (UTC)",
+	StockUniqueText:         "unique items, on sale: %d",
+	StockUnlimitedText:      "unlimited, shared payload: %s, sold: %d",
+	StockCappedText:         "capped, shared payload: %s, sold: %d of %d",
+	SharedPayloadSet:        "set",
+	SharedPayloadNotSet:     "not set",
+	KeepUnsoldButton:        "Keep",
+	WithdrawUnsoldButton:    "Withdraw from sale",
+	ArchiveText:             "Product archive.",
+	ArchiveEmpty:            "The archive is empty.",
+	ArchivedProductText:     "ID: %s\nProduct: %s\nDescription: %s\nStock: %s\nWithdrawn from sale: %d\n\nTariffs:\n%s",
+	RestoreButton:           "Restore",
+	ProductRestored:         "The product has been restored, the withdrawn items are back on sale",
+	ManagedSubscription:     "%s %s until %s",
+	ManagedSubscriptionText: "Subscription: %s\nUser: %s\nStatus: %s\nValid until: %s (UTC)",
+	ManagedPausedText:       "\nPaused: %s (UTC)",
+	ManagedChangesText:      "\n\nChanges:\n%s",
+	SubscriptionChangeText:  "%s: %s, %s → %s\nreason: %s",
+	WebhooksText:            "Webhook delivery log.\nPage %d of %d",
+	WebhooksEmpty:           "The webhook delivery log is empty.",
+	WebhookDeliveryText: "Delivery: %s\nEndpoint: %s\nEvent: %s (%s)\nStatus: %s\nAttempts: %d of %d\n" +
+		"Next attempt: %s\nDelivered: %s\nLast error: %s\n\nData:\n%s",
+	ReplayButton:     "Send again",
+	ToDeliveryButton: "To the delivery",
+	DeliveryReplayed: "The delivery has been queued for resending",
+
+	PublicationStartedTitle: "Product publication has started",
+	PublicationStartedText:  "Product: %s\nID: %s\nOn sale since %s",
+	SaleStartedTitle:        "A sale has started",
+	SaleStartedText:         "Tariff: %s\nPrice: %s %s instead of %s\nUntil %s",
+}
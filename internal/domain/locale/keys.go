@@ -0,0 +1,347 @@
+package locale
+
+// Key ключ текста в каталоге сообщений
+type Key string
+
+// общие элементы интерфейса
+const (
+	BackButton   Key = "button.back"
+	MenuButton   Key = "button.menu"
+	PrevButton   Key = "button.prev"
+	NextButton   Key = "button.next"
+	PayButton    Key = "button.pay"
+	RenewButton  Key = "button.renew"
+	CancelButton Key = "button.cancel"
+	DefaultError Key = "error.default"
+	Forbidden    Key = "error.forbidden"
+	// ButtonExpired срок действия нажатой кнопки истек или кнопка устарела
+	ButtonExpired Key = "error.button_expired"
+)
+
+// единицы периода подписки, формы перечисляются через "|"
+const (
+	NoPeriod   Key = "period.none"
+	PeriodText Key = "period.text"
+	DayUnit    Key = "period.day"
+	WeekUnit   Key = "period.week"
+	MonthUnit  Key = "period.month"
+	YearUnit   Key = "period.year"
+)
+
+// регистрация, меню и профиль
+const (
+	Registered           Key = "start.registered"
+	WelcomeBack          Key = "start.welcome_back"
+	NotRegistered        Key = "auth.not_registered"
+	AccountBlocked       Key = "auth.blocked"
+	BanPermanent         Key = "ban.permanent"
+	BanUntil             Key = "ban.until"
+	MenuText             Key = "menu.text"
+	MenuProducts         Key = "menu.products"
+	MenuProfile          Key = "menu.profile"
+	MenuHelp             Key = "menu.help"
+	MenuAdmin            Key = "menu.admin"
+	ProfileText          Key = "profile.text"
+	ProfileOrders        Key = "profile.orders"
+	ProfileSubscriptions Key = "profile.subscriptions"
+	ProfileLanguage      Key = "profile.language"
+	ProfileReferral      Key = "profile.referral"
+	HelpText             Key = "help.text"
+	LanguageText         Key = "language.text"
+	LanguageAuto         Key = "language.auto"
+)
+
+// каталог продуктов
+const (
+	ProductsText     Key = "products.text"
+	ProductText      Key = "product.text"
+	StockUnlimited   Key = "product.stock_unlimited"
+	StockPieces      Key = "product.stock_pieces"
+	TariffFree       Key = "tariff.free"
+	TariffSale       Key = "tariff.sale"
+	TariffPrice      Key = "tariff.price"
+	NoTariffs        Key = "tariff.none"
+	FreeClaimed      Key = "order.free_claimed"
+	OutOfStock       Key = "order.out_of_stock"
+	OrderCreated     Key = "order.created"
+	OrderFree        Key = "order.free"
+	OrderText        Key = "order.text"
+	OrdersText       Key = "order.list"
+	OrderTitle       Key = "order.title"
+	OrderPaid        Key = "order.paid"
+	OrderExpired     Key = "order.expired"
+	OrderDelivered   Key = "order.delivered"
+	OrderRenewal     Key = "order.renewal"
+	RenewalHistory   Key = "renewal.history_button"
+	RenewalItem      Key = "renewal.item"
+	RenewalsMore     Key = "renewal.more"
+	RenewalsText     Key = "renewal.text"
+	ShowKeyButton    Key = "subscription.key_button"
+	KeyText          Key = "subscription.key"
+	PayloadPurged    Key = "subscription.payload_purged"
+	SubscriptionText Key = "subscription.text"
+	SubscriptionList Key = "subscription.list"
+	NoSubscriptions  Key = "subscription.empty"
+	AccessChatLink   Key = "subscription.chat_link"
+	UntilAccessEnd   Key = "subscription.until_access_end"
+)
+
+// статус подписки и оставшееся время
+const (
+	StatePaused      Key = "state.paused"
+	StateRevoked     Key = "state.revoked"
+	StateGrace       Key = "state.grace"
+	StateActive      Key = "state.active"
+	StateExpired     Key = "state.expired"
+	RemainingExpired Key = "remaining.expired"
+	RemainingDays    Key = "remaining.days"
+	RemainingHours   Key = "remaining.hours"
+)
+
+// уведомления о подписке
+const (
+	ActiveUntil        Key = "notify.active_until"
+	ReminderTitle      Key = "notify.reminder_title"
+	GraceReminderTitle Key = "notify.grace_reminder_title"
+	GraceReminderText  Key = "notify.grace_reminder_text"
+	GraceTitle         Key = "notify.grace_title"
+	GraceText          Key = "notify.grace_text"
+	ExpiredTitle       Key = "notify.expired_title"
+	RenewProduct       Key = "notify.renew_product"
+	RevokedTitle       Key = "notify.revoked_title"
+	RevokedStatus      Key = "notify.revoked_status"
+	PausedTitle        Key = "notify.paused_title"
+	PausedStatus       Key = "notify.paused_status"
+	ResumedTitle       Key = "notify.resumed_title"
+	ExtendedTitle      Key = "notify.extended_title"
+	ChangeText         Key = "notify.change_text"
+	ChatAccessTitle    Key = "notify.chat_access_title"
+	ChatAccessText     Key = "notify.chat_access_text"
+)
+
+// реферальная программа
+const (
+	ReferralText           Key = "referral.text"
+	ReferralRewardDays     Key = "referral.reward_days"
+	ReferralRewardDiscount Key = "referral.reward_discount"
+	ReferralNoReward       Key = "referral.no_reward"
+	ReferralBonusDays      Key = "referral.bonus_days"
+	ReferralPendingDays    Key = "referral.pending_days"
+	ReferralDiscounts      Key = "referral.discounts"
+	ReferralRewardTitle    Key = "referral.reward_title"
+	ReferralDaysPending    Key = "referral.days_pending"
+	ReferralDiscountEarned Key = "referral.discount_earned"
+	ReferralBonusReason    Key = "referral.bonus_reason"
+	ReferralInvited        Key = "referral.invited"
+)
+
+// выгрузка и удаление данных пользователя
+const (
+	MyDataCaption  Key = "privacy.my_data_caption"
+	DeleteMeText   Key = "privacy.delete_me_text"
+	DeleteMeButton Key = "privacy.delete_me_button"
+	DeleteMeDone   Key = "privacy.delete_me_done"
+	DeleteMeStaff  Key = "privacy.delete_me_staff"
+)
+
+// пошаговое заполнение форм
+const (
+	DialogStep         Key = "dialog.step"
+	DialogInvalidValue Key = "dialog.invalid_value"
+	DialogEmptyValue   Key = "dialog.empty_value"
+	DialogSkipHint     Key = "dialog.skip_hint"
+	DialogCancelled    Key = "dialog.cancelled"
+	DialogExpired      Key = "dialog.expired"
+)
+
+// формы администрирования: заголовки форм, вопросы шагов, подсказки и ошибки проверки ответов
+const (
+	DialogInvalidFormat Key = "dialog.invalid_format"
+
+	ProductFormTitle            Key = "form.product"
+	ItemFormTitle               Key = "form.item"
+	TariffFormTitle             Key = "form.tariff"
+	PublicationFormTitle        Key = "form.publication"
+	SaleFormTitle               Key = "form.sale"
+	StockFormTitle              Key = "form.stock"
+	AccessChatFormTitle         Key = "form.access_chat"
+	TranslationFormTitle        Key = "form.translation"
+	DeactivateMoveFormTitle     Key = "form.deactivate_move"
+	RoleFormTitle               Key = "form.role"
+	BanFormTitle                Key = "form.ban"
+	UnbanFormTitle              Key = "form.unban"
+	FindSubscriptionsFormTitle  Key = "form.find_subscriptions"
+	ChangeSubscriptionFormTitle Key = "form.change_subscription"
+	ExportUserDataFormTitle     Key = "form.export_user_data"
+	DeleteUserDataFormTitle     Key = "form.delete_user_data"
+
+	NameField          Key = "field.name"
+	DescriptionField   Key = "field.description"
+	ImageField         Key = "field.image"
+	TariffField        Key = "field.tariff"
+	PriceField         Key = "field.price"
+	CurrencyField      Key = "field.currency"
+	PeriodField        Key = "field.period"
+	ProductTypeField   Key = "field.product_type"
+	PayloadField       Key = "field.payload"
+	PublishFromField   Key = "field.publish_from"
+	PublishUntilField  Key = "field.publish_until"
+	SalePriceField     Key = "field.sale_price"
+	SaleStartField     Key = "field.sale_start"
+	SaleEndField       Key = "field.sale_end"
+	StockModeField     Key = "field.stock_mode"
+	SalesLimitField    Key = "field.sales_limit"
+	ChatIDField        Key = "field.chat_id"
+	LanguageField      Key = "field.language"
+	TargetProductField Key = "field.target_product"
+	TelegramIDField    Key = "field.telegram_id"
+	RoleField          Key = "field.role"
+	ReasonField        Key = "field.reason"
+	BanUntilField      Key = "field.ban_until"
+	ActionField        Key = "field.action"
+	DaysField          Key = "field.days"
+
+	ImageHint               Key = "hint.image"
+	TariffHint              Key = "hint.tariff"
+	PriceHint               Key = "hint.price"
+	SalePriceHint           Key = "hint.sale_price"
+	CurrencyHint            Key = "hint.currency"
+	PeriodHint              Key = "hint.period"
+	ProductTypeHint         Key = "hint.product_type"
+	PayloadHint             Key = "hint.payload"
+	TimeHint                Key = "hint.time"
+	StockModeHint           Key = "hint.stock_mode"
+	SalesLimitHint          Key = "hint.sales_limit"
+	ChatIDHint              Key = "hint.chat_id"
+	LanguageHint            Key = "hint.language"
+	TranslationNameHint     Key = "hint.translation_name"
+	TranslationDescHint     Key = "hint.translation_description"
+	TargetProductHint       Key = "hint.target_product"
+	TelegramIDHint          Key = "hint.telegram_id"
+	RoleHint                Key = "hint.role"
+	BanUntilHint            Key = "hint.ban_until"
+	ActionHint              Key = "hint.action"
+	DaysHint                Key = "hint.days"
+	InvalidPrice            Key = "invalid.price"
+	InvalidCurrency         Key = "invalid.currency"
+	InvalidPeriod           Key = "invalid.period"
+	InvalidProductType      Key = "invalid.product_type"
+	InvalidTime             Key = "invalid.time"
+	InvalidStockMode        Key = "invalid.stock_mode"
+	InvalidCount            Key = "invalid.count"
+	InvalidChatID           Key = "invalid.chat_id"
+	InvalidLanguage         Key = "invalid.language"
+	InvalidTelegramID       Key = "invalid.telegram_id"
+	InvalidRole             Key = "invalid.role"
+	InvalidAction           Key = "invalid.action"
+	InvalidPublicationRange Key = "invalid.publication_range"
+	DaysRequired            Key = "invalid.days_required"
+)
+
+// результаты форм администрирования и кнопки их запуска
+const (
+	ToProductButton          Key = "button.to_product"
+	ToProductsButton         Key = "button.to_products"
+	ToSubscriptionButton     Key = "button.to_subscription"
+	ToStaffButton            Key = "button.to_staff"
+	MoveUnsoldButton         Key = "button.move_unsold"
+	AssignRoleButton         Key = "button.assign_role"
+	BanButton                Key = "button.ban"
+	UnbanButton              Key = "button.unban"
+	ChangeSubscriptionButton Key = "button.change_subscription"
+	ExportUserDataButton     Key = "button.export_user_data"
+	DeleteUserDataButton     Key = "button.delete_user_data"
+
+	ProductCreated          Key = "admin.product_created"
+	ItemCreated             Key = "admin.item_created"
+	SharedPayloadUpdated    Key = "admin.shared_payload_updated"
+	TariffCreated           Key = "admin.tariff_created"
+	PublicationUpdated      Key = "admin.publication_updated"
+	SaleScheduled           Key = "admin.sale_scheduled"
+	StockUpdated            Key = "admin.stock_updated"
+	AccessChatUpdated       Key = "admin.access_chat_updated"
+	TranslationSaved        Key = "admin.translation_saved"
+	TranslationDeleted      Key = "admin.translation_deleted"
+	DeactivateText          Key = "admin.deactivate_text"
+	ProductArchived         Key = "admin.product_archived"
+	UnsoldMoved             Key = "admin.unsold_moved"
+	StaffText               Key = "admin.staff_text"
+	RoleAssigned            Key = "admin.role_assigned"
+	RoleRemoved             Key = "admin.role_removed"
+	UserNotFound            Key = "admin.user_not_found"
+	BanInfoText             Key = "admin.ban_info"
+	UserBanned              Key = "admin.user_banned"
+	UserOrdersCancelFailed  Key = "admin.user_orders_cancel_failed"
+	UserOrdersCancelled     Key = "admin.user_orders_cancelled"
+	UserUnbanned            Key = "admin.user_unbanned"
+	UserSubscriptions       Key = "admin.user_subscriptions"
+	UserNoSubscriptions     Key = "admin.user_no_subscriptions"
+	SubscriptionChanged     Key = "admin.subscription_changed"
+	SubscriptionChangedRace Key = "admin.subscription_changed_race"
+	UserDataInfoText        Key = "admin.user_data_info"
+	UserDataCaption         Key = "admin.user_data_caption"
+	UserDataDeleted         Key = "admin.user_data_deleted"
+	StaffDeletion           Key = "admin.staff_deletion"
+)
+
+// панель администрирования
+const (
+	AdminText               Key = "admin.text"
+	AdminCreateProduct      Key = "admin.menu.create_product"
+	AdminProducts           Key = "admin.menu.products"
+	AdminArchive            Key = "admin.menu.archive"
+	AdminWebhooks           Key = "admin.menu.webhooks"
+	AdminSubscriptions      Key = "admin.menu.subscriptions"
+	AdminBans               Key = "admin.menu.bans"
+	AdminStaff              Key = "admin.menu.staff"
+	AdminUserData           Key = "admin.menu.user_data"
+	AdminProductsText       Key = "admin.products_text"
+	ProductEditText         Key = "admin.product_edit_text"
+	DeleteTariffButton      Key = "button.delete_tariff"
+	SaleButton              Key = "button.sale"
+	AddItemButton           Key = "button.add_item"
+	AddTariffButton         Key = "button.add_tariff"
+	PublicationButton       Key = "button.publication"
+	StockButton             Key = "button.stock"
+	AccessChatButton        Key = "button.access_chat"
+	TranslationsButton      Key = "button.translations"
+	DeleteProductButton     Key = "button.delete_product"
+	DeleteItemButton        Key = "button.delete_item"
+	ItemText                Key = "admin.item_text"
+	ItemDeleted             Key = "admin.item_deleted"
+	TariffDeleted           Key = "admin.tariff_deleted"
+	NoLimit                 Key = "admin.no_limit"
+	NoValue                 Key = "admin.no_value"
+	PublicationText         Key = "admin.publication_text"
+	StockUniqueText         Key = "admin.stock_unique"
+	StockUnlimitedText      Key = "admin.stock_unlimited"
+	StockCappedText         Key = "admin.stock_capped"
+	SharedPayloadSet        Key = "admin.shared_payload_set"
+	SharedPayloadNotSet     Key = "admin.shared_payload_not_set"
+	KeepUnsoldButton        Key = "button.keep_unsold"
+	WithdrawUnsoldButton    Key = "button.withdraw_unsold"
+	ArchiveText             Key = "admin.archive_text"
+	ArchiveEmpty            Key = "admin.archive_empty"
+	ArchivedProductText     Key = "admin.archived_product_text"
+	RestoreButton           Key = "button.restore"
+	ProductRestored         Key = "admin.product_restored"
+	ManagedSubscription     Key = "admin.managed_subscription"
+	ManagedSubscriptionText Key = "admin.managed_subscription_text"
+	ManagedPausedText       Key = "admin.managed_paused_text"
+	ManagedChangesText      Key = "admin.managed_changes_text"
+	SubscriptionChangeText  Key = "admin.subscription_change"
+	WebhooksText            Key = "admin.webhooks_text"
+	WebhooksEmpty           Key = "admin.webhooks_empty"
+	WebhookDeliveryText     Key = "admin.webhook_delivery_text"
+	ReplayButton            Key = "button.replay"
+	ToDeliveryButton        Key = "button.to_delivery"
+	DeliveryReplayed        Key = "admin.delivery_replayed"
+)
+
+// уведомления администраторов
+const (
+	PublicationStartedTitle Key = "announce.publication_started_title"
+	PublicationStartedText  Key = "announce.publication_started_text"
+	SaleStartedTitle        Key = "announce.sale_started_title"
+	SaleStartedText         Key = "announce.sale_started_text"
+)
@@ -0,0 +1,126 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang язык интерфейса бота
+type Lang string
+
+const (
+	// RU русский язык
+	RU Lang = "ru"
+	// EN английский язык
+	EN Lang = "en"
+
+	// Default язык по умолчанию, в нем заданы все тексты каталога
+	Default = RU
+)
+
+// Langs поддерживаемые языки
+var Langs = []Lang{RU, EN}
+
+// String строковое представление
+func (l Lang) String() string {
+	return string(l)
+}
+
+// Name название языка на нем самом
+func (l Lang) Name() string {
+	switch l {
+	case EN:
+		return "English"
+	}
+
+	return "Русский"
+}
+
+// Parse возвращает поддерживаемый язык по его коду
+func Parse(s string) (Lang, bool) {
+	for _, l := range Langs {
+		if string(l) == strings.ToLower(strings.TrimSpace(s)) {
+			return l, true
+		}
+	}
+
+	return "", false
+}
+
+// FromTelegram определяет язык по коду языка клиента Telegram (IETF, например "en-US").
+// Без кода используется язык по умолчанию, для неподдерживаемых языков - английский
+func FromTelegram(code string) Lang {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	switch base {
+	case "":
+		return Default
+	case "ru", "uk", "be", "kk":
+		return RU
+	}
+
+	return EN
+}
+
+// catalogs тексты сообщений по языкам
+var catalogs = map[Lang]map[Key]string{
+	RU: ru,
+	EN: en,
+}
+
+// T возвращает текст key на языке l, args подставляются через fmt.Sprintf.
+// Отсутствующий перевод берется из языка по умолчанию
+func (l Lang) T(key Key, args ...any) string {
+	text, ok := catalogs[l][key]
+	if !ok {
+		text, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return string(key)
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Plural выбирает форму слова key для числа n.
+// Формы в каталоге перечисляются через "|": для русского 1, 2-4 и 5 ("месяц|месяца|месяцев"),
+// для английского 1 и остальные ("month|months")
+func (l Lang) Plural(n int, key Key) string {
+	forms := strings.Split(l.T(key), "|")
+
+	var i int
+	switch l {
+	case EN:
+		if n != 1 {
+			i = 1
+		}
+	default:
+		i = ruPluralForm(n)
+	}
+
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+
+	return forms[i]
+}
+
+// ruPluralForm номер формы слова для числа n: 1 месяц, 2 месяца, 5 месяцев
+func ruPluralForm(n int) int {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return 2
+	}
+
+	switch n % 10 {
+	case 1:
+		return 0
+	case 2, 3, 4:
+		return 1
+	}
+
+	return 2
+}
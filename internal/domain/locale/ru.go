@@ -0,0 +1,336 @@
+package locale
+
+// ru тексты на русском языке
+var ru = map[Key]string{
+	BackButton:   "Назад",
+	MenuButton:   "Меню",
+	PrevButton:   "Пред.",
+	NextButton:   "След.",
+	PayButton:    "Оплатить",
+	RenewButton:  "Продлить",
+	CancelButton: "Отмена",
+	DefaultError: "Что-то пошло не так.\nПожалуйста, попробуйте снова и сообщите об этом администратору.\n\n" +
+		"Кода ошибки: %s",
+	Forbidden:     "Недостаточно прав для этого действия.",
+	ButtonExpired: "Кнопка устарела. Откройте меню и повторите действие.",
+
+	NoPeriod:   "без периода",
+	PeriodText: "%d %s",
+	DayUnit:    "день|дня|дней",
+	WeekUnit:   "неделя|недели|недель",
+	MonthUnit:  "месяц|месяца|месяцев",
+	YearUnit:   "год|года|лет",
+
+	Registered:           "Привет! Ты успешно зарегистрирован.",
+	WelcomeBack:          "С возвращением!",
+	NotRegistered:        "Вы не зарегистрированы в боте. Выполните команду /start и затем повторите действия",
+	AccountBlocked:       "Ваш аккаунт заблокирован %s.\nПричина: %s",
+	BanPermanent:         "бессрочно",
+	BanUntil:             "до %s (UTC)",
+	MenuText:             "Привет, друг! Добро пожаловать в мой магазин!",
+	MenuProducts:         "Продукты",
+	MenuProfile:          "Профиль",
+	MenuHelp:             "Помощь",
+	MenuAdmin:            "Администрирование",
+	ProfileText:          "Твой userID: %d",
+	ProfileOrders:        "Список заказов",
+	ProfileSubscriptions: "Мои подписки",
+	ProfileLanguage:      "Язык",
+	ProfileReferral:      "Пригласить друзей",
+	HelpText:             "Подробное описание магазина",
+	LanguageText:         "Язык интерфейса: %s\n\nВыберите язык или определяйте его по настройкам Telegram:",
+	LanguageAuto:         "Как в Telegram",
+
+	ProductsText:     "Выбирай товар и переходи к подробному описанию.\n\nСтраница %d из %d",
+	ProductText:      "Товар: %s\nописание: %s\nв наличии: %s\n\nВыберите тариф:",
+	StockUnlimited:   "без ограничений",
+	StockPieces:      "%d шт.",
+	TariffFree:       "%s (%s) - бесплатно",
+	TariffSale:       "%s (%s) - %s %s (вместо %s)",
+	TariffPrice:      "%s (%s) - %s %s",
+	NoTariffs:        "нет тарифов",
+	FreeClaimed:      "Бесплатный продукт можно получить только один раз",
+	OutOfStock:       "Товар закончился",
+	OrderCreated:     "Заказ создан\nпродукт: %s\nтариф: %s\nцена: %s %s",
+	OrderFree:        "\n\nОплата не требуется, товар придет отдельным сообщением",
+	OrderText:        "Заказ: %s\nописание: %s\n\nцена: %s %s\nпродукт: %s\nтариф: %s",
+	OrdersText:       "Страница заказов.",
+	OrderTitle:       "Заказ № %s",
+	OrderPaid:        "Заказ оплачен, ожидайте товар придет отдельным сообщением",
+	OrderExpired:     "Отменен по истечению времени жизни",
+	OrderDelivered:   "Полезная нагрузка: %s\nПодписка действует до %s (UTC)",
+	OrderRenewal:     "\n\nПродление подписки: %s",
+	RenewalHistory:   "История продлений",
+	RenewalItem:      "%s: %s\nзаказ: %s",
+	RenewalsMore:     "... и еще %d",
+	RenewalsText:     "Подписка действует до %s (UTC)\nПервый заказ: %s\n\nПродления:\n%s",
+	ShowKeyButton:    "Показать ключ",
+	KeyText:          "Заказ № %s\nПолезная нагрузка: %s",
+	PayloadPurged:    "Срок хранения ключа истек, обратитесь к администратору",
+	SubscriptionText: "Подписка: %s\nТариф: %s\nСтатус: %s\nДействует до: %s (UTC)\nОсталось: %s",
+	SubscriptionList: "Мои подписки.",
+	NoSubscriptions:  "У вас пока нет подписок.",
+	AccessChatLink:   "\nСсылка в канал: %s",
+	UntilAccessEnd:   "%s до закрытия доступа",
+
+	StatePaused:      "приостановлена",
+	StateRevoked:     "отозвана",
+	StateGrace:       "истекла, доступ сохранится до %s (UTC)",
+	StateActive:      "активна",
+	StateExpired:     "истекла",
+	RemainingExpired: "истекла",
+	RemainingDays:    "%d д. %d ч.",
+	RemainingHours:   "%d ч. %d мин.",
+
+	ActiveUntil:        "Подписка действует до %s (UTC)",
+	ReminderTitle:      "Подписка скоро истекает",
+	GraceReminderTitle: "Доступ скоро будет закрыт",
+	GraceReminderText: "Подписка истекла %s (UTC), доступ сохранится до %s (UTC).\n" +
+		"Продлите подписку, чтобы продолжить без перерыва",
+	GraceTitle: "Подписка истекла",
+	GraceText: "%s\nДоступ сохранится до %s (UTC).\n" +
+		"Продлите подписку сейчас, и новый период продолжится без перерыва",
+	ExpiredTitle:    "Ваша подписка истекла",
+	RenewProduct:    "Обновите продукт",
+	RevokedTitle:    "Подписка отозвана",
+	RevokedStatus:   "Доступ по подписке прекращен",
+	PausedTitle:     "Подписка приостановлена",
+	PausedStatus:    "Оставшееся время сохранится до возобновления подписки",
+	ResumedTitle:    "Подписка возобновлена",
+	ExtendedTitle:   "Подписка продлена",
+	ChangeText:      "%s\n%s\nПричина: %s",
+	ChatAccessTitle: "Доступ в канал",
+	ChatAccessText:  "Одноразовая ссылка для вступления: %s\nСсылка действует до %s (UTC)",
+
+	ReferralText: "Ваша ссылка для приглашения друзей:\n%s\n\n%s\n\n" +
+		"Приглашено друзей: %d\nОплаченных заказов друзей: %d",
+	ReferralRewardDays:     "За каждый оплаченный заказ друга вы получите %d %s к подписке.",
+	ReferralRewardDiscount: "За каждый оплаченный заказ друга вы получите скидку %d%% на следующий заказ.",
+	ReferralNoReward:       "Поделитесь ссылкой с друзьями, которым может понравиться магазин.",
+	ReferralBonusDays:      "\nНачислено бонусных дней: %d",
+	ReferralPendingDays:    "\nБонусных дней ждут подписки: %d",
+	ReferralDiscounts:      "\nДоступно скидок: %d",
+	ReferralRewardTitle:    "Награда за приглашение",
+	ReferralDaysPending: "Приглашенный вами друг оплатил заказ.\n" +
+		"%d %s будут добавлены к подписке, как только вы ее оформите",
+	ReferralDiscountEarned: "Приглашенный вами друг оплатил заказ.\n" +
+		"Скидка %d%% будет применена к вашему следующему заказу",
+	ReferralBonusReason: "бонус за приглашение друга",
+	ReferralInvited:     "Вас пригласил друг, добро пожаловать!",
+
+	MyDataCaption: "Ваши данные: профиль, заказы, счета и подписки",
+	DeleteMeText: "Ваши Telegram ID, username, имя и фамилия будут удалены, неоплаченные заказы отменены. " +
+		"Оплаченные заказы, счета и подписки сохранятся без привязки к вашему аккаунту Telegram, " +
+		"действующие подписки продолжат действовать до окончания.\n\n" +
+		"Удаление нельзя отменить. Выгрузить данные перед удалением можно командой /mydata.\n\n" +
+		"Удалить ваши данные?",
+	DeleteMeButton: "Удалить мои данные",
+	DeleteMeDone:   "Ваши данные удалены. Чтобы снова пользоваться ботом, отправьте /start.",
+	DeleteMeStaff:  "Данные сотрудника нельзя удалить, сначала владелец должен снять с вас роль.",
+
+	DialogStep:         "%s\nШаг %d из %d",
+	DialogInvalidValue: "Неверное значение: %s",
+	DialogEmptyValue:   "значение не заполнено",
+	DialogSkipHint:     "Отправьте %s, чтобы пропустить",
+	DialogCancelled:    "%s\n\nЗаполнение отменено.",
+	DialogExpired:      "Форма уже заполнена или отменена.",
+
+	DialogInvalidFormat: "неверный формат",
+
+	ProductFormTitle:            "Создание продукта",
+	ItemFormTitle:               "Добавление единицы товара",
+	TariffFormTitle:             "Создание тарифа",
+	PublicationFormTitle:        "Окно публикации",
+	SaleFormTitle:               "Распродажа",
+	StockFormTitle:              "Режим склада",
+	AccessChatFormTitle:         "Канал продукта",
+	TranslationFormTitle:        "Перевод продукта",
+	DeactivateMoveFormTitle:     "Перенос непроданных единиц товара",
+	RoleFormTitle:               "Назначение роли",
+	BanFormTitle:                "Блокировка пользователя",
+	UnbanFormTitle:              "Разблокировка пользователя",
+	FindSubscriptionsFormTitle:  "Поиск подписок пользователя",
+	ChangeSubscriptionFormTitle: "Изменение подписки",
+	ExportUserDataFormTitle:     "Выгрузка данных пользователя",
+	DeleteUserDataFormTitle:     "Удаление данных пользователя",
+
+	NameField:          "Название",
+	DescriptionField:   "Описание",
+	ImageField:         "Изображение",
+	TariffField:        "Тариф",
+	PriceField:         "Стоимость",
+	CurrencyField:      "Валюта",
+	PeriodField:        "Период подписки",
+	ProductTypeField:   "Тип продукта",
+	PayloadField:       "Полезная нагрузка",
+	PublishFromField:   "Начало публикации",
+	PublishUntilField:  "Окончание публикации",
+	SalePriceField:     "Цена распродажи",
+	SaleStartField:     "Начало распродажи",
+	SaleEndField:       "Окончание распродажи",
+	StockModeField:     "Режим склада",
+	SalesLimitField:    "Лимит продаж",
+	ChatIDField:        "ID чата",
+	LanguageField:      "Язык",
+	TargetProductField: "ID продукта для переноса",
+	TelegramIDField:    "Telegram ID",
+	RoleField:          "Роль",
+	ReasonField:        "Причина",
+	BanUntilField:      "Окончание блокировки",
+	ActionField:        "Действие",
+	DaysField:          "Дней",
+
+	ImageHint:       "<ссылка на изображение>",
+	TariffHint:      "<название первого тарифа>",
+	PriceHint:       "<например 199 или 9.99, 0 - бесплатно>",
+	SalePriceHint:   "<например 149 или 4.99>",
+	CurrencyHint:    "<RUB или XTR>",
+	PeriodHint:      "<например 30d, 2w, 1m, 1y или 3 месяца>",
+	ProductTypeHint: "<subscription>",
+	PayloadHint:     "<ключ, ссылка или текст, можно в несколько строк>",
+	TimeHint:        "<ГГГГ-ММ-ДД ЧЧ:ММ по UTC, например 2025-01-31 18:00>",
+	StockModeHint: "unique - каждая продажа расходует отдельную единицу товара\n" +
+		"unlimited - общая полезная нагрузка без ограничения продаж\n" +
+		"capped - общая полезная нагрузка с ограничением кол-ва продаж\n\n" +
+		"Общая полезная нагрузка задается через \"Добавить item\"",
+	SalesLimitHint: "<кол-во продаж для capped>",
+	ChatIDHint: "<ID приватного канала или группы, например -1001234567890>\n\n" +
+		"Подписчики продукта получают одноразовую ссылку в чат и исключаются из него после окончания подписки. " +
+		"Бот должен быть администратором чата с правами приглашать и блокировать участников. " +
+		"Без значения канал продукта отключается",
+	LanguageHint: "<ru или en>",
+	TranslationNameHint: "<название на выбранном языке>\n\n" +
+		"Покупатели видят название и описание продукта на языке интерфейса, без перевода показываются исходные. " +
+		"Без значения перевод удаляется",
+	TranslationDescHint: "<описание на выбранном языке, без значения показывается исходное>",
+	TargetProductHint:   "<ID продукта, в который переносятся непроданные единицы товара>",
+	TelegramIDHint:      "<числовой ID пользователя в Telegram>",
+	RoleHint: "owner - все действия и назначение ролей\n" +
+		"manager - продукты, подписки и вебхуки\n" +
+		"support - подписки покупателей\n" +
+		"none - снять роль",
+	BanUntilHint: "<ГГГГ-ММ-ДД ЧЧ:ММ по UTC, без значения - бессрочно>",
+	ActionHint:   "<revoke, pause, resume или extend>",
+	DaysHint:     "<кол-во дней для extend>",
+
+	InvalidPrice:            "ожидается неотрицательное число, например 199 или 9.99",
+	InvalidCurrency:         "поддерживаются валюты RUB и XTR",
+	InvalidPeriod:           "ожидается период, например 30d, 2w, 1m, 1y или 3 месяца",
+	InvalidProductType:      "поддерживается тип subscription",
+	InvalidTime:             "ожидается время в формате ГГГГ-ММ-ДД ЧЧ:ММ, например 2025-01-31 18:00",
+	InvalidStockMode:        "поддерживаются режимы unique, unlimited и capped",
+	InvalidCount:            "ожидается целое положительное число",
+	InvalidChatID:           "ожидается числовой ID чата, например -1001234567890",
+	InvalidLanguage:         "поддерживаются языки ru и en",
+	InvalidTelegramID:       "ожидается числовой Telegram ID",
+	InvalidRole:             "поддерживаются роли owner, manager, support и none",
+	InvalidAction:           "поддерживаются действия revoke, pause, resume и extend",
+	InvalidPublicationRange: "Окончание публикации должно быть позже начала",
+	DaysRequired:            "Для продления укажите кол-во дней",
+
+	ToProductButton:          "К продукту",
+	ToProductsButton:         "К продуктам",
+	ToSubscriptionButton:     "К подписке",
+	ToStaffButton:            "К сотрудникам",
+	MoveUnsoldButton:         "Перенести в другой продукт",
+	AssignRoleButton:         "Назначить роль",
+	BanButton:                "Заблокировать",
+	UnbanButton:              "Разблокировать",
+	ChangeSubscriptionButton: "Изменить подписку",
+	ExportUserDataButton:     "Выгрузить данные",
+	DeleteUserDataButton:     "Удалить данные",
+
+	ProductCreated:       "Продукт успешно создан",
+	ItemCreated:          "Единица товара успешно создана",
+	SharedPayloadUpdated: "Общая полезная нагрузка продукта обновлена",
+	TariffCreated:        "Тариф успешно создан",
+	PublicationUpdated:   "Окно публикации обновлено",
+	SaleScheduled:        "Распродажа запланирована\nс %s по %s (UTC)",
+	StockUpdated:         "Режим склада обновлен",
+	AccessChatUpdated:    "Канал продукта обновлен",
+	TranslationSaved:     "Перевод продукта сохранен",
+	TranslationDeleted:   "Перевод продукта удален",
+	DeactivateText:       "Что сделать с непроданными единицами товара?",
+	ProductArchived:      "Продукт перенесен в архив\nID: %s",
+	UnsoldMoved:          "\nНепроданные единицы товара перенесены в продукт %s",
+	StaffText: "Сотрудники:\n%s\n\n" +
+		"owner - все действия и назначение ролей, manager - продукты, подписки и вебхуки, " +
+		"support - подписки покупателей.\nПользователь должен быть зарегистрирован в боте.",
+	RoleAssigned: "Пользователю %d назначена роль %s",
+	RoleRemoved:  "У пользователя %d снята роль",
+	UserNotFound: "Пользователь не зарегистрирован в боте",
+	BanInfoText: "Заблокированный пользователь не может пользоваться ботом, " +
+		"его неоплаченные заказы отменяются. Сотрудника с ролью заблокировать нельзя.",
+	UserBanned:              "Пользователь %d заблокирован %s.",
+	UserOrdersCancelFailed:  "\nНе удалось отменить все заказы пользователя, они будут отменены по истечении времени жизни.",
+	UserOrdersCancelled:     "\nОтменено неоплаченных заказов: %d",
+	UserUnbanned:            "Пользователь %d разблокирован.",
+	UserSubscriptions:       "Подписки пользователя %d:",
+	UserNoSubscriptions:     "У пользователя %d нет подписок.",
+	SubscriptionChanged:     "Подписка изменена, пользователь уведомлен.\nСтатус: %s\nДействует до: %s (UTC)",
+	SubscriptionChangedRace: "Подписка была изменена, откройте ее заново и повторите",
+	UserDataInfoText: "Выгрузка содержит профиль, заказы, счета и подписки пользователя в JSON.\n" +
+		"Удаление обезличивает данные telegram пользователя и отменяет его неоплаченные заказы, " +
+		"заказы, счета и подписки сохраняются. Данные сотрудника с ролью удалить нельзя.",
+	UserDataCaption: "Данные пользователя %s",
+	UserDataDeleted: "Данные пользователя %s удалены.",
+	StaffDeletion:   "Сначала снимите роль сотрудника",
+
+	AdminText:          "Панель администрирования.",
+	AdminCreateProduct: "Создать продукт",
+	AdminProducts:      "Просмотр продуктов",
+	AdminArchive:       "Архив",
+	AdminWebhooks:      "Вебхуки",
+	AdminSubscriptions: "Подписки",
+	AdminBans:          "Блокировки",
+	AdminStaff:         "Сотрудники",
+	AdminUserData:      "Данные пользователей",
+	AdminProductsText:  "Выбирай товар и переходи к его настройке.",
+	ProductEditText: "ID: %s\nИзображение: %s\nТип: %s\nТовар: %s\nОписание: %s\nПубликация: %s\nСклад: %s\n" +
+		"Канал: %s\n\nТарифы:\n%s\n\nПереводы:\n%s",
+	DeleteTariffButton:      "Удалить тариф: %s",
+	SaleButton:              "Распродажа: %s",
+	AddItemButton:           "Добавить item",
+	AddTariffButton:         "Добавить тариф",
+	PublicationButton:       "Публикация",
+	StockButton:             "Склад",
+	AccessChatButton:        "Канал",
+	TranslationsButton:      "Переводы",
+	DeleteProductButton:     "Удалить продукт",
+	DeleteItemButton:        "Удалить item",
+	ItemText:                "ID: %s\nПолезная нагрузка: %s\n",
+	ItemDeleted:             "Удален\nID: %s\nПолезная нагрузка: %s\n",
+	TariffDeleted:           "Тариф удален\nНазвание: %s",
+	NoLimit:                 "без ограничения",
+	NoValue:                 "нет",
+	PublicationText:         "с %s по %s (UTC)",
+	StockUniqueText:         "уникальные единицы, в продаже: %d",
+	StockUnlimitedText:      "без ограничения, общая нагрузка: %s, продано: %d",
+	StockCappedText:         "ограничено, общая нагрузка: %s, продано: %d из %d",
+	SharedPayloadSet:        "задана",
+	SharedPayloadNotSet:     "не задана",
+	KeepUnsoldButton:        "Оставить",
+	WithdrawUnsoldButton:    "Снять с продажи",
+	ArchiveText:             "Архив продуктов.",
+	ArchiveEmpty:            "Архив пуст.",
+	ArchivedProductText:     "ID: %s\nТовар: %s\nОписание: %s\nСклад: %s\nСнято с продажи: %d\n\nТарифы:\n%s",
+	RestoreButton:           "Восстановить",
+	ProductRestored:         "Продукт восстановлен, снятые с продажи единицы товара возвращены в продажу",
+	ManagedSubscription:     "%s %s до %s",
+	ManagedSubscriptionText: "Подписка: %s\nПользователь: %s\nСтатус: %s\nДействует до: %s (UTC)",
+	ManagedPausedText:       "\nПриостановлена: %s (UTC)",
+	ManagedChangesText:      "\n\nИзменения:\n%s",
+	SubscriptionChangeText:  "%s: %s, %s → %s\nпричина: %s",
+	WebhooksText:            "Журнал доставки вебхуков.\nСтраница %d из %d",
+	WebhooksEmpty:           "Журнал доставки вебхуков пуст.",
+	WebhookDeliveryText: "Доставка: %s\nПолучатель: %s\nСобытие: %s (%s)\nСтатус: %s\nПопыток: %d из %d\n" +
+		"Следующая попытка: %s\nДоставлено: %s\nПоследняя ошибка: %s\n\nДанные:\n%s",
+	ReplayButton:     "Отправить повторно",
+	ToDeliveryButton: "К доставке",
+	DeliveryReplayed: "Доставка поставлена в очередь на повторную отправку",
+
+	PublicationStartedTitle: "Началась публикация продукта",
+	PublicationStartedText:  "Продукт: %s\nID: %s\nВ продаже с %s",
+	SaleStartedTitle:        "Началась распродажа",
+	SaleStartedText:         "Тариф: %s\nЦена: %s %s вместо %s\nДо %s",
+}
@@ -65,6 +65,9 @@ func (s Status) CanChangeStatus(toStatus Status) bool {
 		switch toStatus {
 		case ExpectPayments:
 			return true
+		// бесплатный заказ не требует оплаты
+		case Processing:
+			return true
 		case Cancelled:
 			return true
 		}
@@ -147,12 +150,20 @@ func NewChangeOrderStatus(from, to Status) (ChangeOrderStatus, error) {
 	}, nil
 }
 
+// ErrFreeProductClaimed ошибка повторного получения бесплатного продукта
+var ErrFreeProductClaimed = errors.New("free product already claimed")
+
+// IsFree возвращает признак бесплатного заказа
+func (o Order) IsFree() bool {
+	return o.TotalPrice.Value.IsZero()
+}
+
 // CreateOrder структура для создания заказа
 type CreateOrder struct {
 	// UserID ID пользователя
 	UserID user.ID
-	// ProductID ID продукта с витрины
-	ProductID domainProducts.ID
+	// TariffID ID выбранного тарифа продукта
+	TariffID domainProducts.TariffID
 }
 
 // Order заказ
@@ -186,8 +197,12 @@ type Product struct {
 	ItemID domainProducts.ItemID
 	// ProductID ID продукта с ветрины
 	ProductID domainProducts.ID
+	// TariffID ID тарифа продукта
+	TariffID domainProducts.TariffID
 	// Title название продукта
 	Title string
+	// TariffName название тарифа
+	TariffName string
 	// Description описание
 	Description string
 }
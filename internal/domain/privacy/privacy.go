@@ -0,0 +1,36 @@
+package privacy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/payment"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	"github.com/kdv2001/onlySubscription/internal/domain/user"
+)
+
+// ErrStaffDeletion ошибка удаления данных сотрудника с ролью
+var ErrStaffDeletion = errors.New("can not delete staff user data")
+
+// Export выгрузка данных пользователя по его запросу
+type Export struct {
+	// User профиль пользователя
+	User user.User
+	// Orders заказы
+	Orders []order.Order
+	// Invoices счета по заказам
+	Invoices []payment.Invoice
+	// Subscriptions подписки
+	Subscriptions []subscription.Subscription
+	// CreatedAt время выгрузки
+	CreatedAt time.Time
+}
+
+// Deletion результат удаления данных пользователя
+type Deletion struct {
+	// User пользователь до удаления данных
+	User user.User
+	// CancelledOrders кол-во отмененных неоплаченных заказов
+	CancelledOrders int
+}
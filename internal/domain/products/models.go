@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/price"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
@@ -68,6 +69,13 @@ const (
 	PerformedStatus ItemStatus = "performed"
 	// RealizedStatus статус реализован
 	RealizedStatus ItemStatus = "realized"
+	// SharedStatus общая полезная нагрузка продукта, копируется в единицу инвентаря при каждой продаже
+	SharedStatus ItemStatus = "shared"
+	// WithdrawnStatus снят с продажи при деактивации продукта
+	WithdrawnStatus ItemStatus = "withdrawn"
+	// ReleasedStatus копия общей полезной нагрузки, бронь которой снята.
+	// Полезная нагрузка удалена, запись хранится для истории заказов
+	ReleasedStatus ItemStatus = "released"
 )
 
 // CanChangeStatus возвращает признак возможности перехода в статус
@@ -81,7 +89,16 @@ func (s ItemStatus) CanChangeStatus(toStatus ItemStatus) bool {
 		switch toStatus {
 		case PreReservedStatus:
 			return true
+		case WithdrawnStatus:
+			return true
+		}
+	case WithdrawnStatus:
+		switch toStatus {
+		case SaleStatus:
+			return true
 		}
+
+		return false
 	case PreReservedStatus:
 		switch toStatus {
 		case ReservedStatus:
@@ -130,6 +147,12 @@ func ItemStatusFromString(status string) ItemStatus {
 		return PerformedStatus
 	case string(RealizedStatus):
 		return RealizedStatus
+	case string(SharedStatus):
+		return SharedStatus
+	case string(WithdrawnStatus):
+		return WithdrawnStatus
+	case string(ReleasedStatus):
+		return ReleasedStatus
 	default:
 		return UnknownStatus
 	}
@@ -138,6 +161,14 @@ func ItemStatusFromString(status string) ItemStatus {
 // Products список продуктов
 type Products []Product
 
+// ProductsPage страница списка продуктов
+type ProductsPage struct {
+	// Products продукты страницы
+	Products Products
+	// Total общее кол-во продуктов, подходящих под фильтры
+	Total uint64
+}
+
 // Product продукт
 type Product struct {
 	// ID продукта
@@ -154,10 +185,277 @@ type Product struct {
 	CreatedAt time.Time
 	// UpdatedAt последнее время обновления
 	UpdatedAt time.Time
-	// Price цена продукта
+	// Tariffs тарифы продукта
+	Tariffs Tariffs
+	// PublishFrom время начала публикации, нулевое значение - без ограничения
+	PublishFrom time.Time
+	// PublishUntil время окончания публикации, нулевое значение - без ограничения
+	PublishUntil time.Time
+	// StockMode режим склада
+	StockMode StockMode
+	// StockLimit максимальное кол-во продаж в режиме CappedStock
+	StockLimit int64
+	// Stock состояние склада
+	Stock Stock
+	// Archived признак деактивированного продукта
+	Archived bool
+	// AccessChatID приватный канал или группа, доступ в которые выдает подписка, 0 - без доступа
+	AccessChatID int64
+	// Translations переводы названия и описания
+	Translations Translations
+}
+
+// Localized возвращает продукт с названием и описанием на языке lang.
+// Без перевода остаются исходные название и описание
+func (p Product) Localized(lang locale.Lang) Product {
+	t, ok := p.Translations[lang]
+	if !ok {
+		return p
+	}
+
+	p.Name = t.Name
+	if t.Description != "" {
+		p.Description = t.Description
+	}
+
+	return p
+}
+
+// Translation перевод названия и описания продукта
+type Translation struct {
+	// ProductID продукт
+	ProductID ID
+	// Lang язык перевода
+	Lang locale.Lang
+	// Name название
+	Name string
+	// Description описание, пустое - исходное описание продукта
+	Description string
+}
+
+// Translations переводы продукта по языкам
+type Translations map[locale.Lang]Translation
+
+// ErrEmptyTranslationName ошибка отсутствия названия в переводе
+var ErrEmptyTranslationName = errors.New("translation name is required")
+
+// InStock возвращает признак наличия товара для продажи
+func (p Product) InStock(s Stock) bool {
+	num, unlimited := p.Available(s)
+	return unlimited || num > 0
+}
+
+// Available возвращает кол-во доступных для продажи единиц
+// и признак неограниченного кол-ва
+func (p Product) Available(s Stock) (int64, bool) {
+	switch p.StockMode {
+	case UnlimitedStock:
+		if s.HasSharedPayload {
+			return 0, true
+		}
+
+		return s.OnSale, false
+	case CappedStock:
+		left := max(p.StockLimit-s.Issued, 0)
+		if !s.HasSharedPayload {
+			left = min(left, s.OnSale)
+		}
+
+		return left, false
+	default:
+		return s.OnSale, false
+	}
+}
+
+// StockMode режим склада продукта
+type StockMode string
+
+const (
+	// UniqueStock каждая продажа расходует уникальную единицу инвентаря
+	UniqueStock StockMode = "unique"
+	// UnlimitedStock неограниченные продажи с общей полезной нагрузкой
+	UnlimitedStock StockMode = "unlimited"
+	// CappedStock ограниченное кол-во продаж с общей полезной нагрузкой
+	CappedStock StockMode = "capped"
+)
+
+// String строковое представление
+func (m StockMode) String() string {
+	return string(m)
+}
+
+// IsShared возвращает признак продажи общей полезной нагрузки
+func (m StockMode) IsShared() bool {
+	return m == UnlimitedStock || m == CappedStock
+}
+
+// StockModeFromString возвращает режим склада из строки
+func StockModeFromString(s string) StockMode {
+	switch s {
+	case string(UnlimitedStock):
+		return UnlimitedStock
+	case string(CappedStock):
+		return CappedStock
+	default:
+		return UniqueStock
+	}
+}
+
+// ErrUnknownStockMode ошибка неизвестного режима склада
+var ErrUnknownStockMode = errors.New("unknown stock mode")
+
+// ParseStockMode разбирает режим склада, введенный администратором
+func ParseStockMode(s string) (StockMode, error) {
+	switch s {
+	case string(UniqueStock), string(UnlimitedStock), string(CappedStock):
+		return StockMode(s), nil
+	}
+
+	return UniqueStock, custom_errors.NewBadRequestError(ErrUnknownStockMode)
+}
+
+// ErrOutOfStock ошибка отсутствия товара для продажи
+var ErrOutOfStock = errors.New("product is out of stock")
+
+// Stock состояние склада продукта
+type Stock struct {
+	// OnSale кол-во единиц инвентаря в продаже
+	OnSale int64
+	// Issued кол-во единиц инвентаря, выданных под заказы
+	Issued int64
+	// HasSharedPayload признак наличия общей полезной нагрузки
+	HasSharedPayload bool
+	// Withdrawn кол-во единиц инвентаря, снятых с продажи
+	Withdrawn int64
+}
+
+// IsPublished возвращает признак публикации продукта на витрине в момент now
+func (p Product) IsPublished(now time.Time) bool {
+	if !p.PublishFrom.IsZero() && now.Before(p.PublishFrom) {
+		return false
+	}
+
+	if !p.PublishUntil.IsZero() && !now.Before(p.PublishUntil) {
+		return false
+	}
+
+	return true
+}
+
+// Publication окно публикации продукта
+type Publication struct {
+	// From время начала публикации, нулевое значение - без ограничения
+	From time.Time
+	// Until время окончания публикации, нулевое значение - без ограничения
+	Until time.Time
+}
+
+// ErrInvalidWindow ошибка некорректного временного окна
+var ErrInvalidWindow = errors.New("window end is before start")
+
+// NewPublication создает окно публикации
+func NewPublication(from, until time.Time) (Publication, error) {
+	if !from.IsZero() && !until.IsZero() && !until.After(from) {
+		return Publication{}, custom_errors.NewBadRequestError(ErrInvalidWindow)
+	}
+
+	return Publication{
+		From:  from,
+		Until: until,
+	}, nil
+}
+
+// TariffID айди тарифа
+type TariffID struct {
+	ID string
+}
+
+// String строковое представление
+func (id TariffID) String() string {
+	return id.ID
+}
+
+// IsEmpty возвращает признак пустого ID
+func (id TariffID) IsEmpty() bool {
+	return id.ID == ""
+}
+
+// NewTariffID создает объект ID
+func NewTariffID[T string | int | int64](i T) TariffID {
+	return TariffID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Tariffs список тарифов
+type Tariffs []Tariff
+
+// Tariff тариф продукта
+type Tariff struct {
+	// ID тарифа
+	ID TariffID
+	// ProductID ID продукта
+	ProductID ID
+	// Name название тарифа
+	Name string
+	// Price цена тарифа
 	Price price.Price
-	// SubscriptionPeriod период подписки
-	SubscriptionPeriod time.Duration
+	// SubscriptionPeriod календарный период подписки
+	SubscriptionPeriod Period
+	// Sale действующая распродажа тарифа, nil - распродажи нет
+	Sale *Sale
+	// Deleted признак удаленного тарифа, по нему нельзя оформить заказ
+	Deleted bool
+	// CreatedAt время создания
+	CreatedAt time.Time
+	// UpdatedAt время последнего обновления
+	UpdatedAt time.Time
+}
+
+// ActualPrice возвращает цену тарифа с учетом действующей распродажи
+func (t Tariff) ActualPrice() price.Price {
+	if t.Sale != nil {
+		return t.Sale.Price
+	}
+
+	return t.Price
+}
+
+// IsFree возвращает признак бесплатного тарифа, выдаваемого без оплаты.
+// Определяется базовой ценой: распродажа не делает платный тариф бесплатным
+func (t Tariff) IsFree() bool {
+	return t.Price.Value.IsZero()
+}
+
+// SaleID айди распродажи
+type SaleID struct {
+	ID string
+}
+
+// String строковое представление
+func (id SaleID) String() string {
+	return id.ID
+}
+
+// NewSaleID создает объект ID
+func NewSaleID[T string | int | int64](i T) SaleID {
+	return SaleID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Sale запланированное изменение цены тарифа
+type Sale struct {
+	// ID распродажи
+	ID SaleID
+	// TariffID ID тарифа
+	TariffID TariffID
+	// Price цена во время распродажи
+	Price price.Price
+	// StartsAt время начала распродажи
+	StartsAt time.Time
+	// EndsAt время окончания распродажи
+	EndsAt time.Time
 }
 
 // ItemID айди
@@ -192,8 +490,72 @@ type Item struct {
 	CreatedAt time.Time
 	// UpdatedAt время последнего обновления
 	UpdatedAt time.Time
-	// Payload полезная нагрузка
+	// Payload полезная нагрузка в открытом виде.
+	// Заполняется только при создании единицы инвентаря и для записей,
+	// созданных до включения шифрования
 	Payload string
+	// EncryptedPayload зашифрованная полезная нагрузка
+	EncryptedPayload EncryptedPayload
+	// DeliveredAt время подтвержденной выдачи покупателю
+	DeliveredAt time.Time
+	// RealizedAt время реализации
+	RealizedAt time.Time
+	// PayloadPurgedAt время удаления полезной нагрузки после реализации
+	PayloadPurgedAt time.Time
+	// PostSaleAttempts кол-во неудачных попыток выполнить обработчики реализации
+	PostSaleAttempts int
+}
+
+const (
+	// postSaleRetryBaseDelay задержка перед второй попыткой обработки реализации
+	postSaleRetryBaseDelay = time.Minute
+	// postSaleRetryMaxDelay максимальная задержка между попытками обработки реализации
+	postSaleRetryMaxDelay = time.Hour
+)
+
+// PostSaleRetryDelay возвращает задержку перед следующей попыткой обработки реализации
+// после attempts неудачных попыток, задержка удваивается с каждой попыткой
+func PostSaleRetryDelay(attempts int) time.Duration {
+	delay := postSaleRetryBaseDelay
+	for j := 1; j < attempts; j++ {
+		delay *= 2
+		if delay >= postSaleRetryMaxDelay {
+			return postSaleRetryMaxDelay
+		}
+	}
+
+	return delay
+}
+
+// ErrPayloadPurged ошибка обращения к удаленной полезной нагрузке
+var ErrPayloadPurged = errors.New("payload purged")
+
+// maskedPayload маска полезной нагрузки для отображения
+const maskedPayload = "••••••••"
+
+// MaskedPayload возвращает полезную нагрузку в виде, пригодном для отображения
+// администратору: сами данные не раскрываются
+func (i Item) MaskedPayload() string {
+	if i.EncryptedPayload.IsEmpty() {
+		return maskedPayload + " (не зашифровано)"
+	}
+
+	return maskedPayload + " (ключ " + i.EncryptedPayload.KeyID + ")"
+}
+
+// EncryptedPayload полезная нагрузка, зашифрованная конвертным шифрованием
+type EncryptedPayload struct {
+	// KeyID идентификатор мастер-ключа
+	KeyID string
+	// EncryptedKey зашифрованный ключ данных
+	EncryptedKey []byte
+	// Data зашифрованные данные
+	Data []byte
+}
+
+// IsEmpty возвращает признак отсутствия зашифрованных данных
+func (p EncryptedPayload) IsEmpty() bool {
+	return p.KeyID == ""
 }
 
 // Image изображение
@@ -241,6 +603,49 @@ type Filters struct {
 	ItemsExist bool
 	// ProductID фильтр по ID продукта
 	ProductID ID
+	// PublishedAt фильтр продуктов, опубликованных в указанный момент
+	PublishedAt time.Time
+	// Archived фильтр деактивированных продуктов
+	Archived bool
+}
+
+// UnsoldAction действие с непроданными единицами инвентаря при деактивации продукта
+type UnsoldAction string
+
+const (
+	// KeepUnsold оставить в продаже, станут доступны после восстановления продукта
+	KeepUnsold UnsoldAction = "keep"
+	// WithdrawUnsold снять с продажи
+	WithdrawUnsold UnsoldAction = "withdraw"
+	// MoveUnsold перенести в другой продукт
+	MoveUnsold UnsoldAction = "move"
+)
+
+// String строковое представление
+func (a UnsoldAction) String() string {
+	return string(a)
+}
+
+// UnsoldActionFromString возвращает действие из строки
+func UnsoldActionFromString(s string) UnsoldAction {
+	switch s {
+	case string(WithdrawUnsold):
+		return WithdrawUnsold
+	case string(MoveUnsold):
+		return MoveUnsold
+	default:
+		return KeepUnsold
+	}
+}
+
+// Deactivation параметры деактивации продукта
+type Deactivation struct {
+	// ProductID ID деактивируемого продукта
+	ProductID ID
+	// Unsold действие с непроданными единицами инвентаря
+	Unsold UnsoldAction
+	// TargetProductID продукт, в который переносятся единицы инвентаря при MoveUnsold
+	TargetProductID ID
 }
 
 // RequestList список параметров запроса
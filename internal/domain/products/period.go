@@ -0,0 +1,163 @@
+package products
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// PeriodUnit календарная единица периода подписки
+type PeriodUnit string
+
+const (
+	// UnknownPeriodUnit неизвестная единица
+	UnknownPeriodUnit PeriodUnit = ""
+	// DayUnit день
+	DayUnit PeriodUnit = "day"
+	// WeekUnit неделя
+	WeekUnit PeriodUnit = "week"
+	// MonthUnit календарный месяц
+	MonthUnit PeriodUnit = "month"
+	// YearUnit календарный год
+	YearUnit PeriodUnit = "year"
+)
+
+// String строковое представление
+func (u PeriodUnit) String() string {
+	return string(u)
+}
+
+// PeriodUnitFromString единица периода из строки
+func PeriodUnitFromString(s string) PeriodUnit {
+	switch s {
+	case string(DayUnit):
+		return DayUnit
+	case string(WeekUnit):
+		return WeekUnit
+	case string(MonthUnit):
+		return MonthUnit
+	case string(YearUnit):
+		return YearUnit
+	}
+
+	return UnknownPeriodUnit
+}
+
+// Period календарный период подписки, например 1 месяц или 2 недели
+type Period struct {
+	// Count кол-во единиц
+	Count int
+	// Unit единица периода
+	Unit PeriodUnit
+}
+
+// NewPeriod создает период из count единиц unit
+func NewPeriod(count int, unit PeriodUnit) Period {
+	return Period{
+		Count: count,
+		Unit:  unit,
+	}
+}
+
+// IsZero признак пустого периода
+func (p Period) IsZero() bool {
+	return p.Count == 0 || p.Unit == UnknownPeriodUnit
+}
+
+// AddTo прибавляет период к t по правилам time.AddDate:
+// 31 января + 1 месяц = 3 марта (2 марта в високосный год), поскольку 31 февраля нормализуется
+func (p Period) AddTo(t time.Time) time.Time {
+	switch p.Unit {
+	case DayUnit:
+		return t.AddDate(0, 0, p.Count)
+	case WeekUnit:
+		return t.AddDate(0, 0, 7*p.Count)
+	case MonthUnit:
+		return t.AddDate(0, p.Count, 0)
+	case YearUnit:
+		return t.AddDate(p.Count, 0, 0)
+	}
+
+	return t
+}
+
+// String представление периода на языке по умолчанию, например "3 месяца"
+func (p Period) String() string {
+	return p.Format(locale.Default)
+}
+
+// Format представление периода на языке lang
+func (p Period) Format(lang locale.Lang) string {
+	if p.IsZero() {
+		return lang.T(locale.NoPeriod)
+	}
+
+	var unit locale.Key
+	switch p.Unit {
+	case DayUnit:
+		unit = locale.DayUnit
+	case WeekUnit:
+		unit = locale.WeekUnit
+	case MonthUnit:
+		unit = locale.MonthUnit
+	case YearUnit:
+		unit = locale.YearUnit
+	}
+
+	return lang.T(locale.PeriodText, p.Count, lang.Plural(p.Count, unit))
+}
+
+// ErrInvalidPeriod ошибка разбора периода подписки
+var ErrInvalidPeriod = errors.New("invalid subscription period")
+
+// MaxPeriodYears максимальная длительность периода подписки в годах
+const MaxPeriodYears = 10
+
+// maxPeriodCount максимальное кол-во единиц периода, не превышающее MaxPeriodYears
+var maxPeriodCount = map[PeriodUnit]int{
+	DayUnit:   MaxPeriodYears * 365,
+	WeekUnit:  MaxPeriodYears * 52,
+	MonthUnit: MaxPeriodYears * 12,
+	YearUnit:  MaxPeriodYears,
+}
+
+// periodUnits единицы периода по их обозначениям, введенным администратором
+var periodUnits = map[string]PeriodUnit{
+	"d": DayUnit, "д": DayUnit, "day": DayUnit, "days": DayUnit,
+	"дн": DayUnit, "день": DayUnit, "дня": DayUnit, "дней": DayUnit,
+	"w": WeekUnit, "н": WeekUnit, "week": WeekUnit, "weeks": WeekUnit,
+	"нед": WeekUnit, "неделя": WeekUnit, "недели": WeekUnit, "недель": WeekUnit,
+	"m": MonthUnit, "м": MonthUnit, "month": MonthUnit, "months": MonthUnit,
+	"мес": MonthUnit, "месяц": MonthUnit, "месяца": MonthUnit, "месяцев": MonthUnit,
+	"y": YearUnit, "г": YearUnit, "year": YearUnit, "years": YearUnit,
+	"год": YearUnit, "года": YearUnit, "лет": YearUnit,
+}
+
+// ParsePeriod разбирает период, введенный администратором: "1m", "2w", "30d", "1y", "3 месяца", "1 год".
+// Обозначение "m" всегда означает месяц, период не длиннее MaxPeriodYears лет
+func ParsePeriod(s string) (Period, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	digits := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if digits <= 0 {
+		return Period{}, custom_errors.NewBadRequestError(ErrInvalidPeriod).AddDetails(s)
+	}
+
+	count, err := strconv.Atoi(s[:digits])
+	if err != nil || count <= 0 {
+		return Period{}, custom_errors.NewBadRequestError(ErrInvalidPeriod).AddDetails(s)
+	}
+
+	unit, ok := periodUnits[strings.TrimSuffix(strings.TrimSpace(s[digits:]), ".")]
+	if !ok || count > maxPeriodCount[unit] {
+		return Period{}, custom_errors.NewBadRequestError(ErrInvalidPeriod).AddDetails(s)
+	}
+
+	return NewPeriod(count, unit), nil
+}
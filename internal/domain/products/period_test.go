@@ -0,0 +1,141 @@
+package products
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+)
+
+func TestParsePeriod(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    Period
+		wantErr bool
+	}{
+		{in: "1m", want: NewPeriod(1, MonthUnit)},
+		{in: "2w", want: NewPeriod(2, WeekUnit)},
+		{in: "30d", want: NewPeriod(30, DayUnit)},
+		{in: "1y", want: NewPeriod(1, YearUnit)},
+		{in: " 3 Месяца ", want: NewPeriod(3, MonthUnit)},
+		{in: "1 год", want: NewPeriod(1, YearUnit)},
+		{in: "2 нед.", want: NewPeriod(2, WeekUnit)},
+		{in: "120m", want: NewPeriod(120, MonthUnit)},
+		{in: "121m", wantErr: true},
+		{in: "11y", wantErr: true},
+		{in: "99999999999999999999d", wantErr: true},
+		{in: "0d", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "m", wantErr: true},
+		{in: "5", wantErr: true},
+		{in: "5 fortnights", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePeriod(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPeriod) {
+					t.Errorf("ParsePeriod() error = %v, want %v", err, ErrInvalidPeriod)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParsePeriod() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParsePeriod() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriod_AddTo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		period Period
+		from   time.Time
+		want   time.Time
+	}{
+		{
+			name:   "days",
+			period: NewPeriod(30, DayUnit),
+			from:   time.Date(2023, time.January, 15, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2023, time.February, 14, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "weeks",
+			period: NewPeriod(2, WeekUnit),
+			from:   time.Date(2023, time.January, 15, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2023, time.January, 29, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "month from jan 31",
+			period: NewPeriod(1, MonthUnit),
+			from:   time.Date(2023, time.January, 31, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2023, time.March, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "month from jan 31 in leap year",
+			period: NewPeriod(1, MonthUnit),
+			from:   time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2024, time.March, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "year from feb 29",
+			period: NewPeriod(1, YearUnit),
+			from:   time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2025, time.March, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "zero period",
+			period: Period{},
+			from:   time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC),
+			want:   time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.period.AddTo(tt.from); !got.Equal(tt.want) {
+				t.Errorf("AddTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriod_Format(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		period Period
+		lang   locale.Lang
+		want   string
+	}{
+		{period: NewPeriod(1, MonthUnit), lang: locale.RU, want: "1 месяц"},
+		{period: NewPeriod(3, MonthUnit), lang: locale.RU, want: "3 месяца"},
+		{period: NewPeriod(5, DayUnit), lang: locale.RU, want: "5 дней"},
+		{period: NewPeriod(11, YearUnit), lang: locale.RU, want: "11 лет"},
+		{period: NewPeriod(21, WeekUnit), lang: locale.RU, want: "21 неделя"},
+		{period: NewPeriod(1, WeekUnit), lang: locale.EN, want: "1 week"},
+		{period: NewPeriod(2, YearUnit), lang: locale.EN, want: "2 years"},
+		{period: Period{}, lang: locale.RU, want: "без периода"},
+		{period: Period{}, lang: locale.EN, want: "no period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.period.Format(tt.lang); got != tt.want {
+				t.Errorf("Format() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
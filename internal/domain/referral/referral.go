@@ -0,0 +1,302 @@
+package referral
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/price"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	"github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// StartPrefix префикс параметра команды /start в реферальной ссылке
+const StartPrefix = "ref_"
+
+const (
+	// codeLength длина реферального кода
+	codeLength = 8
+	// codeAlphabet символы реферального кода, допустимые в параметре /start
+	codeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	// MaxDiscountPercent максимальный процент скидки за приглашение
+	MaxDiscountPercent = 99
+)
+
+var (
+	// ErrInvalidCode ошибка недопустимого реферального кода
+	ErrInvalidCode = errors.New("invalid referral code")
+	// ErrSelfReferral ошибка приглашения самого себя
+	ErrSelfReferral = errors.New("self referral")
+	// ErrRewardExists ошибка повторного начисления награды за заказ
+	ErrRewardExists = errors.New("referral reward already exists")
+	// ErrInvalidPolicy ошибка недопустимых настроек награды
+	ErrInvalidPolicy = errors.New("invalid referral reward policy")
+)
+
+// Code реферальный код пользователя
+type Code string
+
+// String строковое представление
+func (c Code) String() string {
+	return string(c)
+}
+
+// StartParam параметр команды /start для реферальной ссылки
+func (c Code) StartParam() string {
+	return StartPrefix + string(c)
+}
+
+// NewCode генерирует случайный реферальный код
+func NewCode() (Code, error) {
+	b := make([]byte, codeLength)
+	alphabetLen := big.NewInt(int64(len(codeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", custom_errors.NewInternalError(err)
+		}
+		b[i] = codeAlphabet[n.Int64()]
+	}
+
+	return Code(b), nil
+}
+
+// ParseStartParam извлекает реферальный код из параметра команды /start.
+// ok=false - параметр не относится к реферальной ссылке
+func ParseStartParam(param string) (Code, bool) {
+	code, ok := strings.CutPrefix(strings.TrimSpace(param), StartPrefix)
+	if !ok || len(code) != codeLength {
+		return "", false
+	}
+
+	for _, r := range code {
+		if !strings.ContainsRune(codeAlphabet, r) {
+			return "", false
+		}
+	}
+
+	return Code(code), true
+}
+
+// Referral приглашение пользователя по реферальной ссылке
+type Referral struct {
+	// ReferrerID пригласивший пользователь
+	ReferrerID user.ID
+	// ReferredID приглашенный пользователь
+	ReferredID user.ID
+	// CreatedAt время приглашения
+	CreatedAt time.Time
+}
+
+// RewardKind вид награды за оплаченный заказ приглашенного
+type RewardKind string
+
+const (
+	// NoReward награда не начисляется
+	NoReward RewardKind = ""
+	// DaysReward бонусные дни к подписке
+	DaysReward RewardKind = "days"
+	// DiscountReward скидка на следующий заказ
+	DiscountReward RewardKind = "discount"
+)
+
+// String строковое представление
+func (k RewardKind) String() string {
+	return string(k)
+}
+
+// RewardKindFromString вид награды из строки
+func RewardKindFromString(s string) (RewardKind, bool) {
+	switch s {
+	case string(NoReward):
+		return NoReward, true
+	case string(DaysReward):
+		return DaysReward, true
+	case string(DiscountReward):
+		return DiscountReward, true
+	}
+
+	return NoReward, false
+}
+
+// Policy настройки награды за оплаченный заказ приглашенного
+type Policy struct {
+	// Kind вид награды
+	Kind RewardKind
+	// Days кол-во бонусных дней для DaysReward
+	Days int
+	// DiscountPercent процент скидки для DiscountReward
+	DiscountPercent int
+}
+
+// Validate проверяет настройки награды
+func (p Policy) Validate() error {
+	switch p.Kind {
+	case NoReward:
+		return nil
+	case DaysReward:
+		if p.Days <= 0 || p.Days > subscription.MaxExtendDays {
+			return fmt.Errorf("%w: bonus days must be in 1..%d", ErrInvalidPolicy, subscription.MaxExtendDays)
+		}
+	case DiscountReward:
+		if p.DiscountPercent <= 0 || p.DiscountPercent > MaxDiscountPercent {
+			return fmt.Errorf("%w: discount percent must be in 1..%d", ErrInvalidPolicy, MaxDiscountPercent)
+		}
+	default:
+		return fmt.Errorf("%w: unknown reward %q", ErrInvalidPolicy, p.Kind)
+	}
+
+	return nil
+}
+
+// NewReward награда пригласившему за оплаченный заказ приглашенного
+func (p Policy) NewReward(r Referral, orderID order.ID) Reward {
+	reward := Reward{
+		ReferrerID: r.ReferrerID,
+		ReferredID: r.ReferredID,
+		OrderID:    orderID,
+		Kind:       p.Kind,
+	}
+
+	switch p.Kind {
+	case DaysReward:
+		reward.Days = p.Days
+		reward.State = PendingReward
+	case DiscountReward:
+		reward.DiscountPercent = p.DiscountPercent
+		reward.State = AvailableReward
+	}
+
+	return reward
+}
+
+// RewardState состояние награды
+type RewardState string
+
+const (
+	// UnknownReward неизвестное состояние
+	UnknownReward RewardState = ""
+	// PendingReward бонусные дни ждут действующей подписки
+	PendingReward RewardState = "pending"
+	// AvailableReward скидка доступна для следующего заказа
+	AvailableReward RewardState = "available"
+	// AppliedReward награда использована
+	AppliedReward RewardState = "applied"
+)
+
+// String строковое представление
+func (s RewardState) String() string {
+	return string(s)
+}
+
+// NewRewardState состояние награды из строки
+func NewRewardState(s string) RewardState {
+	switch s {
+	case string(PendingReward):
+		return PendingReward
+	case string(AvailableReward):
+		return AvailableReward
+	case string(AppliedReward):
+		return AppliedReward
+	}
+
+	return UnknownReward
+}
+
+// RewardID айди награды
+type RewardID struct {
+	ID string
+}
+
+// String строковое представление
+func (id RewardID) String() string {
+	return id.ID
+}
+
+// IsEmpty возвращает признак пустого ID
+func (id RewardID) IsEmpty() bool {
+	return id.ID == ""
+}
+
+// NewRewardID создает объект RewardID
+func NewRewardID[T string | int | int64](i T) RewardID {
+	return RewardID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Reward награда пригласившему за оплаченный заказ приглашенного
+type Reward struct {
+	// ID награды
+	ID RewardID
+	// ReferrerID пригласивший пользователь, получатель награды
+	ReferrerID user.ID
+	// ReferredID приглашенный пользователь
+	ReferredID user.ID
+	// OrderID оплаченный заказ приглашенного
+	OrderID order.ID
+	// Kind вид награды
+	Kind RewardKind
+	// Days кол-во бонусных дней
+	Days int
+	// DiscountPercent процент скидки
+	DiscountPercent int
+	// State состояние
+	State RewardState
+	// SubscriptionID подписка, продленная бонусными днями
+	SubscriptionID subscription.ID
+	// UsedOrderID заказ, к которому применена скидка
+	UsedOrderID order.ID
+	// CreatedAt время начисления
+	CreatedAt time.Time
+	// AppliedAt время использования
+	AppliedAt time.Time
+}
+
+// ApplyDiscount возвращает цену со скидкой percent процентов.
+// Цена в звездах округляется до целого, цена со скидкой не опускается ниже одной единицы валюты
+func ApplyDiscount(p price.Price, percent int) price.Price {
+	if percent <= 0 || p.Value.IsZero() {
+		return p
+	}
+
+	hundred := decimal.NewFromInt(100)
+	value := p.Value.Mul(hundred.Sub(decimal.NewFromInt(int64(percent)))).Div(hundred)
+	if p.Currency == price.XTR {
+		value = value.Floor()
+	} else {
+		value = value.Round(2)
+	}
+
+	if one := decimal.NewFromInt(1); value.LessThan(one) {
+		value = one
+	}
+
+	return price.Price{
+		Currency: p.Currency,
+		Value:    value,
+	}
+}
+
+// Stats статистика приглашений пользователя
+type Stats struct {
+	// Code реферальный код
+	Code Code
+	// Invited кол-во приглашенных пользователей
+	Invited int
+	// PaidOrders кол-во оплаченных заказов приглашенных
+	PaidOrders int
+	// BonusDays начисленные бонусные дни
+	BonusDays int
+	// PendingDays бонусные дни, ожидающие действующей подписки
+	PendingDays int
+	// Discounts кол-во доступных скидок
+	Discounts int
+}
@@ -0,0 +1,122 @@
+package referral
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/price"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+)
+
+func TestParseStartParam(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		param  string
+		want   Code
+		wantOk bool
+	}{
+		{name: "valid", param: "ref_abcd2345", want: "abcd2345", wantOk: true},
+		{name: "spaces", param: " ref_abcd2345 ", want: "abcd2345", wantOk: true},
+		{name: "no prefix", param: "abcd2345"},
+		{name: "empty", param: ""},
+		{name: "short", param: "ref_abcd234"},
+		{name: "long", param: "ref_abcd23456"},
+		{name: "ambiguous letter", param: "ref_abcd234l"},
+		{name: "upper case", param: "ref_ABCD2345"},
+		{name: "other start param", param: "promo_abcd2345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseStartParam(tt.param)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("ParseStartParam() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestNewCode(t *testing.T) {
+	t.Parallel()
+	code, err := NewCode()
+	if err != nil {
+		t.Fatalf("NewCode() error = %v", err)
+	}
+
+	if got, ok := ParseStartParam(code.StartParam()); !ok || got != code {
+		t.Errorf("ParseStartParam(%s) = %q, %v", code.StartParam(), got, ok)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{name: "no reward", policy: Policy{}},
+		{name: "days", policy: Policy{Kind: DaysReward, Days: 7}},
+		{name: "max days", policy: Policy{Kind: DaysReward, Days: subscription.MaxExtendDays}},
+		{name: "zero days", policy: Policy{Kind: DaysReward}, wantErr: true},
+		{name: "too many days", policy: Policy{Kind: DaysReward, Days: subscription.MaxExtendDays + 1}, wantErr: true},
+		{name: "discount", policy: Policy{Kind: DiscountReward, DiscountPercent: 10}},
+		{name: "max discount", policy: Policy{Kind: DiscountReward, DiscountPercent: MaxDiscountPercent}},
+		{name: "zero discount", policy: Policy{Kind: DiscountReward}, wantErr: true},
+		{name: "full discount", policy: Policy{Kind: DiscountReward, DiscountPercent: 100}, wantErr: true},
+		{name: "unknown kind", policy: Policy{Kind: "cashback", Days: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.policy.Validate()
+			if tt.wantErr != errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDiscount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		price   price.Price
+		percent int
+		want    string
+	}{
+		{name: "rub", price: newPrice(price.RUB, "1000"), percent: 15, want: "850"},
+		{name: "rub rounded to kopecks", price: newPrice(price.RUB, "99.99"), percent: 33, want: "66.99"},
+		{name: "stars rounded down", price: newPrice(price.XTR, "15"), percent: 10, want: "13"},
+		{name: "floor of one", price: newPrice(price.XTR, "5"), percent: 99, want: "1"},
+		{name: "floor of one rub", price: newPrice(price.RUB, "1.5"), percent: 90, want: "1"},
+		{name: "zero percent", price: newPrice(price.RUB, "100"), percent: 0, want: "100"},
+		{name: "free", price: newPrice(price.XTR, "0"), percent: 50, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := ApplyDiscount(tt.price, tt.percent)
+			if got.Currency != tt.price.Currency {
+				t.Errorf("ApplyDiscount() currency = %s, want %s", got.Currency, tt.price.Currency)
+			}
+
+			if !got.Value.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("ApplyDiscount() = %s, want %s", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func newPrice(c price.Currency, value string) price.Price {
+	return price.Price{
+		Currency: c,
+		Value:    decimal.RequireFromString(value),
+	}
+}
@@ -0,0 +1,216 @@
+package subscription
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// AdminAction действие администратора с подпиской
+type AdminAction string
+
+const (
+	// UnknownAction неизвестное действие
+	UnknownAction AdminAction = ""
+	// RevokeAction отзыв подписки
+	RevokeAction AdminAction = "revoke"
+	// PauseAction приостановка подписки
+	PauseAction AdminAction = "pause"
+	// ResumeAction возобновление приостановленной подписки
+	ResumeAction AdminAction = "resume"
+	// ExtendAction продление подписки на несколько дней
+	ExtendAction AdminAction = "extend"
+)
+
+// String строковое представление
+func (a AdminAction) String() string {
+	return string(a)
+}
+
+// AdminActionFromString действие из строки
+func AdminActionFromString(s string) AdminAction {
+	switch s {
+	case string(RevokeAction):
+		return RevokeAction
+	case string(PauseAction):
+		return PauseAction
+	case string(ResumeAction):
+		return ResumeAction
+	case string(ExtendAction):
+		return ExtendAction
+	}
+
+	return UnknownAction
+}
+
+const (
+	// RevokedEvent событие отзыва подписки
+	RevokedEvent = "subscription.revoked"
+	// PausedEvent событие приостановки подписки
+	PausedEvent = "subscription.paused"
+	// ResumedEvent событие возобновления подписки
+	ResumedEvent = "subscription.resumed"
+	// ExtendedEvent событие продления подписки администратором
+	ExtendedEvent = "subscription.extended"
+)
+
+// Event тип события жизненного цикла, порождаемого действием
+func (a AdminAction) Event() string {
+	switch a {
+	case RevokeAction:
+		return RevokedEvent
+	case PauseAction:
+		return PausedEvent
+	case ResumeAction:
+		return ResumedEvent
+	case ExtendAction:
+		return ExtendedEvent
+	}
+
+	return ""
+}
+
+// MaxExtendDays максимальное кол-во дней продления администратором за один раз
+const MaxExtendDays = 3650
+
+var (
+	// ErrUnknownAction ошибка неизвестного действия
+	ErrUnknownAction = errors.New("unknown subscription action")
+	// ErrEmptyReason ошибка отсутствия причины изменения
+	ErrEmptyReason = errors.New("change reason is required")
+	// ErrInvalidExtendDays ошибка недопустимого кол-ва дней продления
+	ErrInvalidExtendDays = errors.New("invalid extend days")
+	// ErrSubscriptionChanged ошибка изменения подписки другим процессом
+	ErrSubscriptionChanged = errors.New("subscription was changed concurrently")
+)
+
+// ChangeRequest запрос администратора на изменение подписки
+type ChangeRequest struct {
+	// SubscriptionID ID подписки
+	SubscriptionID ID
+	// Action действие
+	Action AdminAction
+	// Days кол-во дней продления для ExtendAction
+	Days int
+	// Reason причина изменения
+	Reason string
+	// AdminID администратор, пустой - изменение вносит система
+	AdminID user.ID
+}
+
+// ChangeID айди изменения
+type ChangeID struct {
+	ID string
+}
+
+// String строковое представление
+func (id ChangeID) String() string {
+	return id.ID
+}
+
+// NewChangeID создает объект ChangeID
+func NewChangeID[T string | int | int64](i T) ChangeID {
+	return ChangeID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Change изменение подписки администратором
+type Change struct {
+	// ID изменения
+	ID ChangeID
+	// SubscriptionID ID подписки
+	SubscriptionID ID
+	// Action действие
+	Action AdminAction
+	// Reason причина изменения
+	Reason string
+	// AdminID администратор, пустой - изменение вносит система
+	AdminID user.ID
+	// State переход состояния подписки
+	State ChangeState
+	// PreviousDeadline окончание подписки до изменения
+	PreviousDeadline time.Time
+	// NewDeadline окончание подписки после изменения
+	NewDeadline time.Time
+	// PausedAt время приостановки после изменения, нулевое - подписка не приостановлена
+	PausedAt time.Time
+	// CreatedAt время изменения
+	CreatedAt time.Time
+}
+
+// NewChange рассчитывает изменение подписки s по запросу администратора в момент now.
+// Приостановка замораживает оставшееся время: при возобновлении окончание сдвигается на длительность паузы
+func NewChange(s Subscription, req ChangeRequest, now time.Time) (Change, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return Change{}, custom_errors.NewBadRequestError(ErrEmptyReason)
+	}
+
+	c := Change{
+		SubscriptionID:   s.ID,
+		Action:           req.Action,
+		Reason:           req.Reason,
+		AdminID:          req.AdminID,
+		PreviousDeadline: s.Deadline,
+		NewDeadline:      s.Deadline,
+		PausedAt:         s.PausedAt,
+	}
+
+	var to State
+	switch req.Action {
+	case RevokeAction:
+		to = RevokedState
+		c.PausedAt = time.Time{}
+	case PauseAction:
+		to = PausedState
+		c.PausedAt = now
+	case ResumeAction:
+		to = ActiveState
+		if !s.PausedAt.IsZero() {
+			c.NewDeadline = s.Deadline.Add(now.Sub(s.PausedAt))
+		}
+		c.PausedAt = time.Time{}
+	case ExtendAction:
+		if req.Days <= 0 || req.Days > MaxExtendDays {
+			return Change{}, custom_errors.NewBadRequestError(ErrInvalidExtendDays)
+		}
+
+		to = s.State
+		from := s.Deadline
+		switch s.State {
+		case InactiveState:
+			// истекшая подписка продлевается от текущего момента и снова становится активной
+			to = ActiveState
+			if from.Before(now) {
+				from = now
+			}
+		case GraceState:
+			// в льготном периоде подписка продлевается от прежнего окончания, без разрыва
+			to = ActiveState
+		}
+		c.NewDeadline = from.AddDate(0, 0, req.Days)
+	default:
+		return Change{}, custom_errors.NewBadRequestError(ErrUnknownAction)
+	}
+
+	if to == s.State {
+		if req.Action != ExtendAction || (s.State != ActiveState && s.State != PausedState) {
+			return Change{}, custom_errors.NewBadRequestError(errors.New("can not change status"))
+		}
+		c.State = ChangeState{From: s.State, To: to}
+		return c, nil
+	}
+
+	state, err := NewChangeItemStatus(s.State, to)
+	if err != nil {
+		return Change{}, err
+	}
+	c.State = state
+
+	return c, nil
+}
@@ -0,0 +1,147 @@
+package subscription
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestState_CanChangeStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		from State
+		to   State
+		want bool
+	}{
+		{ActiveState, PausedState, true},
+		{ActiveState, RevokedState, true},
+		{ActiveState, GraceState, true},
+		{GraceState, ActiveState, true},
+		{GraceState, PausedState, false},
+		{PausedState, ActiveState, true},
+		{PausedState, InactiveState, false},
+		{InactiveState, ActiveState, true},
+		{InactiveState, PausedState, false},
+		{RevokedState, ActiveState, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanChangeStatus(tt.to); got != tt.want {
+			t.Errorf("%s.CanChangeStatus(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestNewChange(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	deadline := now.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name         string
+		sub          Subscription
+		req          ChangeRequest
+		wantTo       State
+		wantDeadline time.Time
+		wantPaused   bool
+		wantErr      error
+	}{
+		{
+			name:         "pause freezes deadline",
+			sub:          Subscription{State: ActiveState, Deadline: deadline},
+			req:          ChangeRequest{Action: PauseAction, Reason: "check"},
+			wantTo:       PausedState,
+			wantDeadline: deadline,
+			wantPaused:   true,
+		},
+		{
+			name:         "resume shifts deadline by pause",
+			sub:          Subscription{State: PausedState, Deadline: deadline, PausedAt: now.AddDate(0, 0, -3)},
+			req:          ChangeRequest{Action: ResumeAction, Reason: "ok"},
+			wantTo:       ActiveState,
+			wantDeadline: deadline.AddDate(0, 0, 3),
+		},
+		{
+			name:         "extend active",
+			sub:          Subscription{State: ActiveState, Deadline: deadline},
+			req:          ChangeRequest{Action: ExtendAction, Days: 5, Reason: "gift"},
+			wantTo:       ActiveState,
+			wantDeadline: deadline.AddDate(0, 0, 5),
+		},
+		{
+			name:         "extend inactive from now",
+			sub:          Subscription{State: InactiveState, Deadline: now.AddDate(0, 0, -7)},
+			req:          ChangeRequest{Action: ExtendAction, Days: 5, Reason: "gift"},
+			wantTo:       ActiveState,
+			wantDeadline: now.AddDate(0, 0, 5),
+		},
+		{
+			name:         "extend grace from old deadline",
+			sub:          Subscription{State: GraceState, Deadline: now.AddDate(0, 0, -1)},
+			req:          ChangeRequest{Action: ExtendAction, Days: 5, Reason: "gift"},
+			wantTo:       ActiveState,
+			wantDeadline: now.AddDate(0, 0, 4),
+		},
+		{
+			name:         "revoke",
+			sub:          Subscription{State: ActiveState, Deadline: deadline},
+			req:          ChangeRequest{Action: RevokeAction, Reason: "fraud"},
+			wantTo:       RevokedState,
+			wantDeadline: deadline,
+		},
+		{
+			name:    "empty reason",
+			sub:     Subscription{State: ActiveState, Deadline: deadline},
+			req:     ChangeRequest{Action: PauseAction, Reason: "  "},
+			wantErr: ErrEmptyReason,
+		},
+		{
+			name:    "too many days",
+			sub:     Subscription{State: ActiveState, Deadline: deadline},
+			req:     ChangeRequest{Action: ExtendAction, Days: MaxExtendDays + 1, Reason: "gift"},
+			wantErr: ErrInvalidExtendDays,
+		},
+		{
+			name:    "unknown action",
+			sub:     Subscription{State: ActiveState, Deadline: deadline},
+			req:     ChangeRequest{Action: UnknownAction, Reason: "x"},
+			wantErr: ErrUnknownAction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewChange(tt.sub, tt.req, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewChange() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewChange() error = %v", err)
+			}
+
+			if c.State.From != tt.sub.State || c.State.To != tt.wantTo {
+				t.Errorf("NewChange() state = %+v, want %s -> %s", c.State, tt.sub.State, tt.wantTo)
+			}
+
+			if !c.NewDeadline.Equal(tt.wantDeadline) || !c.PreviousDeadline.Equal(tt.sub.Deadline) {
+				t.Errorf("NewChange() deadline = %v, want %v", c.NewDeadline, tt.wantDeadline)
+			}
+
+			if c.PausedAt.IsZero() == tt.wantPaused {
+				t.Errorf("NewChange() pausedAt = %v, want paused %v", c.PausedAt, tt.wantPaused)
+			}
+		})
+	}
+}
+
+func TestNewChange_RevokedCanNotResume(t *testing.T) {
+	t.Parallel()
+	_, err := NewChange(Subscription{State: RevokedState}, ChangeRequest{Action: ResumeAction, Reason: "x"}, time.Now())
+	if err == nil {
+		t.Errorf("NewChange() error = nil, want error")
+	}
+}
@@ -3,10 +3,12 @@ package subscription
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	domainOrder "github.com/kdv2001/onlySubscription/internal/domain/order"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
 	"github.com/kdv2001/onlySubscription/internal/domain/user"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
@@ -38,6 +40,12 @@ const (
 	ActiveState State = "active"
 	// InactiveState статус не активен
 	InactiveState State = "inactive"
+	// PausedState подписка приостановлена администратором, оставшееся время заморожено
+	PausedState State = "paused"
+	// RevokedState подписка отозвана администратором
+	RevokedState State = "revoked"
+	// GraceState подписка истекла, но доступ сохраняется до окончания льготного периода
+	GraceState State = "grace"
 )
 
 // String строковое представление
@@ -54,11 +62,28 @@ func NewState(s string) State {
 		return ActiveState
 	case string(InactiveState):
 		return InactiveState
+	case string(PausedState):
+		return PausedState
+	case string(RevokedState):
+		return RevokedState
+	case string(GraceState):
+		return GraceState
 	}
 
 	return UnknownState
 }
 
+const (
+	// CreatedEvent событие оформления подписки
+	CreatedEvent = "subscription.created"
+	// RenewedEvent событие продления подписки
+	RenewedEvent = "subscription.renewed"
+	// ExpiredEvent событие окончания подписки
+	ExpiredEvent = "subscription.expired"
+	// GraceStartedEvent событие начала льготного периода
+	GraceStartedEvent = "subscription.grace_started"
+)
+
 // Subscription подписка
 type Subscription struct {
 	// ID подписки
@@ -67,10 +92,20 @@ type Subscription struct {
 	UserID user.ID
 	// OrderID ID заказа
 	OrderID domainOrder.ID
+	// TariffID ID купленного тарифа
+	TariffID domainProducts.TariffID
+	// ProductID ID продукта
+	ProductID domainProducts.ID
+	// AccessChatID приватный канал или группа, доступ в которые выдает подписка, 0 - без доступа
+	AccessChatID int64
 	// State состояние подписки
 	State State
 	// Deadline время окончания подписки
 	Deadline time.Time
+	// PausedAt время приостановки, нулевое - подписка не приостановлена
+	PausedAt time.Time
+	// GraceUntil окончание льготного периода, нулевое - подписка не в льготном периоде
+	GraceUntil time.Time
 	// CreatedAt время создания
 	CreatedAt time.Time
 	// UpdatedAt время последнего обновления
@@ -79,12 +114,134 @@ type Subscription struct {
 	Description string
 }
 
+// HasAccess подписка дает доступ в момент now: активна и не истекла либо находится в льготном периоде
+func (s Subscription) HasAccess(now time.Time) bool {
+	switch s.State {
+	case ActiveState:
+		return s.Deadline.After(now)
+	case GraceState:
+		return s.GraceUntil.After(now)
+	}
+
+	return false
+}
+
+// Purchase покупка подписки по оплаченному заказу
+type Purchase struct {
+	// UserID пользователя
+	UserID user.ID
+	// OrderID ID заказа
+	OrderID domainOrder.ID
+	// ProductID ID продукта
+	ProductID domainProducts.ID
+	// TariffID ID купленного тарифа
+	TariffID domainProducts.TariffID
+	// Period оплаченный календарный период
+	Period domainProducts.Period
+	// AccessChatID приватный канал или группа продукта, 0 - без доступа
+	AccessChatID int64
+	// Description описание
+	Description string
+}
+
+// ChatAccess доступ подписчика в приватный канал или группу
+type ChatAccess struct {
+	// SubscriptionID ID подписки
+	SubscriptionID ID
+	// ChatID ID чата
+	ChatID int64
+	// UserID пользователя
+	UserID user.ID
+	// TelegramUserID ID пользователя в телеграм
+	TelegramUserID int64
+	// InviteLink одноразовая ссылка-приглашение
+	InviteLink string
+	// CreatedAt время выдачи доступа
+	CreatedAt time.Time
+	// RevokedAt время отзыва доступа, нулевое - доступ действует
+	RevokedAt time.Time
+}
+
+// IsRevoked признак отозванного доступа
+func (a ChatAccess) IsRevoked() bool {
+	return !a.RevokedAt.IsZero()
+}
+
+// RenewalID айди продления
+type RenewalID struct {
+	ID string
+}
+
+// String строковое представление
+func (id RenewalID) String() string {
+	return id.ID
+}
+
+// NewRenewalID создает объект RenewalID
+func NewRenewalID[T string | int | int64](i T) RenewalID {
+	return RenewalID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Renewal продление подписки
+type Renewal struct {
+	// ID продления
+	ID RenewalID
+	// SubscriptionID ID продленной подписки
+	SubscriptionID ID
+	// OrderID ID заказа продления
+	OrderID domainOrder.ID
+	// TariffID ID оплаченного тарифа
+	TariffID domainProducts.TariffID
+	// PreviousDeadline окончание подписки до продления
+	PreviousDeadline time.Time
+	// NewDeadline окончание подписки после продления
+	NewDeadline time.Time
+	// CreatedAt время продления
+	CreatedAt time.Time
+}
+
+// RenewableStates состояния подписки, которую оплаченный заказ продлевает, а не заменяет новой.
+// Приостановленная подписка продлевается и остается приостановленной
+var RenewableStates = []State{ActiveState, GraceState, PausedState}
+
+// CanRenew признак подписки, которую оплаченный заказ продлевает
+func CanRenew(s State) bool {
+	return slices.Contains(RenewableStates, s)
+}
+
+// NewRenewal создает продление подписки s на период period.
+// Период добавляется к текущему окончанию подписки, а если оно уже прошло - к моменту now.
+// Подписка в льготном периоде продлевается от прежнего окончания, без разрыва.
+// У приостановленной подписки оставшееся время заморожено, поэтому она продлевается от прежнего окончания
+func NewRenewal(s Subscription, p Purchase, now time.Time) Renewal {
+	from := s.Deadline
+	if from.Before(now) && s.State != GraceState && s.State != PausedState {
+		from = now
+	}
+
+	return Renewal{
+		SubscriptionID:   s.ID,
+		OrderID:          p.OrderID,
+		TariffID:         p.TariffID,
+		PreviousDeadline: s.Deadline,
+		NewDeadline:      p.Period.AddTo(from),
+	}
+}
+
 // Filters фильтры
 type Filters struct {
+	// UserID фильтр по пользователю
+	UserID user.ID
+	// ProductID фильтр по продукту
+	ProductID domainProducts.ID
 	// Statuses фильтр по статусу
 	Statuses []State
 	// Deadline фильтр по окончанию подписки
 	Deadline *primitives.IntervalFilter[time.Time]
+	// GraceUntil фильтр по окончанию льготного периода
+	GraceUntil *primitives.IntervalFilter[time.Time]
 }
 
 // RequestList список параметров запроса
@@ -112,7 +269,24 @@ func (s State) CanChangeStatus(toStatus State) bool {
 	switch s {
 	case ActiveState:
 		switch toStatus {
-		case InactiveState:
+		case InactiveState, PausedState, RevokedState, GraceState:
+			return true
+		}
+	case GraceState:
+		// продление в льготном периоде возвращает подписку в активные
+		switch toStatus {
+		case ActiveState, InactiveState, RevokedState:
+			return true
+		}
+	case PausedState:
+		switch toStatus {
+		case ActiveState, RevokedState:
+			return true
+		}
+	case InactiveState:
+		// продление администратором возобновляет истекшую подписку
+		switch toStatus {
+		case ActiveState:
 			return true
 		}
 	}
@@ -0,0 +1,136 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+)
+
+func TestNewRenewal(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	month := domainProducts.NewPeriod(1, domainProducts.MonthUnit)
+
+	tests := []struct {
+		name     string
+		state    State
+		deadline time.Time
+		want     time.Time
+	}{
+		{
+			name:     "active continues from deadline",
+			state:    ActiveState,
+			deadline: now.AddDate(0, 0, 5),
+			want:     now.AddDate(0, 1, 5),
+		},
+		{
+			name:     "expired active continues from now",
+			state:    ActiveState,
+			deadline: now.AddDate(0, 0, -5),
+			want:     now.AddDate(0, 1, 0),
+		},
+		{
+			name:     "grace continues from old deadline",
+			state:    GraceState,
+			deadline: now.AddDate(0, 0, -2),
+			want:     now.AddDate(0, 1, -2),
+		},
+		{
+			name:     "paused continues from frozen deadline",
+			state:    PausedState,
+			deadline: now.AddDate(0, 0, -2),
+			want:     now.AddDate(0, 1, -2),
+		},
+		{
+			name:     "inactive continues from now",
+			state:    InactiveState,
+			deadline: now.AddDate(0, 0, -2),
+			want:     now.AddDate(0, 1, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := Subscription{ID: NewID("sub"), State: tt.state, Deadline: tt.deadline}
+			r := NewRenewal(s, Purchase{OrderID: s.OrderID, Period: month}, now)
+
+			if !r.NewDeadline.Equal(tt.want) {
+				t.Errorf("NewRenewal() deadline = %v, want %v", r.NewDeadline, tt.want)
+			}
+
+			if !r.PreviousDeadline.Equal(tt.deadline) || r.SubscriptionID != s.ID {
+				t.Errorf("NewRenewal() = %+v, want previous deadline %v", r, tt.deadline)
+			}
+		})
+	}
+}
+
+func TestCanRenew(t *testing.T) {
+	t.Parallel()
+	tests := map[State]bool{
+		ActiveState:   true,
+		GraceState:    true,
+		PausedState:   true,
+		InactiveState: false,
+		RevokedState:  false,
+	}
+
+	for state, want := range tests {
+		if got := CanRenew(state); got != want {
+			t.Errorf("CanRenew(%s) = %v, want %v", state, got, want)
+		}
+	}
+}
+
+func TestSubscription_HasAccess(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		s    Subscription
+		want bool
+	}{
+		{
+			name: "active before deadline",
+			s:    Subscription{State: ActiveState, Deadline: now.Add(time.Hour)},
+			want: true,
+		},
+		{
+			name: "active after deadline",
+			s:    Subscription{State: ActiveState, Deadline: now.Add(-time.Hour)},
+			want: false,
+		},
+		{
+			name: "grace before grace end",
+			s:    Subscription{State: GraceState, Deadline: now.Add(-time.Hour), GraceUntil: now.Add(time.Hour)},
+			want: true,
+		},
+		{
+			name: "grace after grace end",
+			s:    Subscription{State: GraceState, Deadline: now.Add(-2 * time.Hour), GraceUntil: now.Add(-time.Hour)},
+			want: false,
+		},
+		{
+			name: "inactive",
+			s:    Subscription{State: InactiveState, Deadline: now.Add(time.Hour)},
+			want: false,
+		},
+		{
+			name: "paused",
+			s:    Subscription{State: PausedState, Deadline: now.Add(time.Hour)},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.s.HasAccess(now); got != tt.want {
+				t.Errorf("HasAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
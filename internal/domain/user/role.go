@@ -0,0 +1,95 @@
+package user
+
+import (
+	"errors"
+)
+
+// Role роль пользователя в панели администрирования
+type Role string
+
+const (
+	// CustomerRole покупатель без доступа к панели администрирования
+	CustomerRole Role = ""
+	// OwnerRole владелец магазина, управляет всем, в том числе ролями
+	OwnerRole Role = "owner"
+	// ManagerRole менеджер, управляет продуктами, подписками, вебхуками, блокировками и данными пользователей
+	ManagerRole Role = "manager"
+	// SupportRole поддержка, управляет подписками, блокировками и данными покупателей
+	SupportRole Role = "support"
+)
+
+// String строковое представление
+func (r Role) String() string {
+	return string(r)
+}
+
+// ErrUnknownRole ошибка неизвестной роли
+var ErrUnknownRole = errors.New("unknown role")
+
+// RoleFromString роль из строки, "none" и пустая строка - покупатель
+func RoleFromString(s string) (Role, error) {
+	switch s {
+	case string(CustomerRole), "none":
+		return CustomerRole, nil
+	case string(OwnerRole):
+		return OwnerRole, nil
+	case string(ManagerRole):
+		return ManagerRole, nil
+	case string(SupportRole):
+		return SupportRole, nil
+	}
+
+	return CustomerRole, ErrUnknownRole
+}
+
+// Permission право на действие в панели администрирования
+type Permission string
+
+const (
+	// AdminPanelPermission доступ к панели администрирования
+	AdminPanelPermission Permission = "admin_panel"
+	// ProductsPermission управление продуктами, инвентарем и тарифами
+	ProductsPermission Permission = "products"
+	// SubscriptionsPermission управление подписками покупателей
+	SubscriptionsPermission Permission = "subscriptions"
+	// WebhooksPermission просмотр и повторная отправка вебхуков
+	WebhooksPermission Permission = "webhooks"
+	// UsersPermission блокировка пользователей, выгрузка и удаление их данных
+	UsersPermission Permission = "users"
+	// RolesPermission назначение ролей
+	RolesPermission Permission = "roles"
+)
+
+var rolePermissions = map[Role][]Permission{
+	OwnerRole: {
+		AdminPanelPermission,
+		ProductsPermission,
+		SubscriptionsPermission,
+		WebhooksPermission,
+		UsersPermission,
+		RolesPermission,
+	},
+	ManagerRole: {
+		AdminPanelPermission,
+		ProductsPermission,
+		SubscriptionsPermission,
+		WebhooksPermission,
+		UsersPermission,
+	},
+	SupportRole: {
+		AdminPanelPermission,
+		SubscriptionsPermission,
+		UsersPermission,
+	},
+}
+
+// Can возвращает признак наличия у роли права p
+func (r Role) Can(p Permission) bool {
+	for _, cur := range rolePermissions[r] {
+		if cur == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoleFromString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    Role
+		wantErr error
+	}{
+		{in: "", want: CustomerRole},
+		{in: "none", want: CustomerRole},
+		{in: "owner", want: OwnerRole},
+		{in: "manager", want: ManagerRole},
+		{in: "support", want: SupportRole},
+		{in: "admin", want: CustomerRole, wantErr: ErrUnknownRole},
+		{in: "Owner", want: CustomerRole, wantErr: ErrUnknownRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := RoleFromString(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RoleFromString() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("RoleFromString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRole_Can(t *testing.T) {
+	t.Parallel()
+	permissions := []Permission{
+		AdminPanelPermission,
+		ProductsPermission,
+		SubscriptionsPermission,
+		WebhooksPermission,
+		UsersPermission,
+		RolesPermission,
+	}
+
+	tests := []struct {
+		role Role
+		want []Permission
+	}{
+		{role: CustomerRole},
+		{role: OwnerRole, want: permissions},
+		{
+			role: ManagerRole,
+			want: []Permission{
+				AdminPanelPermission,
+				ProductsPermission,
+				SubscriptionsPermission,
+				WebhooksPermission,
+				UsersPermission,
+			},
+		},
+		{
+			role: SupportRole,
+			want: []Permission{AdminPanelPermission, SubscriptionsPermission, UsersPermission},
+		},
+		{role: Role("unknown")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role.String(), func(t *testing.T) {
+			t.Parallel()
+			for _, p := range permissions {
+				want := false
+				for _, w := range tt.want {
+					if w == p {
+						want = true
+					}
+				}
+
+				if got := tt.role.Can(p); got != want {
+					t.Errorf("Can(%s) = %v, want %v", p, got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package user
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 type State string
@@ -11,8 +17,26 @@ const (
 	UnknownState State = "unknown"
 	// VerifiedState ...
 	VerifiedState State = "verified"
+	// BlockedState пользователь заблокирован администратором
+	BlockedState State = "blocked"
+	// DeletedState данные пользователя удалены по его запросу, финансовые записи сохранены обезличенными
+	DeletedState State = "deleted"
 )
 
+// NewState состояние из строки
+func NewState(s string) State {
+	switch s {
+	case string(VerifiedState):
+		return VerifiedState
+	case string(BlockedState):
+		return BlockedState
+	case string(DeletedState):
+		return DeletedState
+	}
+
+	return UnknownState
+}
+
 func (s State) String() string {
 	return string(s)
 }
@@ -50,12 +74,110 @@ type User struct {
 	ID ID
 	// Contact контактные данные
 	Contact Contact
+	// Role роль в панели администрирования
+	Role Role
+	// State состояние пользователя
+	State State
+	// Ban действующая блокировка, заполнена для BlockedState
+	Ban Ban
+	// Language язык, выбранный пользователем, пустой - как в Telegram
+	Language locale.Lang
+	// TelegramLanguage код языка клиента Telegram
+	TelegramLanguage string
+	// UnreachableAt время, когда пользователь заблокировал бота, нулевое - сообщения доставляются
+	UnreachableAt time.Time
+}
+
+// IsUnreachable возвращает признак пользователя, заблокировавшего бота
+func (u User) IsUnreachable() bool {
+	return !u.UnreachableAt.IsZero()
+}
+
+// Lang возвращает язык интерфейса пользователя
+func (u User) Lang() locale.Lang {
+	if u.Language != "" {
+		return u.Language
+	}
+
+	return locale.FromTelegram(u.TelegramLanguage)
+}
+
+// IsBlocked возвращает признак действующей в момент now блокировки
+func (u User) IsBlocked(now time.Time) bool {
+	return u.State == BlockedState && (u.Ban.Until.IsZero() || u.Ban.Until.After(now))
+}
+
+// Ban блокировка пользователя
+type Ban struct {
+	// Reason причина блокировки
+	Reason string
+	// Until окончание блокировки, нулевое - бессрочно
+	Until time.Time
+	// AdminID администратор, заблокировавший пользователя
+	AdminID ID
+	// CreatedAt время блокировки
+	CreatedAt time.Time
+}
+
+var (
+	// ErrEmptyBanReason ошибка отсутствия причины блокировки
+	ErrEmptyBanReason = errors.New("ban reason is required")
+	// ErrBanExpired ошибка окончания блокировки в прошлом
+	ErrBanExpired = errors.New("ban expiry is in the past")
+)
+
+// BanRequest запрос администратора на блокировку пользователя
+type BanRequest struct {
+	// TelegramID блокируемого пользователя
+	TelegramID TelegramID
+	// Reason причина блокировки
+	Reason string
+	// Until окончание блокировки, нулевое - бессрочно
+	Until time.Time
+	// AdminID администратор
+	AdminID ID
+}
+
+// NewBan создает блокировку по запросу в момент now
+func NewBan(req BanRequest, now time.Time) (Ban, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return Ban{}, custom_errors.NewBadRequestError(ErrEmptyBanReason)
+	}
+
+	if !req.Until.IsZero() && !req.Until.After(now) {
+		return Ban{}, custom_errors.NewBadRequestError(ErrBanExpired)
+	}
+
+	return Ban{
+		Reason:    reason,
+		Until:     req.Until,
+		AdminID:   req.AdminID,
+		CreatedAt: now,
+	}, nil
 }
 
 // Contact контакты
 type Contact struct {
+	// TelegramID ID пользователя в телеграм
+	TelegramID TelegramID
 	// TelegramBotChatID ID телеграм чата
 	TelegramBotChatID int64
+	// TelegramUsername имя пользователя в телеграм без @, может быть пустым
+	TelegramUsername string
+	// FirstName имя в профиле телеграм
+	FirstName string
+	// LastName фамилия в профиле телеграм
+	LastName string
+}
+
+// DisplayName имя пользователя для отображения: @username или имя и фамилия
+func (c Contact) DisplayName() string {
+	if c.TelegramUsername != "" {
+		return "@" + c.TelegramUsername
+	}
+
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
 }
 
 // TelegramID айди
@@ -75,8 +197,27 @@ func NewTelegramID[T string | int | int64](i T) TelegramID {
 	}
 }
 
+// deletedTelegramPrefix префикс telegram ID пользователя с удаленными данными
+const deletedTelegramPrefix = "deleted:"
+
+// DeletedTelegramID заменяет telegram ID пользователя при удалении данных.
+// Настоящий ID освобождается, и пользователь может зарегистрироваться заново
+func DeletedTelegramID(id ID) TelegramID {
+	return TelegramID{
+		ID: deletedTelegramPrefix + id.ID,
+	}
+}
+
 // TelegramBotRegister авторизация через телеграм
 type TelegramBotRegister struct {
 	TelegramID TelegramID
 	ChatID     int64
+	// LanguageCode код языка клиента Telegram
+	LanguageCode string
+	// Username имя пользователя в телеграм без @
+	Username string
+	// FirstName имя в профиле телеграм
+	FirstName string
+	// LastName фамилия в профиле телеграм
+	LastName string
 }
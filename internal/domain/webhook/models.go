@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+)
+
+// DeliveryID айди доставки
+type DeliveryID struct {
+	ID string
+}
+
+// String строковое представление
+func (id DeliveryID) String() string {
+	return id.ID
+}
+
+// NewDeliveryID создает объект DeliveryID
+func NewDeliveryID[T string | int | int64](i T) DeliveryID {
+	return DeliveryID{
+		ID: fmt.Sprint(i),
+	}
+}
+
+// Status статус доставки
+type Status string
+
+const (
+	// UnknownStatus неизвестный статус
+	UnknownStatus Status = ""
+	// PendingStatus ожидает отправки
+	PendingStatus Status = "pending"
+	// DeliveredStatus доставлено
+	DeliveredStatus Status = "delivered"
+	// FailedStatus попытки доставки исчерпаны
+	FailedStatus Status = "failed"
+)
+
+// String строковое представление
+func (s Status) String() string {
+	return string(s)
+}
+
+// StatusFromString статус из строки
+func StatusFromString(s string) Status {
+	switch s {
+	case string(PendingStatus):
+		return PendingStatus
+	case string(DeliveredStatus):
+		return DeliveredStatus
+	case string(FailedStatus):
+		return FailedStatus
+	}
+
+	return UnknownStatus
+}
+
+const (
+	// MaxAttempts кол-во попыток доставки, после которого доставка считается неудачной
+	MaxAttempts = 8
+	// retryBaseDelay задержка перед второй попыткой
+	retryBaseDelay = 30 * time.Second
+	// retryMaxDelay максимальная задержка между попытками
+	retryMaxDelay = time.Hour
+)
+
+// RetryDelay возвращает задержку перед следующей попыткой после attempts неудачных попыток,
+// задержка удваивается с каждой попыткой
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for j := 1; j < attempts; j++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+
+	return delay
+}
+
+// Delivery доставка события на вебхук
+type Delivery struct {
+	// ID доставки
+	ID DeliveryID
+	// Endpoint название получателя
+	Endpoint string
+	// Event событие
+	Event communication.Event
+	// Status статус доставки
+	Status Status
+	// Attempts кол-во выполненных попыток
+	Attempts int
+	// NextAttemptAt время следующей попытки
+	NextAttemptAt time.Time
+	// LastError ошибка последней попытки
+	LastError string
+	// CreatedAt время создания
+	CreatedAt time.Time
+	// DeliveredAt время успешной доставки
+	DeliveredAt time.Time
+}
+
+// Filters фильтры
+type Filters struct {
+	// Statuses фильтр по статусу
+	Statuses []Status
+}
+
+// RequestList запрос списка доставок
+type RequestList struct {
+	Pagination *primitives.Pagination
+	Filters    *Filters
+}
+
+// DeliveriesPage страница доставок
+type DeliveriesPage struct {
+	// Deliveries доставки
+	Deliveries []Delivery
+	// Total общее кол-во доставок без учета пагинации
+	Total uint64
+}
@@ -2,46 +2,98 @@ package telegram_bot
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
-	"strings"
+	"time"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/shopspring/decimal"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/price"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
-	"github.com/kdv2001/onlySubscription/pkg/form_parser"
+	domainSubscription "github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
 )
 
+// adminMenuButtons кнопки панели администрирования, показываются при наличии права на обработчик.
+// Текст кнопки - ключ перевода
+var adminMenuButtons = []models.InlineKeyboardButton{
+	{
+		Text:         string(locale.AdminCreateProduct),
+		CallbackData: getCreateProductInfoHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminProducts),
+		CallbackData: getAllProductsHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminArchive),
+		CallbackData: getArchiveHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminWebhooks),
+		CallbackData: webhookListHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminSubscriptions),
+		CallbackData: subscriptionSearchInfoHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminBans),
+		CallbackData: banInfoHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminStaff),
+		CallbackData: staffHandler.String(),
+	},
+	{
+		Text:         string(locale.AdminUserData),
+		CallbackData: userDataInfoHandler.String(),
+	},
+}
+
+// adminMenuColumns кол-во кнопок в строке панели администрирования
+const adminMenuColumns = 2
+
 func (i *Implementation) GetAdminMenu(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
-	results := [][]models.InlineKeyboardButton{
-		{
-			{
-				Text:         "Создать продукт",
-				CallbackData: getCreateProductInfoHandler.String(),
-			},
-			{
-				Text:         "Просмотр продуктов",
-				CallbackData: getAllProductsHandler.String(),
-			},
-		},
+	lang := getLangFromContext(ctx)
+	role := getRoleFromContext(ctx)
+
+	results := make([][]models.InlineKeyboardButton, 0, len(adminMenuButtons)/adminMenuColumns+2)
+	row := make([]models.InlineKeyboardButton, 0, adminMenuColumns)
+	for _, b := range adminMenuButtons {
+		if p, ok := handlerName(b.CallbackData).Permission(); ok && !role.Can(p) {
+			continue
+		}
+
+		b.Text = lang.T(locale.Key(b.Text))
+		row = append(row, b)
+		if len(row) == adminMenuColumns {
+			results = append(results, row)
+			row = make([]models.InlineKeyboardButton, 0, adminMenuColumns)
+		}
+	}
+	if len(row) != 0 {
+		results = append(results, row)
+	}
+
+	results = append(results, []models.InlineKeyboardButton{
 		{
-			{
-				Text:         "Назад",
-				CallbackData: adminHandler.GetBackHandler().String(),
-			},
+			Text:         lang.T(locale.BackButton),
+			CallbackData: adminHandler.GetBackHandler().String(),
 		},
-	}
+	})
 
 	sender := msgInlineSender{
 		ChatID:       oldMsg.Chat.ID,
 		CurMessageID: oldMsg.ID,
-		Text:         "Панель администрирования.",
+		Text:         lang.T(locale.AdminText),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: results,
 		},
@@ -52,23 +104,24 @@ func (i *Implementation) GetAdminMenu(ctx context.Context, bot *telegramBot.Bot,
 
 func (i *Implementation) GetAllProducts(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 
-	l := strings.TrimPrefix(update.CallbackQuery.Data, getAllProductsHandler.String())
-	var offset int64
-	if l != "" {
-		var err error
-		offset, err = strconv.ParseInt(l, 10, 64)
-		if err != nil {
-			sendErrorMsg(ctx, bot, oldMsg, err, "")
-			return
-		}
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
 	}
 
 	cardsNum := maxProductsLines * maxProductsColumns
 	products, err := i.productsUseCase.GetProducts(ctx, domainProducts.RequestList{
 		Pagination: &primitives.Pagination{
 			Num:    maxProductsLines * maxProductsColumns,
-			Offset: uint64(offset) * uint64(cardsNum),
+			Offset: offset * uint64(cardsNum),
 		},
 	})
 	if err != nil {
@@ -76,8 +129,8 @@ func (i *Implementation) GetAllProducts(ctx context.Context, bot *telegramBot.Bo
 		return
 	}
 
-	pag := make([]*paginatorItem, 0, len(products))
-	for _, curProduct := range products {
+	pag := make([]*paginatorItem, 0, len(products.Products))
+	for _, curProduct := range products.Products {
 		pag = append(pag, &paginatorItem{
 			id:   curProduct.ID.String(),
 			name: curProduct.Name,
@@ -85,19 +138,20 @@ func (i *Implementation) GetAllProducts(ctx context.Context, bot *telegramBot.Bo
 	}
 
 	paginator := paginatorHandlerList{
-		nextHandler:        getProductForEditHandler.String(),
-		curHandler:         productsHandler.String(),
+		nextHandler:        getProductForEditHandler,
+		curHandler:         getAllProductsHandler,
 		maxProductsColumns: maxProductsColumns,
+		total:              products.Total,
 	}
 
 	keyboard := paginator.paginationKeyboard(pag, primitives.Pagination{
 		Num:    uint64(cardsNum),
-		Offset: uint64(offset),
+		Offset: offset,
 	})
 
 	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			Text:         "Назад",
+			Text:         lang.T(locale.BackButton),
 			CallbackData: getAllProductsHandler.GetBackHandler().String(),
 		},
 	})
@@ -105,7 +159,7 @@ func (i *Implementation) GetAllProducts(ctx context.Context, bot *telegramBot.Bo
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text:         "Выбирай товар и переходи к его настройке.",
+		Text:         lang.T(locale.AdminProductsText),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -116,24 +170,23 @@ func (i *Implementation) GetAllProducts(ctx context.Context, bot *telegramBot.Bo
 
 func (i *Implementation) GetProductForEdit(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 
-	separator := "?"
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
 
-	splited := strings.Split(update.CallbackQuery.Data, separator)
-	productIDStr := strings.TrimPrefix(splited[0], getProductForEditHandler.String())
-	if productIDStr == "" {
-		sendErrorMsg(ctx, bot, oldMsg, errors.New("error parse productID"), "")
+	productIDStr, err := data.ID(0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
 		return
 	}
 
-	var offset int64
-	if len(splited) > 1 {
-		var err error
-		offset, err = strconv.ParseInt(splited[1], 10, 64)
-		if err != nil {
-			sendErrorMsg(ctx, bot, oldMsg, err, "")
-			return
-		}
+	offset, err := callbackPage(data, 1)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
 	}
 
 	productID := domainProducts.NewID(productIDStr)
@@ -144,14 +197,14 @@ func (i *Implementation) GetProductForEdit(ctx context.Context, bot *telegramBot
 	}
 
 	cardsNum := maxProductsLines * maxProductsColumns
-	cardOffset := offset * int64(cardsNum)
+	cardOffset := offset * uint64(cardsNum)
 	items, err := i.productsUseCase.GetItems(ctx, domainProducts.RequestList{
 		Filters: &domainProducts.Filters{
 			ProductID: productID,
 		},
 		Pagination: &primitives.Pagination{
 			Num:    maxProductsLines * maxProductsColumns,
-			Offset: uint64(cardOffset),
+			Offset: cardOffset,
 		},
 	})
 	if err != nil {
@@ -163,35 +216,73 @@ func (i *Implementation) GetProductForEdit(ctx context.Context, bot *telegramBot
 	for j, curItem := range items {
 		pag = append(pag, &paginatorItem{
 			id:   curItem.ID.String(),
-			name: fmt.Sprint(int64(j) + cardOffset + 1),
+			name: fmt.Sprint(uint64(j) + cardOffset + 1),
 		})
 	}
 
 	paginator := paginatorHandlerList{
-		nextHandler:        getItemHandler.String(),
-		curHandler:         getProductForEditHandler.String() + productID.String() + separator,
+		nextHandler:        getItemHandler,
+		curHandler:         getProductForEditHandler,
+		curID:              productID.String(),
 		maxProductsColumns: maxProductsColumns,
 	}
 
 	keyboard := paginator.paginationKeyboard(pag, primitives.Pagination{
 		Num:    uint64(cardsNum),
-		Offset: uint64(offset),
+		Offset: offset,
 	})
 
+	for _, t := range product.Tariffs {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{
+				Text:         lang.T(locale.DeleteTariffButton, t.Name),
+				CallbackData: deleteTariffHandler.Data().ID(t.ID.String()).ExpiresIn(confirmTTL).String(),
+			},
+			{
+				Text:         lang.T(locale.SaleButton, t.Name),
+				CallbackData: getCreateSaleInfoHandler.Data().ID(t.ID.String()).String(),
+			},
+		})
+	}
+
 	keyboard = append(keyboard, [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Добавить item",
-				CallbackData: fmt.Sprint(getCreateItemInfoHandler, productID),
+				Text:         lang.T(locale.AddItemButton),
+				CallbackData: getCreateItemInfoHandler.Data().ID(productID.String()).String(),
+			},
+			{
+				Text:         lang.T(locale.AddTariffButton),
+				CallbackData: getCreateTariffInfoHandler.Data().ID(productID.String()).String(),
 			},
 		},
 		{
 			{
-				Text:         "Удалить продукт",
-				CallbackData: fmt.Sprint(deleteProductHandler, productID),
+				Text:         lang.T(locale.PublicationButton),
+				CallbackData: getPublishInfoHandler.Data().ID(productID.String()).String(),
 			},
 			{
-				Text:         "Назад",
+				Text:         lang.T(locale.StockButton),
+				CallbackData: getStockInfoHandler.Data().ID(productID.String()).String(),
+			},
+		},
+		{
+			{
+				Text:         lang.T(locale.AccessChatButton),
+				CallbackData: getAccessChatInfoHandler.Data().ID(productID.String()).String(),
+			},
+			{
+				Text:         lang.T(locale.TranslationsButton),
+				CallbackData: getTranslationInfoHandler.Data().ID(productID.String()).String(),
+			},
+		},
+		{
+			{
+				Text:         lang.T(locale.DeleteProductButton),
+				CallbackData: getDeactivateInfoHandler.Data().ID(productID.String()).String(),
+			},
+			{
+				Text:         lang.T(locale.BackButton),
 				CallbackData: getProductForEditHandler.GetBackHandler().String(),
 			},
 		},
@@ -200,14 +291,17 @@ func (i *Implementation) GetProductForEdit(ctx context.Context, bot *telegramBot
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text: fmt.Sprintf("ID: %s\nИзображение: %s\nТип: %s\nТовар: %s\nОписание: %s\n\n Цена: %s %s",
+		Text: lang.T(locale.ProductEditText,
 			product.ID,
 			product.Image.URL,
 			product.Type.String(),
 			product.Name,
 			product.Description,
-			product.Price.Value,
-			currencyToIcon(product.Price.Currency)),
+			formatPublication(lang, product),
+			formatStock(lang, product),
+			formatAccessChat(lang, product.AccessChatID),
+			formatTariffs(lang, product.Tariffs),
+			formatTranslations(lang, product.Translations)),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -216,93 +310,65 @@ func (i *Implementation) GetProductForEdit(ctx context.Context, bot *telegramBot
 	sender.sendInlineMsg(ctx, bot)
 }
 
-func (i *Implementation) GetCreateProductInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	str := make([]string, 0)
-	msg := productFormParser.Format(str)
-
-	sender := msgInlineSender{
-		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
-		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text:         "/" + createProductHandler.String() + "\n" + strings.Join(msg, ",\n"),
-		Keyboard: &models.InlineKeyboardMarkup{
-			InlineKeyboard: [][]models.InlineKeyboardButton{
-				{
-					{
-						Text:         "Назад",
-						CallbackData: getCreateProductInfoHandler.GetBackHandler().String(),
-					},
-				},
-			},
-		},
-	}
-
-	sender.sendInlineMsg(ctx, bot)
+// productForm форма создания продукта с первым тарифом
+var productForm = &dialog.Form{
+	Name:  "create_product",
+	Title: string(locale.ProductFormTitle),
+	Steps: []dialog.Step{
+		{Field: "name", Title: string(locale.NameField)},
+		{Field: "desc", Title: string(locale.DescriptionField)},
+		{Field: "image", Title: string(locale.ImageField), Hint: string(locale.ImageHint), Optional: true},
+		{Field: "tariff", Title: string(locale.TariffField), Hint: string(locale.TariffHint)},
+		{Field: "price", Title: string(locale.PriceField), Hint: string(locale.PriceHint), Validate: validatePrice},
+		{Field: "currency", Title: string(locale.CurrencyField), Hint: string(locale.CurrencyHint),
+			Validate: validateCurrency},
+		{Field: "subscription_period", Title: string(locale.PeriodField), Hint: string(locale.PeriodHint),
+			Validate: validatePeriod},
+		{Field: "type", Title: string(locale.ProductTypeField), Hint: string(locale.ProductTypeHint),
+			Validate: validateProductType},
+	},
 }
 
-func (i *Implementation) CreateProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	oldMsg := update.Message
-
-	inputMessage := strings.TrimPrefix(update.Message.Text, "/"+createProductHandler.String())
-	inputMessage = strings.TrimSpace(inputMessage)
-	var p = &product{}
-	msg, err := productFormParser.Execute(inputMessage, p)
-	if err != nil {
-		sendErrorMsg(ctx, bot, oldMsg, err, "")
-		return
-	}
-	if msg != nil {
-		_, err := bot.SendMessage(ctx, &telegramBot.SendMessageParams{
-			Text: "Заполните поле: " + msg.Field + " и повторите снова",
-			ReplyMarkup: &models.InlineKeyboardMarkup{
-				InlineKeyboard: [][]models.InlineKeyboardButton{
-					{
-						{
-							Text:         "Отмена",
-							CallbackData: backHandler.String() + menu.String(),
-						},
-					},
-				},
-			},
-		})
-		if err != nil {
-			return
-		}
-	}
+func (i *Implementation) GetCreateProductInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, productForm, nil)
+}
 
-	d, err := decimal.NewFromString(p.Price)
+// createProduct создает продукт по заполненной форме
+func (i *Implementation) createProduct(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	t, err := parseTariff(tariff{
+		Name:               s.Value("tariff"),
+		Currency:           s.Value("currency"),
+		Price:              s.Value("price"),
+		SubscriptionPeriod: s.Value("subscription_period"),
+	})
 	if err != nil {
-		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		sendErrorMsg(ctx, bot, msg, err, "")
 		return
 	}
 
 	err = i.productsUseCase.CreateProduct(ctx, domainProducts.Product{
-		Type:        domainProducts.TypeFromString(p.Type),
-		Name:        p.Name,
-		Description: p.Description,
+		Type:        domainProducts.TypeFromString(s.Value("type")),
+		Name:        s.Value("name"),
+		Description: s.Value("desc"),
 		Image: domainProducts.Image{
-			URL: p.Image,
+			URL: s.Value("image"),
 		},
-		Price: price.Price{
-			Currency: price.CurrencyFromString(p.Currency),
-			Value:    d,
-		},
-		SubscriptionPeriod: 0,
+		Tariffs: domainProducts.Tariffs{t},
 	})
 	if err != nil {
-		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		sendErrorMsg(ctx, bot, msg, err, "")
 		return
 	}
 
 	sender := msgInlineSender{
-		ChatID:       oldMsg.Chat.ID,
-		CurMessageID: oldMsg.ID,
-		Text:         "Продукт успешно создан",
+		ChatID: msg.Chat.ID,
+		Text:   getLangFromContext(ctx).T(locale.ProductCreated),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: [][]models.InlineKeyboardButton{
 				{
 					{
-						Text:         "Назад",
-						CallbackData: createProductHandler.GetBackHandler().String(),
+						Text:         getLangFromContext(ctx).T(locale.BackButton),
+						CallbackData: getCreateProductInfoHandler.GetBackHandler().String(),
 					},
 				},
 			},
@@ -312,160 +378,306 @@ func (i *Implementation) CreateProduct(ctx context.Context, bot *telegramBot.Bot
 	sender.sendInlineMsg(ctx, bot)
 }
 
-var productFormParser = newProductParser()
+// ошибки проверки ответов в формах, текст ошибки выводится из каталога
+var (
+	errInvalidPrice       = invalidValue(locale.InvalidPrice)
+	errInvalidCurrency    = invalidValue(locale.InvalidCurrency)
+	errInvalidPeriod      = invalidValue(locale.InvalidPeriod)
+	errInvalidProductType = invalidValue(locale.InvalidProductType)
+	errInvalidTime        = invalidValue(locale.InvalidTime)
+	errInvalidStockMode   = invalidValue(locale.InvalidStockMode)
+	errInvalidCount       = invalidValue(locale.InvalidCount)
+	errInvalidChatID      = invalidValue(locale.InvalidChatID)
+	errInvalidLanguage    = invalidValue(locale.InvalidLanguage)
+	errInvalidTelegramID  = invalidValue(locale.InvalidTelegramID)
+	errInvalidRole        = invalidValue(locale.InvalidRole)
+	errInvalidAction      = invalidValue(locale.InvalidAction)
+)
+
+// validatePrice проверяет стоимость в форме
+func validatePrice(v string) error {
+	d, err := decimal.NewFromString(v)
+	if err != nil || d.IsNegative() {
+		return errInvalidPrice
+	}
 
-func newProductParser() form_parser.FormParser {
-	defaultPlaceholder := "<значение>"
-	stg := form_parser.NewStage("Название", "name", defaultPlaceholder)
-	stg.SetNext(form_parser.NewStage("Описание", "desc", defaultPlaceholder)).
-		SetNext(form_parser.NewStage("Изображение", "image", defaultPlaceholder)).
-		SetNext(form_parser.NewStage("Стоимость", "price", defaultPlaceholder)).
-		SetNext(form_parser.NewStage("Валюта", "currency", defaultPlaceholder)).
-		SetNext(form_parser.NewStage("Период подписки", "subscription_period", defaultPlaceholder)).
-		SetNext(form_parser.NewStage("Тип продукта", "type", defaultPlaceholder))
-	return stg
+	return nil
 }
 
-type product struct {
-	Name               string `field:"name"`
-	Type               string `field:"type"`
-	Description        string `field:"desc"`
-	Image              string `field:"image"`
-	Currency           string `field:"currency"`
-	Price              string `field:"price"`
-	SubscriptionPeriod string `field:"subscription_period"`
+// validateCurrency проверяет валюту в форме
+func validateCurrency(v string) error {
+	if price.CurrencyFromString(v) == price.UNKNOWN {
+		return errInvalidCurrency
+	}
+
+	return nil
 }
 
-var productItemParser = newItemParser()
+// validatePeriod проверяет период подписки в форме
+func validatePeriod(v string) error {
+	if _, err := domainProducts.ParsePeriod(v); err != nil {
+		return errInvalidPeriod
+	}
+
+	return nil
+}
 
-func newItemParser() form_parser.FormParser {
-	defaultPlaceholder := "<значение>"
-	stg := form_parser.NewStage("ID продукта", "id", defaultPlaceholder, true)
-	stg.SetNext(form_parser.NewStage("Полезная нагрузка", "payload", defaultPlaceholder))
+// validateProductType проверяет тип продукта в форме
+func validateProductType(v string) error {
+	if domainProducts.TypeFromString(v) == domainProducts.UnspecifiedType {
+		return errInvalidProductType
+	}
 
-	return stg
+	return nil
 }
 
-type item struct {
-	ProductID string `field:"id"`
-	Payload   string `field:"payload"`
+// validateTime проверяет время в форме
+func validateTime(v string) error {
+	if _, err := parseFormTime(v); err != nil {
+		return errInvalidTime
+	}
+
+	return nil
 }
 
-func (i *Implementation) DeleteProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	oldMsg := update.CallbackQuery.Message.Message
+// validateStockMode проверяет режим склада в форме
+func validateStockMode(v string) error {
+	if _, err := domainProducts.ParseStockMode(v); err != nil {
+		return errInvalidStockMode
+	}
 
-	productIDStr := strings.TrimPrefix(update.CallbackQuery.Data, deleteProductHandler.String())
-	productID := domainProducts.NewID(productIDStr)
+	return nil
+}
 
-	err := i.productsUseCase.DeactivateProduct(ctx, productID)
-	if err != nil {
-		sendErrorMsg(ctx, bot, oldMsg, err, "")
-		return
+// validateCount проверяет кол-во в форме, например дней или продаж
+func validateCount(v string) error {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return errInvalidCount
 	}
 
-	buttons := [][]models.InlineKeyboardButton{
-		{
-			{
-				Text:         "Назад",
-				CallbackData: deleteProductHandler.GetBackHandler().String(),
-			},
-		},
+	return nil
+}
+
+// validateChatID проверяет ID чата в форме
+func validateChatID(v string) error {
+	if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+		return errInvalidChatID
 	}
 
-	_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
-		ChatID: update.CallbackQuery.Message.Message.Chat.ID,
-		Text: fmt.Sprintf("Продукт деактивирован\nID: %s",
-			productID.ID),
-		ReplyMarkup: &models.InlineKeyboardMarkup{
-			InlineKeyboard: buttons,
-		},
-	})
-	if err != nil {
-		return
+	return nil
+}
+
+// validateLanguage проверяет код языка в форме
+func validateLanguage(v string) error {
+	if _, ok := locale.Parse(v); !ok {
+		return errInvalidLanguage
 	}
+
+	return nil
 }
 
-func (i *Implementation) GetCreateItemInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	strID := strings.TrimPrefix(update.CallbackQuery.Data, getCreateItemInfoHandler.String())
-	if strID == "" {
-		return
+// validateTelegramID проверяет telegram ID пользователя в форме
+func validateTelegramID(v string) error {
+	if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+		return errInvalidTelegramID
 	}
 
-	_, err := bot.DeleteMessage(ctx, &telegramBot.DeleteMessageParams{
-		ChatID:    update.CallbackQuery.Message.Message.Chat.ID,
-		MessageID: update.CallbackQuery.Message.Message.ID,
-	})
+	return nil
+}
 
-	str := make([]string, 0)
-	msg := productItemParser.Format(str)
+// validateRole проверяет роль сотрудника в форме
+func validateRole(v string) error {
+	if _, err := domainUser.RoleFromString(v); err != nil {
+		return errInvalidRole
+	}
 
-	_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
-		ChatID: update.CallbackQuery.Message.Message.Chat.ID,
-		Text:   fmt.Sprintf("/%s\nID продукта: %s\n%s", createItemHandler.String(), strID, strings.Join(msg, ",\n")),
-		ReplyMarkup: &models.InlineKeyboardMarkup{
-			InlineKeyboard: [][]models.InlineKeyboardButton{
-				{
-					{
-						Text:         "Назад",
-						CallbackData: getCreateItemInfoHandler.GetBackHandler().String() + strID,
-					},
-				},
-			},
-		},
-	})
-	if err != nil {
-		return
+	return nil
+}
+
+// validateAction проверяет действие администратора с подпиской в форме
+func validateAction(v string) error {
+	if domainSubscription.AdminActionFromString(v) == domainSubscription.UnknownAction {
+		return errInvalidAction
 	}
+
+	return nil
 }
 
-func (i *Implementation) AddItem(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	oldMsg := update.Message
-	inputMessage := strings.TrimPrefix(update.Message.Text, "/"+createItemHandler.String())
-	inputMessage = strings.TrimSpace(inputMessage)
-	var p = &item{}
-	msg, err := productItemParser.Execute(inputMessage, p)
+// параметры запуска форм
+const (
+	// productParam ID продукта
+	productParam = "product_id"
+	// tariffParam ID тарифа
+	tariffParam = "tariff_id"
+	// subscriptionParam ID подписки
+	subscriptionParam = "subscription_id"
+)
+
+// tariffForm форма создания тарифа продукта
+var tariffForm = &dialog.Form{
+	Name:  "add_tariff",
+	Title: string(locale.TariffFormTitle),
+	Steps: []dialog.Step{
+		{Field: "name", Title: string(locale.NameField)},
+		{Field: "price", Title: string(locale.PriceField), Hint: string(locale.PriceHint), Validate: validatePrice},
+		{Field: "currency", Title: string(locale.CurrencyField), Hint: string(locale.CurrencyHint),
+			Validate: validateCurrency},
+		{Field: "subscription_period", Title: string(locale.PeriodField), Hint: string(locale.PeriodHint),
+			Validate: validatePeriod},
+	},
+}
+
+type tariff struct {
+	ProductID          string
+	Name               string
+	Currency           string
+	Price              string
+	SubscriptionPeriod string
+}
+
+// parseTariff преобразует данные формы в тариф
+func parseTariff(t tariff) (domainProducts.Tariff, error) {
+	d, err := decimal.NewFromString(t.Price)
 	if err != nil {
-		return
+		return domainProducts.Tariff{}, err
 	}
 
-	if msg != nil {
-		_, err := bot.SendMessage(ctx, &telegramBot.SendMessageParams{
-			Text: "Заполните поле: " + msg.Field + " и повторите снова",
-			ReplyMarkup: &models.InlineKeyboardMarkup{
-				InlineKeyboard: [][]models.InlineKeyboardButton{
-					{
-						{
-							Text:         "Отмена",
-							CallbackData: createItemHandler.GetBackHandler().String(),
-						},
-					},
-				},
-			},
-		})
-		if err != nil {
-			return
-		}
+	period, err := domainProducts.ParsePeriod(t.SubscriptionPeriod)
+	if err != nil {
+		return domainProducts.Tariff{}, err
 	}
 
-	err = i.productsUseCase.AddItem(ctx, domainProducts.Item{
-		ProductID: domainProducts.NewID(p.ProductID),
-		Payload:   p.Payload,
+	return domainProducts.Tariff{
+		ProductID: domainProducts.NewID(t.ProductID),
+		Name:      t.Name,
+		Price: price.Price{
+			Currency: price.CurrencyFromString(t.Currency),
+			Value:    d,
+		},
+		SubscriptionPeriod: period,
+	}, nil
+}
+
+// parseFormTime разбирает время из формы, пропущенный ответ означает отсутствие ограничения
+func parseFormTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.ParseInLocation(consts.DateTimeLayout, s, time.UTC)
+}
+
+// publicationForm форма окна публикации продукта, пропущенная граница не ограничивает публикацию
+var publicationForm = &dialog.Form{
+	Name:  "set_publication",
+	Title: string(locale.PublicationFormTitle),
+	Steps: []dialog.Step{
+		{Field: "from", Title: string(locale.PublishFromField), Hint: string(locale.TimeHint), Optional: true,
+			Validate: validateTime},
+		{Field: "until", Title: string(locale.PublishUntilField), Hint: string(locale.TimeHint), Optional: true,
+			Validate: validateTime},
+	},
+}
+
+// stockForm форма режима склада продукта, лимит продаж нужен только для capped
+var stockForm = &dialog.Form{
+	Name:  "set_stock",
+	Title: string(locale.StockFormTitle),
+	Steps: []dialog.Step{
+		{Field: "mode", Title: string(locale.StockModeField), Hint: string(locale.StockModeHint),
+			Validate: validateStockMode},
+		{Field: "limit", Title: string(locale.SalesLimitField), Hint: string(locale.SalesLimitHint), Optional: true,
+			Validate: validateCount},
+	},
+}
+
+// accessChatForm форма канала продукта, пропущенный ответ отключает канал
+var accessChatForm = &dialog.Form{
+	Name:  "set_access_chat",
+	Title: string(locale.AccessChatFormTitle),
+	Steps: []dialog.Step{
+		{Field: "chat", Title: string(locale.ChatIDField), Hint: string(locale.ChatIDHint), Optional: true,
+			Validate: validateChatID},
+	},
+}
+
+// saleForm форма распродажи тарифа
+var saleForm = &dialog.Form{
+	Name:  "add_sale",
+	Title: string(locale.SaleFormTitle),
+	Steps: []dialog.Step{
+		{Field: "price", Title: string(locale.SalePriceField), Hint: string(locale.SalePriceHint),
+			Validate: validatePrice},
+		{Field: "starts_at", Title: string(locale.SaleStartField), Hint: string(locale.TimeHint),
+			Validate: validateTime},
+		{Field: "ends_at", Title: string(locale.SaleEndField), Hint: string(locale.TimeHint),
+			Validate: validateTime},
+	},
+}
+
+// itemForm форма добавления единицы товара, полезная нагрузка может содержать запятые и переносы строк
+var itemForm = &dialog.Form{
+	Name:  "add_item",
+	Title: string(locale.ItemFormTitle),
+	Steps: []dialog.Step{
+		{Field: "payload", Title: string(locale.PayloadField), Hint: string(locale.PayloadHint)},
+	},
+}
+
+func (i *Implementation) GetCreateItemInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, itemForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// addItem добавляет единицу товара по заполненной форме
+func (i *Implementation) addItem(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	productID := domainProducts.NewID(s.Param(productParam))
+	err := i.productsUseCase.AddItem(ctx, domainProducts.Item{
+		ProductID: productID,
+		Payload:   s.Value("payload"),
 	})
 	if err != nil {
-		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		sendErrorMsg(ctx, bot, msg, err, "")
 		return
 	}
 
+	text := lang.T(locale.ItemCreated)
+	if product, err := i.productsUseCase.GetProduct(ctx, productID); err == nil && product.StockMode.IsShared() {
+		text = lang.T(locale.SharedPayloadUpdated)
+	}
+
+	sendToProduct(ctx, bot, msg, productID, text)
+}
+
+// productCancelData возвращает к продукту, для которого заполнялась форма
+func productCancelData(s dialog.Session) string {
+	return getProductForEditHandler.Data().ID(s.Param(productParam)).String()
+}
+
+// sendToProduct отправляет результат формы продукта с кнопкой возврата к продукту
+func sendToProduct(ctx context.Context,
+	bot *telegramBot.Bot,
+	msg *models.Message,
+	productID domainProducts.ID,
+	text string,
+) {
 	sender := msgInlineSender{
-		ChatID:       oldMsg.Chat.ID,
-		CurMessageID: oldMsg.ID,
-		Text:         "Единица товара успешно создана",
+		ChatID: msg.Chat.ID,
+		Text:   text,
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: [][]models.InlineKeyboardButton{
 				{
 					{
-						Text:         "Администрирование",
-						CallbackData: adminHandler.String(),
+						Text:         getLangFromContext(ctx).T(locale.ToProductButton),
+						CallbackData: getProductForEditHandler.Data().ID(productID.String()).String(),
 					},
 				},
 			},
@@ -477,8 +689,12 @@ func (i *Implementation) AddItem(ctx context.Context, bot *telegramBot.Bot, upda
 
 func (i *Implementation) DeleteItem(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 
-	itemIDStr := strings.TrimPrefix(update.CallbackQuery.Data, deleteItemHandler.String())
+	itemIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
 	itemID := domainProducts.NewItemID(itemIDStr)
 
 	curItem, err := i.productsUseCase.GetItem(ctx, itemID)
@@ -496,8 +712,8 @@ func (i *Implementation) DeleteItem(ctx context.Context, bot *telegramBot.Bot, u
 	buttons := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Назад",
-				CallbackData: deleteItemHandler.GetBackHandler().GetBackHandler().String() + curItem.ProductID.String(),
+				Text:         lang.T(locale.BackButton),
+				CallbackData: deleteItemHandler.GetBackHandler().GetBackHandler().Data().ID(curItem.ProductID.String()).String(),
 			},
 		},
 	}
@@ -505,9 +721,9 @@ func (i *Implementation) DeleteItem(ctx context.Context, bot *telegramBot.Bot, u
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text: fmt.Sprintf("Удален\nID: %s\nПолезная нагрузка: %s\n",
+		Text: lang.T(locale.ItemDeleted,
 			curItem.ID,
-			curItem.Payload),
+			curItem.MaskedPayload()),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: buttons,
 		},
@@ -518,8 +734,12 @@ func (i *Implementation) DeleteItem(ctx context.Context, bot *telegramBot.Bot, u
 
 func (i *Implementation) GetItem(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 
-	itemIDStr := strings.TrimPrefix(update.CallbackQuery.Data, getItemHandler.String())
+	itemIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
 	itemID := domainProducts.NewItemID(itemIDStr)
 
 	curItem, err := i.productsUseCase.GetItem(ctx, itemID)
@@ -531,12 +751,12 @@ func (i *Implementation) GetItem(ctx context.Context, bot *telegramBot.Bot, upda
 	buttons := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Удалить item",
-				CallbackData: fmt.Sprint(deleteItemHandler, curItem.ID),
+				Text:         lang.T(locale.DeleteItemButton),
+				CallbackData: deleteItemHandler.Data().ID(curItem.ID.String()).ExpiresIn(confirmTTL).String(),
 			},
 			{
-				Text:         "Назад",
-				CallbackData: getItemHandler.GetBackHandler().String() + curItem.ProductID.String(),
+				Text:         lang.T(locale.BackButton),
+				CallbackData: getItemHandler.GetBackHandler().Data().ID(curItem.ProductID.String()).String(),
 			},
 		},
 	}
@@ -544,9 +764,9 @@ func (i *Implementation) GetItem(ctx context.Context, bot *telegramBot.Bot, upda
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text: fmt.Sprintf("ID: %s\nПолезная нагрузка: %s\n",
+		Text: lang.T(locale.ItemText,
 			curItem.ID,
-			curItem.Payload),
+			curItem.MaskedPayload()),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: buttons,
 		},
@@ -554,3 +774,230 @@ func (i *Implementation) GetItem(ctx context.Context, bot *telegramBot.Bot, upda
 
 	sender.sendInlineMsg(ctx, bot)
 }
+
+func (i *Implementation) GetCreateTariffInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, tariffForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// addTariff создает тариф продукта по заполненной форме
+func (i *Implementation) addTariff(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	t, err := parseTariff(tariff{
+		ProductID:          s.Param(productParam),
+		Name:               s.Value("name"),
+		Currency:           s.Value("currency"),
+		Price:              s.Value("price"),
+		SubscriptionPeriod: s.Value("subscription_period"),
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	err = i.productsUseCase.AddTariff(ctx, t)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, t.ProductID, getLangFromContext(ctx).T(locale.TariffCreated))
+}
+
+func (i *Implementation) DeleteTariff(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	tariffIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+	tariffID := domainProducts.NewTariffID(tariffIDStr)
+
+	t, err := i.productsUseCase.GetTariff(ctx, tariffID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	err = i.productsUseCase.DeleteTariff(ctx, tariffID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.TariffDeleted, t.Name),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: deleteTariffHandler.GetBackHandler().Data().ID(t.ProductID.String()).String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetPublishInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, publicationForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// setPublication задает окно публикации продукта по заполненной форме
+func (i *Implementation) setPublication(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+
+	// ответы проверены при заполнении формы
+	from, _ := parseFormTime(s.Value("from"))
+	until, _ := parseFormTime(s.Value("until"))
+	pub, err := domainProducts.NewPublication(from, until)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, lang.T(locale.InvalidPublicationRange))
+		return
+	}
+
+	productID := domainProducts.NewID(s.Param(productParam))
+	err = i.productsUseCase.SetPublication(ctx, productID, pub)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, productID, lang.T(locale.PublicationUpdated))
+}
+
+func (i *Implementation) GetCreateSaleInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	t, err := i.productsUseCase.GetTariff(ctx, domainProducts.NewTariffID(strID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, saleForm, map[string]string{
+		tariffParam:  strID,
+		productParam: t.ProductID.String(),
+	})
+}
+
+// addSale планирует распродажу тарифа по заполненной форме
+func (i *Implementation) addSale(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	d, err := decimal.NewFromString(s.Value("price"))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	startsAt, _ := parseFormTime(s.Value("starts_at"))
+	endsAt, _ := parseFormTime(s.Value("ends_at"))
+	err = i.productsUseCase.AddSale(ctx, domainProducts.Sale{
+		TariffID: domainProducts.NewTariffID(s.Param(tariffParam)),
+		Price: price.Price{
+			Value: d,
+		},
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, domainProducts.NewID(s.Param(productParam)),
+		getLangFromContext(ctx).T(locale.SaleScheduled,
+			startsAt.Format(consts.DateTimeLayout), endsAt.Format(consts.DateTimeLayout)))
+}
+
+func (i *Implementation) GetStockInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, stockForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// setStockMode задает режим склада продукта по заполненной форме
+func (i *Implementation) setStockMode(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	mode, err := domainProducts.ParseStockMode(s.Value("mode"))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	var limit int64
+	if v := s.Value("limit"); v != "" {
+		limit, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			sendErrorMsg(ctx, bot, msg, err, "")
+			return
+		}
+	}
+
+	productID := domainProducts.NewID(s.Param(productParam))
+	err = i.productsUseCase.SetStockMode(ctx, productID, mode, limit)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, productID, getLangFromContext(ctx).T(locale.StockUpdated))
+}
+
+func (i *Implementation) GetAccessChatInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, accessChatForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// setAccessChat задает канал продукта по заполненной форме
+func (i *Implementation) setAccessChat(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	var chatID int64
+	if v := s.Value("chat"); v != "" {
+		var err error
+		chatID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			sendErrorMsg(ctx, bot, msg, err, "")
+			return
+		}
+	}
+
+	productID := domainProducts.NewID(s.Param(productParam))
+	err := i.productsUseCase.SetAccessChat(ctx, productID, chatID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, productID, getLangFromContext(ctx).T(locale.AccessChatUpdated))
+}
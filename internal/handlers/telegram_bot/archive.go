@@ -0,0 +1,323 @@
+package telegram_bot
+
+import (
+	"context"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+)
+
+func (i *Implementation) GetDeactivateInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	lang := getLangFromContext(ctx)
+	sender := msgInlineSender{
+		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
+		CurMessageID: update.CallbackQuery.Message.Message.ID,
+		Text:         lang.T(locale.DeactivateText),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.KeepUnsoldButton),
+						CallbackData: deleteProductHandler.Data().ID(strID).Str(domainProducts.KeepUnsold.String()).ExpiresIn(confirmTTL).String(),
+					},
+					{
+						Text:         lang.T(locale.WithdrawUnsoldButton),
+						CallbackData: deleteProductHandler.Data().ID(strID).Str(domainProducts.WithdrawUnsold.String()).ExpiresIn(confirmTTL).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.MoveUnsoldButton),
+						CallbackData: deactivateMoveHandler.Data().ID(strID).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: getDeactivateInfoHandler.GetBackHandler().Data().ID(strID).String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) DeleteProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	productIDStr, err := data.ID(0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	action, err := data.Str(1)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	productID := domainProducts.NewID(productIDStr)
+	err = i.productsUseCase.DeactivateProduct(ctx, domainProducts.Deactivation{
+		ProductID: productID,
+		Unsold:    domainProducts.UnsoldActionFromString(action),
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.ProductArchived, productID),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: deleteProductHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// deactivateMoveForm форма переноса непроданных единиц товара в другой продукт при снятии продукта с продажи
+var deactivateMoveForm = &dialog.Form{
+	Name:  "deactivate_move",
+	Title: string(locale.DeactivateMoveFormTitle),
+	Steps: []dialog.Step{
+		{Field: "target", Title: string(locale.TargetProductField), Hint: string(locale.TargetProductHint)},
+	},
+}
+
+func (i *Implementation) GetDeactivateMoveInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, deactivateMoveForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// deactivateWithMove переносит продукт в архив, а непроданные единицы товара - в продукт из формы
+func (i *Implementation) deactivateWithMove(ctx context.Context,
+	bot *telegramBot.Bot,
+	msg *models.Message,
+	s dialog.Session,
+) {
+	lang := getLangFromContext(ctx)
+	productID := domainProducts.NewID(s.Param(productParam))
+	targetID := domainProducts.NewID(s.Value("target"))
+	err := i.productsUseCase.DeactivateProduct(ctx, domainProducts.Deactivation{
+		ProductID:       productID,
+		Unsold:          domainProducts.MoveUnsold,
+		TargetProductID: targetID,
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID: msg.Chat.ID,
+		Text:   lang.T(locale.ProductArchived, productID) + lang.T(locale.UnsoldMoved, targetID),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ToProductsButton),
+						CallbackData: deactivateMoveHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetArchive(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	cardsNum := maxProductsLines * maxProductsColumns
+	products, err := i.productsUseCase.GetProducts(ctx, domainProducts.RequestList{
+		Pagination: &primitives.Pagination{
+			Num:    uint64(cardsNum),
+			Offset: offset * uint64(cardsNum),
+		},
+		Filters: &domainProducts.Filters{
+			Archived: true,
+		},
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	pag := make([]*paginatorItem, 0, len(products.Products))
+	for _, curProduct := range products.Products {
+		pag = append(pag, &paginatorItem{
+			id:   curProduct.ID.String(),
+			name: curProduct.Name,
+		})
+	}
+
+	paginator := paginatorHandlerList{
+		nextHandler:        getArchivedProductHandler,
+		curHandler:         getArchiveHandler,
+		maxProductsColumns: maxProductsColumns,
+		total:              products.Total,
+	}
+
+	keyboard := paginator.paginationKeyboard(pag, primitives.Pagination{
+		Num:    uint64(cardsNum),
+		Offset: offset,
+	})
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
+		{
+			Text:         lang.T(locale.BackButton),
+			CallbackData: getArchiveHandler.GetBackHandler().String(),
+		},
+	})
+
+	text := lang.T(locale.ArchiveText)
+	if products.Total == 0 && offset == 0 {
+		text = lang.T(locale.ArchiveEmpty)
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetArchivedProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	productIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	product, err := i.productsUseCase.GetProduct(ctx, domainProducts.NewID(productIDStr))
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text: lang.T(locale.ArchivedProductText,
+			product.ID,
+			product.Name,
+			product.Description,
+			formatStock(lang, product),
+			product.Stock.Withdrawn,
+			formatTariffs(lang, product.Tariffs)),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.RestoreButton),
+						CallbackData: restoreProductHandler.Data().ID(product.ID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: getArchivedProductHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) RestoreProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	productIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+	productID := domainProducts.NewID(productIDStr)
+
+	err := i.productsUseCase.RestoreProduct(ctx, productID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.ProductRestored),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ToProductButton),
+						CallbackData: getProductForEditHandler.Data().ID(productID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: restoreProductHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
@@ -0,0 +1,164 @@
+package telegram_bot
+
+import (
+	"context"
+	"strconv"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// banForm форма блокировки пользователя, без окончания блокировка бессрочная
+var banForm = &dialog.Form{
+	Name:  "ban_user",
+	Title: string(locale.BanFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+		{Field: "reason", Title: string(locale.ReasonField)},
+		{Field: "until", Title: string(locale.BanUntilField), Hint: string(locale.BanUntilHint), Optional: true,
+			Validate: validateTime},
+	},
+}
+
+// unbanForm форма разблокировки пользователя
+var unbanForm = &dialog.Form{
+	Name:  "unban_user",
+	Title: string(locale.UnbanFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+	},
+}
+
+func (i *Implementation) GetBanInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	lang := getLangFromContext(ctx)
+	sender := msgInlineSender{
+		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
+		CurMessageID: update.CallbackQuery.Message.Message.ID,
+		Text:         lang.T(locale.BanInfoText),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BanButton),
+						CallbackData: banUserHandler.String(),
+					},
+					{
+						Text:         lang.T(locale.UnbanButton),
+						CallbackData: unbanUserHandler.String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: banInfoHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetBanUserInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, banForm, nil)
+}
+
+func (i *Implementation) GetUnbanUserInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, unbanForm, nil)
+}
+
+// banUser блокирует пользователя по заполненной форме и отменяет его неоплаченные заказы
+func (i *Implementation) banUser(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	tgID, err := strconv.ParseInt(s.Value("tg"), 10, 64)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, lang.T(locale.InvalidTelegramID))
+		return
+	}
+
+	until, err := parseFormTime(s.Value("until"))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, lang.T(locale.InvalidTime))
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	user, err := i.userUseCase.BanUser(ctx, domainUser.BanRequest{
+		TelegramID: domainUser.NewTelegramID(tgID),
+		Reason:     s.Value("reason"),
+		Until:      until,
+		AdminID:    adminID,
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	text := lang.T(locale.UserBanned, tgID, formatBanUntil(lang, user.Ban))
+	cancelled, err := i.orderUseCase.CancelUserOrders(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "error cancel blocked user orders: %v", err)
+		text += lang.T(locale.UserOrdersCancelFailed)
+	} else if cancelled != 0 {
+		text += lang.T(locale.UserOrdersCancelled, cancelled)
+	}
+
+	sendToAdminMenu(ctx, bot, msg, text)
+}
+
+// unbanUser разблокирует пользователя по заполненной форме
+func (i *Implementation) unbanUser(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	tgID, err := strconv.ParseInt(s.Value("tg"), 10, 64)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, lang.T(locale.InvalidTelegramID))
+		return
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	_, err = i.userUseCase.UnbanUser(ctx, adminID, domainUser.NewTelegramID(tgID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToAdminMenu(ctx, bot, msg, lang.T(locale.UserUnbanned, tgID))
+}
+
+// sendToAdminMenu отправляет результат формы с кнопкой возврата в панель администрирования
+func sendToAdminMenu(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, text string) {
+	sender := msgInlineSender{
+		ChatID: msg.Chat.ID,
+		Text:   text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         getLangFromContext(ctx).T(locale.MenuAdmin),
+						CallbackData: adminHandler.String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
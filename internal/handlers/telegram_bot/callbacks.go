@@ -0,0 +1,78 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/pkg/callback"
+)
+
+// confirmTTL срок действия кнопок необратимых действий: удаления, повторной отправки и т.п.
+const confirmTTL = time.Hour
+
+// Data начинает данные кнопки, адресованной обработчику
+func (h handlerName) Data() *callback.Builder {
+	return callback.New(h.String())
+}
+
+// registerCallback регистрирует обработчик кнопок. Обработчик выбирается по точному совпадению действия,
+// поэтому названия обработчиков могут быть префиксами друг друга
+func registerCallback(bot *telegramBot.Bot, h handlerName, f telegramBot.HandlerFunc) {
+	bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		return update.CallbackQuery != nil && callback.ActionOf(update.CallbackQuery.Data) == h.String()
+	}, f)
+}
+
+// parseCallback разбирает данные нажатой кнопки. При ошибке пользователю отправляется сообщение и ok=false
+func parseCallback(ctx context.Context, bot *telegramBot.Bot, update *models.Update) (callback.Data, bool) {
+	data, err := callback.Parse(update.CallbackQuery.Data)
+	if err != nil {
+		var text string
+		if errors.Is(err, callback.ErrExpired) {
+			text = getLangFromContext(ctx).T(locale.ButtonExpired)
+		}
+		sendErrorMsg(ctx, bot, update.CallbackQuery.Message.Message, err, text)
+		return callback.Data{}, false
+	}
+
+	return data, true
+}
+
+// callbackID возвращает идентификатор из первого аргумента нажатой кнопки.
+// При ошибке пользователю отправляется сообщение и ok=false
+func callbackID(ctx context.Context, bot *telegramBot.Bot, update *models.Update) (string, bool) {
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return "", false
+	}
+
+	id, err := data.ID(0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, update.CallbackQuery.Message.Message, err, "")
+		return "", false
+	}
+
+	return id, true
+}
+
+// callbackPage возвращает номер страницы списка из аргумента i, без аргумента - первая страница
+func callbackPage(data callback.Data, i int) (uint64, error) {
+	if data.Len() <= i {
+		return 0, nil
+	}
+
+	page, err := data.Int(i)
+	if err != nil {
+		return 0, err
+	}
+	if page < 0 {
+		return 0, callback.ErrInvalidData
+	}
+
+	return uint64(page), nil
+}
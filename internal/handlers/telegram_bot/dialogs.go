@@ -0,0 +1,229 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// dialogTTL время, после которого незаполненная форма без ответов отменяется
+const dialogTTL = 30 * time.Minute
+
+// invalidValue ошибка проверки ответа на шаг формы, значение - ключ текста ошибки в каталоге
+type invalidValue locale.Key
+
+func (e invalidValue) Error() string {
+	return string(e)
+}
+
+// invalidValueKey возвращает ключ текста ошибки проверки ответа
+func invalidValueKey(err error) locale.Key {
+	var invalid invalidValue
+	switch {
+	case errors.Is(err, dialog.ErrEmptyValue):
+		return locale.DialogEmptyValue
+	case errors.As(err, &invalid):
+		return locale.Key(invalid)
+	}
+
+	return locale.DialogInvalidFormat
+}
+
+// dialogFlow форма, заполняемая по шагам, и действие по ее результату
+type dialogFlow struct {
+	form *dialog.Form
+	// permission право, необходимое для заполнения формы, пустое - доступна всем
+	permission domainUser.Permission
+	// cancelData данные кнопки, к которой пользователь возвращается после отмены
+	cancelData func(s dialog.Session) string
+	// complete выполняет действие по заполненной форме
+	complete func(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session)
+}
+
+// registerDialogs регистрирует формы, заполняемые по шагам
+func (i *Implementation) registerDialogs(flows ...dialogFlow) {
+	i.dialogFlows = make(map[string]dialogFlow, len(flows))
+	for _, f := range flows {
+		i.dialogFlows[f.form.Name] = f
+	}
+}
+
+// startDialog начинает заполнение формы вместо сообщения, на кнопку которого нажал пользователь
+func (i *Implementation) startDialog(ctx context.Context,
+	bot *telegramBot.Bot,
+	query *models.CallbackQuery,
+	form *dialog.Form,
+	params map[string]string,
+) {
+	s := i.dialogs.Start(query.From.ID, form, params)
+	sendDialogStep(ctx, bot, query.Message.Message.Chat.ID, query.Message.Message.ID, s, "")
+}
+
+// matchDialog отбирает текстовые сообщения пользователей, заполняющих форму. Команды не перехватываются
+func (i *Implementation) matchDialog(update *models.Update) bool {
+	if update.Message == nil || update.Message.From == nil ||
+		update.Message.Text == "" || strings.HasPrefix(update.Message.Text, "/") {
+		return false
+	}
+
+	_, ok := i.dialogs.Get(update.Message.From.ID)
+	return ok
+}
+
+// DialogAnswer принимает ответ на текущий шаг формы
+func (i *Implementation) DialogAnswer(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	msg := update.Message
+	key := msg.From.ID
+
+	s, ok := i.dialogs.Get(key)
+	if !ok {
+		return
+	}
+
+	flow, ok := i.dialogFlows[s.Form.Name]
+	if !ok {
+		i.dialogs.Cancel(key)
+		return
+	}
+
+	// роль могла быть снята, пока пользователь заполнял форму
+	if flow.permission != "" && !getRoleFromContext(ctx).Can(flow.permission) {
+		i.dialogs.Cancel(key)
+		sendErrorMsg(ctx, bot, msg, nil, getLangFromContext(ctx).T(locale.Forbidden))
+		return
+	}
+
+	s, err := i.dialogs.Answer(key, msg.Text)
+	if err != nil {
+		if errors.Is(err, dialog.ErrNoSession) {
+			return
+		}
+
+		lang := getLangFromContext(ctx)
+		sendDialogStep(ctx, bot, msg.Chat.ID, 0, s, lang.T(locale.DialogInvalidValue, lang.T(invalidValueKey(err))))
+		return
+	}
+
+	if s.Done() {
+		flow.complete(ctx, bot, msg, s)
+		return
+	}
+
+	sendDialogStep(ctx, bot, msg.Chat.ID, 0, s, "")
+}
+
+// DialogBack возвращает к предыдущему шагу формы
+func (i *Implementation) DialogBack(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+
+	s, err := i.dialogs.Back(update.CallbackQuery.From.ID)
+	if err != nil && !errors.Is(err, dialog.ErrFirstStep) {
+		sendDialogExpired(ctx, bot, oldMsg)
+		return
+	}
+
+	sendDialogStep(ctx, bot, oldMsg.Chat.ID, oldMsg.ID, s, "")
+}
+
+// DialogCancel отменяет заполнение формы
+func (i *Implementation) DialogCancel(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+
+	s, ok := i.dialogs.Cancel(update.CallbackQuery.From.ID)
+	if !ok {
+		sendDialogExpired(ctx, bot, oldMsg)
+		return
+	}
+
+	backData := menu.String()
+	if flow, ok := i.dialogFlows[s.Form.Name]; ok && flow.cancelData != nil {
+		backData = flow.cancelData(s)
+	}
+
+	lang := getLangFromContext(ctx)
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.DialogCancelled, lang.T(locale.Key(s.Form.Title))),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: backData,
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// sendDialogStep задает вопрос текущего шага. curMessageID - сообщение, которое заменяется вопросом, 0 - новое
+func sendDialogStep(ctx context.Context,
+	bot *telegramBot.Bot,
+	chatID int64,
+	curMessageID int,
+	s dialog.Session,
+	errText string,
+) {
+	lang := getLangFromContext(ctx)
+	step := s.Current()
+	text := lang.T(locale.DialogStep, lang.T(locale.Key(s.Form.Title)), s.Step+1, len(s.Form.Steps)) + "\n\n"
+	if errText != "" {
+		text += errText + "\n\n"
+	}
+	text += lang.T(locale.Key(step.Title)) + ":"
+	if step.Hint != "" {
+		text += "\n" + lang.T(locale.Key(step.Hint))
+	}
+	if step.Optional {
+		text += "\n" + lang.T(locale.DialogSkipHint, dialog.SkipValue)
+	}
+
+	buttons := make([]models.InlineKeyboardButton, 0, 2)
+	if s.Step != 0 {
+		buttons = append(buttons, models.InlineKeyboardButton{
+			Text:         lang.T(locale.BackButton),
+			CallbackData: dialogBackHandler.String(),
+		})
+	}
+	buttons = append(buttons, models.InlineKeyboardButton{
+		Text:         lang.T(locale.CancelButton),
+		CallbackData: dialogCancelHandler.String(),
+	})
+
+	sender := msgInlineSender{
+		ChatID:       chatID,
+		CurMessageID: curMessageID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{buttons},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// sendDialogExpired сообщает, что форма уже заполнена, отменена или истекла
+func sendDialogExpired(ctx context.Context, bot *telegramBot.Bot, oldMsg *models.Message) {
+	_, err := bot.DeleteMessage(ctx, &telegramBot.DeleteMessageParams{
+		ChatID:    oldMsg.Chat.ID,
+		MessageID: oldMsg.ID,
+	})
+	if err != nil {
+		logger.Errorf(ctx, "error: %v", err)
+	}
+
+	sendErrorMsg(ctx, bot, oldMsg, nil, getLangFromContext(ctx).T(locale.DialogExpired))
+}
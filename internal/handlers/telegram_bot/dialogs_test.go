@@ -0,0 +1,77 @@
+package telegram_bot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+)
+
+func TestInvalidValueKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want locale.Key
+	}{
+		{
+			name: "empty value",
+			err:  dialog.ErrEmptyValue,
+			want: locale.DialogEmptyValue,
+		},
+		{
+			name: "validator error",
+			err:  validatePrice("-1"),
+			want: locale.InvalidPrice,
+		},
+		{
+			name: "wrapped validator error",
+			err:  fmt.Errorf("step: %w", validateTelegramID("abc")),
+			want: locale.InvalidTelegramID,
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("unknown"),
+			want: locale.DialogInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := invalidValueKey(tt.err); got != tt.want {
+				t.Errorf("invalidValueKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialogFormsTranslated(t *testing.T) {
+	t.Parallel()
+	forms := []*dialog.Form{
+		productForm, itemForm, tariffForm, publicationForm, saleForm, stockForm, accessChatForm,
+		translationForm, deactivateMoveForm, roleForm, banForm, unbanForm,
+		findSubscriptionsForm, changeSubscriptionForm, exportUserDataForm, deleteUserDataForm,
+	}
+
+	for _, f := range forms {
+		keys := []string{f.Title}
+		for _, s := range f.Steps {
+			keys = append(keys, s.Title)
+			if s.Hint != "" {
+				keys = append(keys, s.Hint)
+			}
+		}
+
+		for _, lang := range locale.Langs {
+			for _, key := range keys {
+				// без перевода T возвращает сам ключ
+				if lang.T(locale.Key(key)) == key {
+					t.Errorf("form %s: no %s translation for %q", f.Name, lang, key)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,18 @@
 package telegram_bot
 
-import "github.com/kdv2001/onlySubscription/internal/domain/price"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/price"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	domainSubscription "github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+)
 
 func currencyToIcon(c price.Currency) string {
 	switch c {
@@ -10,3 +22,158 @@ func currencyToIcon(c price.Currency) string {
 
 	return string('⚙')
 }
+
+func formatTariff(lang locale.Lang, t domainProducts.Tariff) string {
+	period := t.SubscriptionPeriod.Format(lang)
+	if t.IsFree() {
+		return lang.T(locale.TariffFree, t.Name, period)
+	}
+
+	if t.Sale != nil {
+		return lang.T(locale.TariffSale, t.Name, period, t.Sale.Price.Value,
+			currencyToIcon(t.Sale.Price.Currency), t.Price.Value)
+	}
+
+	return lang.T(locale.TariffPrice, t.Name, period, t.Price.Value, currencyToIcon(t.Price.Currency))
+}
+
+func formatPublication(lang locale.Lang, p domainProducts.Product) string {
+	from, until := lang.T(locale.NoLimit), lang.T(locale.NoLimit)
+	if !p.PublishFrom.IsZero() {
+		from = p.PublishFrom.Format(consts.DateTimeLayout)
+	}
+	if !p.PublishUntil.IsZero() {
+		until = p.PublishUntil.Format(consts.DateTimeLayout)
+	}
+
+	return lang.T(locale.PublicationText, from, until)
+}
+
+func formatStock(lang locale.Lang, p domainProducts.Product) string {
+	switch p.StockMode {
+	case domainProducts.UnlimitedStock:
+		return lang.T(locale.StockUnlimitedText, formatSharedPayload(lang, p.Stock), p.Stock.Issued)
+	case domainProducts.CappedStock:
+		return lang.T(locale.StockCappedText, formatSharedPayload(lang, p.Stock), p.Stock.Issued, p.StockLimit)
+	default:
+		return lang.T(locale.StockUniqueText, p.Stock.OnSale)
+	}
+}
+
+func formatSharedPayload(lang locale.Lang, s domainProducts.Stock) string {
+	if s.HasSharedPayload {
+		return lang.T(locale.SharedPayloadSet)
+	}
+
+	return lang.T(locale.SharedPayloadNotSet)
+}
+
+// formatAvailableNum возвращает кол-во доступных для продажи единиц
+func formatAvailableNum(lang locale.Lang, p domainProducts.Product) string {
+	num, unlimited := p.Available(p.Stock)
+	if unlimited {
+		return lang.T(locale.StockUnlimited)
+	}
+
+	return lang.T(locale.StockPieces, num)
+}
+
+// formatAvailable возвращает кол-во доступных единиц для подписи кнопки
+func formatAvailable(p domainProducts.Product) string {
+	num, unlimited := p.Available(p.Stock)
+	if unlimited {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%d)", num)
+}
+
+// pagesNum возвращает кол-во страниц, не меньше одной
+func pagesNum(total, pageSize uint64) uint64 {
+	if total == 0 || pageSize == 0 {
+		return 1
+	}
+
+	return (total + pageSize - 1) / pageSize
+}
+
+func formatTariffs(lang locale.Lang, tariffs domainProducts.Tariffs) string {
+	if len(tariffs) == 0 {
+		return lang.T(locale.NoTariffs)
+	}
+
+	lines := make([]string, 0, len(tariffs))
+	for _, t := range tariffs {
+		lines = append(lines, formatTariff(lang, t))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatRenewal форматирует продление подписки
+func formatRenewal(r domainSubscription.Renewal) string {
+	return fmt.Sprintf("%s → %s (UTC)",
+		r.PreviousDeadline.Format(consts.DateTimeLayout),
+		r.NewDeadline.Format(consts.DateTimeLayout))
+}
+
+// formatRemaining форматирует время, оставшееся до deadline
+func formatRemaining(lang locale.Lang, deadline, now time.Time) string {
+	left := deadline.Sub(now)
+	if left <= 0 {
+		return lang.T(locale.RemainingExpired)
+	}
+
+	days := int(left / (24 * time.Hour))
+	hours := int(left % (24 * time.Hour) / time.Hour)
+	if days > 0 {
+		return lang.T(locale.RemainingDays, days, hours)
+	}
+
+	minutes := int(left % time.Hour / time.Minute)
+	return lang.T(locale.RemainingHours, hours, minutes)
+}
+
+// formatAccessChat форматирует чат, доступ в который выдает подписка
+func formatAccessChat(lang locale.Lang, chatID int64) string {
+	if chatID == 0 {
+		return lang.T(locale.NoValue)
+	}
+
+	return fmt.Sprint(chatID)
+}
+
+// formatEventData форматирует данные события в порядке ключей
+func formatEventData(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, k+": "+data[k])
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatSubscriptionChange форматирует изменение подписки администратором
+func formatSubscriptionChange(lang locale.Lang, c domainSubscription.Change) string {
+	return lang.T(locale.SubscriptionChangeText,
+		c.CreatedAt.Format(consts.DateTimeLayout),
+		c.Action,
+		c.State.From,
+		c.State.To,
+		c.Reason)
+}
+
+// formatBanUntil форматирует срок блокировки
+func formatBanUntil(lang locale.Lang, ban domainUser.Ban) string {
+	if ban.Until.IsZero() {
+		return lang.T(locale.BanPermanent)
+	}
+
+	return lang.T(locale.BanUntil, ban.Until.Format(consts.DateTimeLayout))
+}
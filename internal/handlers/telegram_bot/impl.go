@@ -2,22 +2,43 @@ package telegram_bot
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domainOrder "github.com/kdv2001/onlySubscription/internal/domain/order"
 	domainPayment "github.com/kdv2001/onlySubscription/internal/domain/payment"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainPrivacy "github.com/kdv2001/onlySubscription/internal/domain/privacy"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	domainReferral "github.com/kdv2001/onlySubscription/internal/domain/referral"
+	domainSubscription "github.com/kdv2001/onlySubscription/internal/domain/subscription"
 	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	domainWebhook "github.com/kdv2001/onlySubscription/internal/domain/webhook"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
 	"github.com/kdv2001/onlySubscription/pkg/logger"
 )
 
 type productsUseCase interface {
-	GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.Products, error)
+	GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.ProductsPage, error)
 	GetProduct(ctx context.Context, id domainProducts.ID) (domainProducts.Product, error)
 	CreateProduct(ctx context.Context, product domainProducts.Product) error
-	DeactivateProduct(ctx context.Context, id domainProducts.ID) error
+	DeactivateProduct(ctx context.Context, req domainProducts.Deactivation) error
+	RestoreProduct(ctx context.Context, id domainProducts.ID) error
+
+	AddTariff(ctx context.Context, tariff domainProducts.Tariff) error
+	GetTariff(ctx context.Context, id domainProducts.TariffID) (domainProducts.Tariff, error)
+	DeleteTariff(ctx context.Context, id domainProducts.TariffID) error
+	SetPublication(ctx context.Context, id domainProducts.ID, publication domainProducts.Publication) error
+	SetStockMode(ctx context.Context, id domainProducts.ID, mode domainProducts.StockMode, limit int64) error
+	SetAccessChat(ctx context.Context, id domainProducts.ID, chatID int64) error
+	AddSale(ctx context.Context, sale domainProducts.Sale) error
+	SetTranslation(ctx context.Context, t domainProducts.Translation) error
+	DeleteTranslation(ctx context.Context, id domainProducts.ID, lang locale.Lang) error
 
 	AddItem(ctx context.Context, item domainProducts.Item) error
 	GetItems(ctx context.Context, req domainProducts.RequestList) ([]domainProducts.Item, error)
@@ -27,15 +48,61 @@ type productsUseCase interface {
 
 type userUseCase interface {
 	RegisterByTelegramID(ctx context.Context,
-		telegramBotLogin domainUser.TelegramBotRegister) (domainUser.ID, error)
+		telegramBotLogin domainUser.TelegramBotRegister) (domainUser.User, bool, error)
 	GetUserByTelegramID(ctx context.Context,
 		tgID domainUser.TelegramID) (domainUser.User, error)
+	SetRole(ctx context.Context,
+		actorID domainUser.ID,
+		tgID domainUser.TelegramID,
+		role domainUser.Role,
+	) (domainUser.User, error)
+	GetStaff(ctx context.Context) ([]domainUser.User, error)
+	BanUser(ctx context.Context, req domainUser.BanRequest) (domainUser.User, error)
+	UnbanUser(ctx context.Context, adminID domainUser.ID, tgID domainUser.TelegramID) (domainUser.User, error)
+	SetLanguage(ctx context.Context, id domainUser.ID, lang locale.Lang) error
 }
 
 type orderUseCase interface {
 	CreateOrder(ctx context.Context, o domainOrder.CreateOrder) (domainOrder.ID, error)
 	GetOrder(ctx context.Context, oID domainOrder.ID, userID domainUser.ID) (domainOrder.Order, error)
 	GetOrderList(ctx context.Context, userID domainUser.ID, list domainOrder.RequestList) ([]domainOrder.Order, error)
+	RevealOrderPayload(ctx context.Context, oID domainOrder.ID, userID domainUser.ID) (string, error)
+	CancelUserOrders(ctx context.Context, userID domainUser.ID) (int, error)
+}
+
+type subscriptionUseCase interface {
+	GetUserSubscriptions(ctx context.Context,
+		userID domainUser.ID,
+		pagination primitives.Pagination,
+	) ([]domainSubscription.Subscription, error)
+	GetUserSubscription(ctx context.Context,
+		id domainSubscription.ID,
+		userID domainUser.ID,
+	) (domainSubscription.Subscription, error)
+	GetRenewals(ctx context.Context, id domainSubscription.ID) ([]domainSubscription.Renewal, error)
+	GetRenewalByOrder(ctx context.Context, orderID domainOrder.ID) (domainSubscription.Renewal, error)
+	GetChatAccess(ctx context.Context, id domainSubscription.ID) (domainSubscription.ChatAccess, error)
+
+	GetSubscription(ctx context.Context, id domainSubscription.ID) (domainSubscription.Subscription, error)
+	ChangeSubscription(ctx context.Context, req domainSubscription.ChangeRequest) (domainSubscription.Subscription, error)
+	GetChanges(ctx context.Context, id domainSubscription.ID) ([]domainSubscription.Change, error)
+}
+
+type webhookUseCase interface {
+	GetDeliveries(ctx context.Context, r domainWebhook.RequestList) (domainWebhook.DeliveriesPage, error)
+	GetDelivery(ctx context.Context, id domainWebhook.DeliveryID) (domainWebhook.Delivery, error)
+	ReplayDelivery(ctx context.Context, id domainWebhook.DeliveryID) error
+}
+
+type referralUseCase interface {
+	GetStats(ctx context.Context, userID domainUser.ID) (domainReferral.Stats, error)
+	Policy() domainReferral.Policy
+	Attribute(ctx context.Context, referredID domainUser.ID, code domainReferral.Code) error
+}
+
+type privacyUseCase interface {
+	Export(ctx context.Context, userID domainUser.ID) (domainPrivacy.Export, error)
+	Delete(ctx context.Context, userID domainUser.ID) (domainPrivacy.Deletion, error)
 }
 
 type paymentClient interface {
@@ -73,20 +140,124 @@ const (
 	getOrderHandler     handlerName = "get_order"
 	getOrderListHandler handlerName = "order_list"
 	createInvoice       handlerName = "create_invoice"
+	// subscriptionHistoryHandler история продлений подписки
+	subscriptionHistoryHandler handlerName = "subscription_history"
+	// subscriptionListHandler список подписок пользователя
+	subscriptionListHandler handlerName = "subscription_list"
+	// getSubscriptionHandler карточка подписки
+	getSubscriptionHandler handlerName = "get_subscription"
+	// subscriptionKeyHandler повторное открытие выданной полезной нагрузки
+	subscriptionKeyHandler handlerName = "subscription_key"
+	// renewHandler продление подписки из напоминания, совпадает с действием кнопки сообщения
+	renewHandler handlerName = handlerName(communication.RenewAction)
+	// languageHandler выбор языка интерфейса
+	languageHandler    handlerName = "language"
+	setLanguageHandler handlerName = "set_language"
+	// referralHandler реферальная ссылка и статистика приглашений
+	referralHandler handlerName = "referral"
+	// myDataHandler выгрузка данных пользователя
+	myDataHandler handlerName = "mydata"
+	// deleteMeHandler удаление данных пользователя, выполняется после подтверждения
+	deleteMeHandler        handlerName = "deleteme"
+	confirmDeleteMeHandler handlerName = "confirm_deleteme"
+	// dialogBackHandler и dialogCancelHandler кнопки формы, заполняемой по шагам
+	dialogBackHandler   handlerName = "dialog_back"
+	dialogCancelHandler handlerName = "dialog_cancel"
 
 	// administration
 	adminHandler                handlerName = "admin"
 	getAllProductsHandler       handlerName = "get_all_products"
 	getProductForEditHandler    handlerName = "get_product_for_edit"
-	createProductHandler        handlerName = "create_product"
 	getCreateProductInfoHandler handlerName = "get_create_product_info"
 	getCreateItemInfoHandler    handlerName = "get_create_item_info"
 	deleteProductHandler        handlerName = "delete_product"
-	createItemHandler           handlerName = "add_item"
 	getItemHandler              handlerName = "get_item"
 	deleteItemHandler           handlerName = "delete_item"
+	getCreateTariffInfoHandler  handlerName = "get_create_tariff_info"
+	deleteTariffHandler         handlerName = "delete_tariff"
+	getPublishInfoHandler       handlerName = "get_publish_info"
+	getCreateSaleInfoHandler    handlerName = "get_create_sale_info"
+	getStockInfoHandler         handlerName = "get_stock_info"
+	getDeactivateInfoHandler    handlerName = "get_deactivate_info"
+	deactivateMoveHandler       handlerName = "deactivate_move"
+	getArchiveHandler           handlerName = "archive_list"
+	getArchivedProductHandler   handlerName = "get_archived_product"
+	restoreProductHandler       handlerName = "restore_product"
+	getAccessChatInfoHandler    handlerName = "get_access_chat_info"
+	// getTranslationInfoHandler форма перевода названия и описания продукта
+	getTranslationInfoHandler handlerName = "get_translation_info"
+	webhookListHandler        handlerName = "webhook_list"
+	getWebhookDeliveryHandler handlerName = "get_webhook_delivery"
+	replayWebhookHandler      handlerName = "replay_webhook"
+	// subscriptionSearchInfoHandler форма поиска подписок пользователя
+	subscriptionSearchInfoHandler handlerName = "sub_search_info"
+	// manageSubscriptionHandler карточка подписки для администратора
+	manageSubscriptionHandler handlerName = "manage_subscription"
+	changeSubscriptionHandler handlerName = "change_subscription"
+	// staffHandler список сотрудников и форма назначения роли
+	staffHandler   handlerName = "staff"
+	setRoleHandler handlerName = "set_role"
+	// banInfoHandler формы блокировки и разблокировки пользователя
+	banInfoHandler   handlerName = "ban_info"
+	banUserHandler   handlerName = "ban_user"
+	unbanUserHandler handlerName = "unban_user"
+	// userDataInfoHandler формы выгрузки и удаления данных пользователя
+	userDataInfoHandler   handlerName = "user_data_info"
+	exportUserDataHandler handlerName = "export_user_data"
+	deleteUserDataHandler handlerName = "delete_user_data"
 )
 
+// handlerPermissions права, необходимые для обработчиков панели администрирования.
+// Обработчики без записи доступны всем пользователям
+var handlerPermissions = map[handlerName]domainUser.Permission{
+	adminHandler: domainUser.AdminPanelPermission,
+
+	getAllProductsHandler:       domainUser.ProductsPermission,
+	getProductForEditHandler:    domainUser.ProductsPermission,
+	getCreateProductInfoHandler: domainUser.ProductsPermission,
+	getCreateItemInfoHandler:    domainUser.ProductsPermission,
+	deleteProductHandler:        domainUser.ProductsPermission,
+	getItemHandler:              domainUser.ProductsPermission,
+	deleteItemHandler:           domainUser.ProductsPermission,
+	getCreateTariffInfoHandler:  domainUser.ProductsPermission,
+	deleteTariffHandler:         domainUser.ProductsPermission,
+	getPublishInfoHandler:       domainUser.ProductsPermission,
+	getCreateSaleInfoHandler:    domainUser.ProductsPermission,
+	getStockInfoHandler:         domainUser.ProductsPermission,
+	getDeactivateInfoHandler:    domainUser.ProductsPermission,
+	deactivateMoveHandler:       domainUser.ProductsPermission,
+	getArchiveHandler:           domainUser.ProductsPermission,
+	getArchivedProductHandler:   domainUser.ProductsPermission,
+	restoreProductHandler:       domainUser.ProductsPermission,
+	getAccessChatInfoHandler:    domainUser.ProductsPermission,
+	getTranslationInfoHandler:   domainUser.ProductsPermission,
+
+	webhookListHandler:        domainUser.WebhooksPermission,
+	getWebhookDeliveryHandler: domainUser.WebhooksPermission,
+	replayWebhookHandler:      domainUser.WebhooksPermission,
+
+	subscriptionSearchInfoHandler: domainUser.SubscriptionsPermission,
+	manageSubscriptionHandler:     domainUser.SubscriptionsPermission,
+	changeSubscriptionHandler:     domainUser.SubscriptionsPermission,
+
+	staffHandler:   domainUser.RolesPermission,
+	setRoleHandler: domainUser.RolesPermission,
+
+	banInfoHandler:   domainUser.UsersPermission,
+	banUserHandler:   domainUser.UsersPermission,
+	unbanUserHandler: domainUser.UsersPermission,
+
+	userDataInfoHandler:   domainUser.UsersPermission,
+	exportUserDataHandler: domainUser.UsersPermission,
+	deleteUserDataHandler: domainUser.UsersPermission,
+}
+
+// Permission возвращает право, необходимое для обработчика, и признак его наличия
+func (h handlerName) Permission() (domainUser.Permission, bool) {
+	p, ok := handlerPermissions[h]
+	return p, ok
+}
+
 func (h handlerName) GetBackHandler() handlerName {
 	switch h {
 	case adminHandler, helpHandler, profileHandler, productsHandler:
@@ -97,14 +268,34 @@ func (h handlerName) GetBackHandler() handlerName {
 		return productHandler
 	case createInvoice:
 		return createOrder
-	case createProductHandler, getAllProductsHandler:
+	case getCreateProductInfoHandler, getAllProductsHandler, getArchiveHandler, webhookListHandler,
+		subscriptionSearchInfoHandler, staffHandler, setRoleHandler,
+		banInfoHandler, userDataInfoHandler:
 		return adminHandler
+	case banUserHandler, unbanUserHandler:
+		return banInfoHandler
+	case exportUserDataHandler, deleteUserDataHandler:
+		return userDataInfoHandler
+	case manageSubscriptionHandler:
+		return subscriptionSearchInfoHandler
+	case getWebhookDeliveryHandler, replayWebhookHandler:
+		return webhookListHandler
+	case getArchivedProductHandler, restoreProductHandler:
+		return getArchiveHandler
+	case getDeactivateInfoHandler:
+		return getProductForEditHandler
+	case deactivateMoveHandler:
+		return getAllProductsHandler
 	case getProductForEditHandler:
 		return getAllProductsHandler
 	case getCreateItemInfoHandler:
 		return getProductForEditHandler
 	case getItemHandler:
 		return getProductForEditHandler
+	case getCreateTariffInfoHandler, deleteTariffHandler,
+		getPublishInfoHandler, getCreateSaleInfoHandler, getStockInfoHandler, getAccessChatInfoHandler,
+		getTranslationInfoHandler:
+		return getProductForEditHandler
 	case deleteItemHandler:
 		return getItemHandler
 	case deleteProductHandler:
@@ -113,17 +304,30 @@ func (h handlerName) GetBackHandler() handlerName {
 		return profileHandler
 	case getOrderHandler:
 		return getOrderListHandler
+	case subscriptionListHandler, languageHandler, setLanguageHandler, referralHandler:
+		return profileHandler
+	case getSubscriptionHandler:
+		return subscriptionListHandler
+	case subscriptionHistoryHandler, subscriptionKeyHandler:
+		return getSubscriptionHandler
 	}
 
 	return menu
 }
 
 type Implementation struct {
-	productsUseCase productsUseCase
-	userUseCase     userUseCase
-	orderUseCase    orderUseCase
-	paymentClient   paymentClient
-	bot             *telegramBot.Bot
+	productsUseCase     productsUseCase
+	userUseCase         userUseCase
+	orderUseCase        orderUseCase
+	subscriptionUseCase subscriptionUseCase
+	webhookUseCase      webhookUseCase
+	referralUseCase     referralUseCase
+	privacyUseCase      privacyUseCase
+	paymentClient       paymentClient
+	bot                 *telegramBot.Bot
+	// dialogs незаполненные формы пользователей
+	dialogs     *dialog.Store
+	dialogFlows map[string]dialogFlow
 }
 
 const (
@@ -135,82 +339,265 @@ func NewImplementation(
 	productsUseCase productsUseCase,
 	userUseCase userUseCase,
 	orderUseCase orderUseCase,
+	subscriptionUseCase subscriptionUseCase,
+	webhookUseCase webhookUseCase,
+	referralUseCase referralUseCase,
+	privacyUseCase privacyUseCase,
 	paymentClient paymentClient,
 	bot *telegramBot.Bot,
 ) *Implementation {
 	i := &Implementation{
-		productsUseCase: productsUseCase,
-		orderUseCase:    orderUseCase,
-		userUseCase:     userUseCase,
-		paymentClient:   paymentClient,
-		bot:             bot,
+		productsUseCase:     productsUseCase,
+		orderUseCase:        orderUseCase,
+		userUseCase:         userUseCase,
+		subscriptionUseCase: subscriptionUseCase,
+		webhookUseCase:      webhookUseCase,
+		referralUseCase:     referralUseCase,
+		privacyUseCase:      privacyUseCase,
+		paymentClient:       paymentClient,
+		bot:                 bot,
+		dialogs:             dialog.NewStore(dialogTTL),
 	}
 
+	i.registerDialogs(
+		dialogFlow{
+			form:       productForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: func(dialog.Session) string {
+				return getCreateProductInfoHandler.GetBackHandler().String()
+			},
+			complete: i.createProduct,
+		},
+		dialogFlow{
+			form:       itemForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.addItem,
+		},
+		dialogFlow{
+			form:       tariffForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.addTariff,
+		},
+		dialogFlow{
+			form:       publicationForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.setPublication,
+		},
+		dialogFlow{
+			form:       saleForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.addSale,
+		},
+		dialogFlow{
+			form:       stockForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.setStockMode,
+		},
+		dialogFlow{
+			form:       accessChatForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.setAccessChat,
+		},
+		dialogFlow{
+			form:       translationForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: productCancelData,
+			complete:   i.setTranslation,
+		},
+		dialogFlow{
+			form:       deactivateMoveForm,
+			permission: domainUser.ProductsPermission,
+			cancelData: func(s dialog.Session) string {
+				return getDeactivateInfoHandler.Data().ID(s.Param(productParam)).String()
+			},
+			complete: i.deactivateWithMove,
+		},
+		dialogFlow{
+			form:       findSubscriptionsForm,
+			permission: domainUser.SubscriptionsPermission,
+			cancelData: func(dialog.Session) string {
+				return subscriptionSearchInfoHandler.GetBackHandler().String()
+			},
+			complete: i.findSubscriptions,
+		},
+		dialogFlow{
+			form:       changeSubscriptionForm,
+			permission: domainUser.SubscriptionsPermission,
+			cancelData: func(s dialog.Session) string {
+				return manageSubscriptionHandler.Data().ID(s.Param(subscriptionParam)).String()
+			},
+			complete: i.changeSubscription,
+		},
+		dialogFlow{
+			form:       roleForm,
+			permission: domainUser.RolesPermission,
+			cancelData: func(dialog.Session) string {
+				return staffHandler.String()
+			},
+			complete: i.setRole,
+		},
+		dialogFlow{
+			form:       banForm,
+			permission: domainUser.UsersPermission,
+			cancelData: func(dialog.Session) string {
+				return banInfoHandler.String()
+			},
+			complete: i.banUser,
+		},
+		dialogFlow{
+			form:       unbanForm,
+			permission: domainUser.UsersPermission,
+			cancelData: func(dialog.Session) string {
+				return banInfoHandler.String()
+			},
+			complete: i.unbanUser,
+		},
+		dialogFlow{
+			form:       exportUserDataForm,
+			permission: domainUser.UsersPermission,
+			cancelData: func(dialog.Session) string {
+				return userDataInfoHandler.String()
+			},
+			complete: i.exportUserData,
+		},
+		dialogFlow{
+			form:       deleteUserDataForm,
+			permission: domainUser.UsersPermission,
+			cancelData: func(dialog.Session) string {
+				return userDataInfoHandler.String()
+			},
+			complete: i.deleteUserData,
+		},
+	)
+
+	// ответы на шаги формы проверяются раньше остальных обработчиков текста
+	bot.RegisterHandlerMatchFunc(i.matchDialog, i.DialogAnswer)
+	registerCallback(bot, dialogBackHandler, i.DialogBack)
+	registerCallback(bot, dialogCancelHandler, i.DialogCancel)
+
 	bot.RegisterHandler(telegramBot.HandlerTypeMessageText,
 		menu.String(), telegramBot.MatchTypeCommand, i.GetMenu)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		menu.String(), telegramBot.MatchTypePrefix, i.GetMenu)
+	registerCallback(bot, menu, i.GetMenu)
 	bot.RegisterHandler(telegramBot.HandlerTypeMessageText,
 		startHandler.String(), telegramBot.MatchTypeCommand, i.Start)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		helpHandler.String(), telegramBot.MatchTypePrefix, i.GetHelp)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		profileHandler.String(), telegramBot.MatchTypePrefix, i.GetProfile)
-
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		productsHandler.String(), telegramBot.MatchTypePrefix, i.GetProducts)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		productHandler.String(), telegramBot.MatchTypePrefix, i.GetProduct)
-
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getAllProductsHandler.String(), telegramBot.MatchTypePrefix, i.GetAllProducts)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getProductForEditHandler.String(), telegramBot.MatchTypePrefix, i.GetProductForEdit)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		adminHandler.String(), telegramBot.MatchTypePrefix, i.GetAdminMenu)
+	registerCallback(bot, helpHandler, i.GetHelp)
+	registerCallback(bot, profileHandler, i.GetProfile)
+	registerCallback(bot, languageHandler, i.GetLanguage)
+	registerCallback(bot, setLanguageHandler, i.SetLanguage)
+	registerCallback(bot, referralHandler, i.GetReferral)
 	bot.RegisterHandler(telegramBot.HandlerTypeMessageText,
-		createProductHandler.String(), telegramBot.MatchTypeCommand, i.CreateProduct)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getCreateProductInfoHandler.String(), telegramBot.MatchTypePrefix, i.GetCreateProductInfo)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		deleteProductHandler.String(), telegramBot.MatchTypePrefix, i.DeleteProduct)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getCreateItemInfoHandler.String(), telegramBot.MatchTypePrefix, i.GetCreateItemInfo)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		deleteItemHandler.String(), telegramBot.MatchTypePrefix, i.DeleteItem)
+		myDataHandler.String(), telegramBot.MatchTypeCommand, i.GetMyData)
 	bot.RegisterHandler(telegramBot.HandlerTypeMessageText,
-		createItemHandler.String(), telegramBot.MatchTypeCommand, i.AddItem)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getItemHandler.String(), telegramBot.MatchTypePrefix, i.GetItem)
+		deleteMeHandler.String(), telegramBot.MatchTypeCommand, i.GetDeleteMeInfo)
+	registerCallback(bot, confirmDeleteMeHandler, i.DeleteMe)
+
+	registerCallback(bot, productsHandler, i.GetProducts)
+	registerCallback(bot, productHandler, i.GetProduct)
+
+	registerCallback(bot, getAllProductsHandler, i.GetAllProducts)
+	registerCallback(bot, getProductForEditHandler, i.GetProductForEdit)
+	registerCallback(bot, adminHandler, i.GetAdminMenu)
+	registerCallback(bot, getCreateProductInfoHandler, i.GetCreateProductInfo)
+	registerCallback(bot, deleteProductHandler, i.DeleteProduct)
+	registerCallback(bot, getDeactivateInfoHandler, i.GetDeactivateInfo)
+	registerCallback(bot, deactivateMoveHandler, i.GetDeactivateMoveInfo)
+	registerCallback(bot, getArchivedProductHandler, i.GetArchivedProduct)
+	registerCallback(bot, getArchiveHandler, i.GetArchive)
+	registerCallback(bot, restoreProductHandler, i.RestoreProduct)
+	registerCallback(bot, getCreateItemInfoHandler, i.GetCreateItemInfo)
+	registerCallback(bot, deleteItemHandler, i.DeleteItem)
+	registerCallback(bot, getItemHandler, i.GetItem)
+	registerCallback(bot, getCreateTariffInfoHandler, i.GetCreateTariffInfo)
+	registerCallback(bot, deleteTariffHandler, i.DeleteTariff)
+	registerCallback(bot, getPublishInfoHandler, i.GetPublishInfo)
+	registerCallback(bot, getCreateSaleInfoHandler, i.GetCreateSaleInfo)
+	registerCallback(bot, getStockInfoHandler, i.GetStockInfo)
+	registerCallback(bot, getAccessChatInfoHandler, i.GetAccessChatInfo)
+	registerCallback(bot, getTranslationInfoHandler, i.GetTranslationInfo)
+	registerCallback(bot, webhookListHandler, i.GetWebhookDeliveries)
+	registerCallback(bot, getWebhookDeliveryHandler, i.GetWebhookDelivery)
+	registerCallback(bot, replayWebhookHandler, i.ReplayWebhookDelivery)
+	registerCallback(bot, subscriptionSearchInfoHandler, i.GetSubscriptionSearchInfo)
+	registerCallback(bot, manageSubscriptionHandler, i.ManageSubscription)
+	registerCallback(bot, changeSubscriptionHandler, i.GetChangeSubscriptionInfo)
+	registerCallback(bot, staffHandler, i.GetStaff)
+	registerCallback(bot, setRoleHandler, i.GetSetRoleInfo)
+	registerCallback(bot, banInfoHandler, i.GetBanInfo)
+	registerCallback(bot, banUserHandler, i.GetBanUserInfo)
+	registerCallback(bot, unbanUserHandler, i.GetUnbanUserInfo)
+	registerCallback(bot, userDataInfoHandler, i.GetUserDataInfo)
+	registerCallback(bot, exportUserDataHandler, i.GetExportUserDataInfo)
+	registerCallback(bot, deleteUserDataHandler, i.GetDeleteUserDataInfo)
 
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		createOrder.String(), telegramBot.MatchTypePrefix, i.CreateOrder)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getOrderHandler.String(), telegramBot.MatchTypePrefix, i.GetOrder)
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		getOrderListHandler.String(), telegramBot.MatchTypePrefix, i.OrderList)
+	registerCallback(bot, createOrder, i.CreateOrder)
+	registerCallback(bot, renewHandler, i.Renew)
+	registerCallback(bot, getOrderHandler, i.GetOrder)
+	registerCallback(bot, getOrderListHandler, i.OrderList)
+	registerCallback(bot, subscriptionHistoryHandler, i.GetSubscriptionHistory)
+	registerCallback(bot, subscriptionListHandler, i.GetSubscriptions)
+	registerCallback(bot, getSubscriptionHandler, i.GetSubscription)
+	registerCallback(bot, subscriptionKeyHandler, i.GetSubscriptionKey)
 
-	bot.RegisterHandler(telegramBot.HandlerTypeCallbackQueryData,
-		createInvoice.String(), telegramBot.MatchTypePrefix, i.CreateInvoice)
+	registerCallback(bot, createInvoice, i.CreateInvoice)
 
 	return i
 }
 
+// Start регистрирует пользователя или обновляет данные уже зарегистрированного и отвечает меню
 func (i *Implementation) Start(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	id := update.Message.From.ID
-	_, err := i.userUseCase.RegisterByTelegramID(ctx, domainUser.TelegramBotRegister{
-		TelegramID: domainUser.NewTelegramID(id),
-		ChatID:     update.Message.Chat.ID,
+	msg := update.Message
+	user, created, err := i.userUseCase.RegisterByTelegramID(ctx, domainUser.TelegramBotRegister{
+		TelegramID:   domainUser.NewTelegramID(msg.From.ID),
+		ChatID:       msg.Chat.ID,
+		LanguageCode: msg.From.LanguageCode,
+		Username:     msg.From.Username,
+		FirstName:    msg.From.FirstName,
+		LastName:     msg.From.LastName,
 	})
 	if err != nil {
-		logger.Errorf(ctx, "err: %v", err)
+		sendErrorMsg(ctx, bot, msg, err, "")
 		return
 	}
 
+	lang := user.Lang()
+	if user.IsBlocked(time.Now().UTC()) {
+		_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
+			ChatID: msg.Chat.ID,
+			Text:   lang.T(locale.AccountBlocked, formatBanUntil(lang, user.Ban), user.Ban.Reason),
+		})
+		if err != nil {
+			logger.Errorf(ctx, "error send blocked msg: %v", err)
+		}
+		return
+	}
+
+	text := lang.T(locale.WelcomeBack)
+	if created {
+		text = lang.T(locale.Registered)
+
+		// реферальная ссылка передает код в параметре команды: /start ref_<код>
+		_, param, _ := strings.Cut(msg.Text, " ")
+		if code, ok := domainReferral.ParseStartParam(param); ok {
+			if err = i.referralUseCase.Attribute(ctx, user.ID, code); err != nil {
+				logger.Errorf(ctx, "error attribute referral: %v", err)
+			} else {
+				text += "\n\n" + lang.T(locale.ReferralInvited)
+			}
+		}
+	}
+
 	_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
-		BusinessConnectionID: "",
-		ChatID:               update.Message.Chat.ID,
-		Text:                 "Привет! ты успешно зарегистрирован. Выполни команду /menu, чтобы начать ",
+		ChatID: msg.Chat.ID,
+		Text:   text + "\n\n" + lang.T(locale.MenuText),
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: menuKeyboard(lang, user.Role),
+		},
 	})
 	if err != nil {
 		logger.Errorf(ctx, "err: %v", err)
@@ -218,33 +605,43 @@ func (i *Implementation) Start(ctx context.Context, bot *telegramBot.Bot, update
 	}
 }
 
-func (i *Implementation) GetMenu(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+// menuKeyboard кнопки главного меню, панель администрирования видна только сотрудникам
+func menuKeyboard(lang locale.Lang, role domainUser.Role) [][]models.InlineKeyboardButton {
 	results := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Продукты",
+				Text:         lang.T(locale.MenuProducts),
 				CallbackData: productsHandler.String(),
 			},
 			{
-				Text:         "Профиль",
+				Text:         lang.T(locale.MenuProfile),
 				CallbackData: profileHandler.String(),
 			},
 		},
 		{
 			{
-				Text:         "Помощь",
+				Text:         lang.T(locale.MenuHelp),
 				CallbackData: helpHandler.String(),
 			},
 		},
+	}
 
-		{
+	if role.Can(domainUser.AdminPanelPermission) {
+		results = append(results, []models.InlineKeyboardButton{
 			{
-				Text:         "Администрирование",
+				Text:         lang.T(locale.MenuAdmin),
 				CallbackData: adminHandler.String(),
 			},
-		},
+		})
 	}
 
+	return results
+}
+
+func (i *Implementation) GetMenu(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	lang := getLangFromContext(ctx)
+	results := menuKeyboard(lang, getRoleFromContext(ctx))
+
 	var chatID int64
 	if update.CallbackQuery != nil {
 		chatID = update.CallbackQuery.Message.Message.Chat.ID
@@ -261,7 +658,7 @@ func (i *Implementation) GetMenu(ctx context.Context, bot *telegramBot.Bot, upda
 
 	_, err := bot.SendMessage(ctx, &telegramBot.SendMessageParams{
 		ChatID: chatID,
-		Text:   "Привет, друг! Добро пожаловать в мой магазин!",
+		Text:   lang.T(locale.MenuText),
 		ReplyMarkup: &models.InlineKeyboardMarkup{
 			InlineKeyboard: results,
 		},
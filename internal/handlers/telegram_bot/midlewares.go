@@ -4,22 +4,27 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
+	"unicode/utf16"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"go.uber.org/zap"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domainPayment "github.com/kdv2001/onlySubscription/internal/domain/payment"
 	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/callback"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 	"github.com/kdv2001/onlySubscription/pkg/logger"
 )
 
 type userUC interface {
 	RegisterByTelegramID(ctx context.Context,
-		telegramBotLogin domainUser.TelegramBotRegister) (domainUser.ID, error)
+		telegramBotLogin domainUser.TelegramBotRegister) (domainUser.User, bool, error)
 	GetUserByTelegramID(ctx context.Context,
 		tgID domainUser.TelegramID) (domainUser.User, error)
+	SyncTelegramLanguage(ctx context.Context, u domainUser.User, code string) error
 }
 
 type AuthMiddleware struct {
@@ -45,6 +50,34 @@ func getUserIDFromContext(ctx context.Context) (domainUser.ID, error) {
 	return domainUser.NewID(id), nil
 }
 
+type roleKeyStruct struct{}
+
+var roleKey = roleKeyStruct{}
+
+// getRoleFromContext возвращает роль пользователя, без авторизации - роль покупателя
+func getRoleFromContext(ctx context.Context) domainUser.Role {
+	role, ok := ctx.Value(roleKey).(domainUser.Role)
+	if !ok {
+		return domainUser.CustomerRole
+	}
+
+	return role
+}
+
+type langKeyStruct struct{}
+
+var langKey = langKeyStruct{}
+
+// getLangFromContext возвращает язык интерфейса пользователя, без авторизации - язык по умолчанию
+func getLangFromContext(ctx context.Context) locale.Lang {
+	lang, ok := ctx.Value(langKey).(locale.Lang)
+	if !ok {
+		return locale.Default
+	}
+
+	return lang
+}
+
 func (am *AuthMiddleware) Middleware(next telegramBot.HandlerFunc) telegramBot.HandlerFunc {
 	return func(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 		if update.Message != nil && strings.Contains(update.Message.Text, "/start") {
@@ -52,28 +85,28 @@ func (am *AuthMiddleware) Middleware(next telegramBot.HandlerFunc) telegramBot.H
 			return
 		}
 
-		var tgID domainUser.TelegramID
+		var from *models.User
 		var chatID int64
 		switch {
 		case update.Message != nil:
 			chatID = update.Message.Chat.ID
-			tgID = domainUser.NewTelegramID(update.Message.From.ID)
+			from = update.Message.From
 		case update.CallbackQuery != nil:
 			chatID = update.CallbackQuery.Message.Message.Chat.ID
-			tgID = domainUser.NewTelegramID(update.CallbackQuery.From.ID)
+			from = &update.CallbackQuery.From
 		case update.PreCheckoutQuery != nil:
-			tgID = domainUser.NewTelegramID(update.PreCheckoutQuery.From.ID)
+			from = update.PreCheckoutQuery.From
 		default:
 			return
 		}
+		tgID := domainUser.NewTelegramID(from.ID)
 
 		user, err := am.auth.GetUserByTelegramID(ctx, tgID)
 		if err != nil {
 			if errors.Is(err, custom_errors.ErrorNotFound) && chatID != 0 {
 				_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
 					ChatID: chatID,
-					Text: "Вы не зарегистрированы в боте." +
-						" Выполните команду /start и затем повторите действия",
+					Text:   locale.FromTelegram(from.LanguageCode).T(locale.NotRegistered),
 				})
 				if err != nil {
 					logger.Errorf(ctx, "error send unauthorized msg: %v", err)
@@ -87,11 +120,130 @@ func (am *AuthMiddleware) Middleware(next telegramBot.HandlerFunc) telegramBot.H
 			return
 		}
 
+		// язык клиента мог измениться, сохраненный код нужен для уведомлений вне ответа на действие
+		if err = am.auth.SyncTelegramLanguage(ctx, user, from.LanguageCode); err != nil {
+			logger.Errorf(ctx, "error sync telegram language: %v", err)
+		}
+		if from.LanguageCode != "" {
+			user.TelegramLanguage = from.LanguageCode
+		}
+		lang := user.Lang()
+
+		if user.IsBlocked(time.Now().UTC()) {
+			if chatID == 0 {
+				return
+			}
+
+			_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
+				ChatID: chatID,
+				Text:   lang.T(locale.AccountBlocked, formatBanUntil(lang, user.Ban), user.Ban.Reason),
+			})
+			if err != nil {
+				logger.Errorf(ctx, "error send blocked msg: %v", err)
+			}
+			return
+		}
+
 		ctx = context.WithValue(ctx, userIDKey, user.ID.String())
+		ctx = context.WithValue(ctx, roleKey, user.Role)
+		ctx = context.WithValue(ctx, langKey, lang)
 		next(ctx, bot, update)
 	}
 }
 
+type RoleMiddleware struct{}
+
+func NewRoleMiddleware() *RoleMiddleware {
+	return &RoleMiddleware{}
+}
+
+// Middleware пропускает к обработчикам панели администрирования только пользователей с нужным правом.
+// Должен выполняться после AuthMiddleware, который помещает роль в context
+func (rm *RoleMiddleware) Middleware(next telegramBot.HandlerFunc) telegramBot.HandlerFunc {
+	return func(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+		// сообщение может содержать несколько команд, и обработчик выбирается по любой из них,
+		// поэтому право проверяется для каждой
+		role := getRoleFromContext(ctx)
+		allowed := true
+		for _, h := range handlersFromUpdate(update) {
+			if permission, ok := h.Permission(); ok && !role.Can(permission) {
+				allowed = false
+				break
+			}
+		}
+
+		if allowed {
+			next(ctx, bot, update)
+			return
+		}
+
+		var chatID int64
+		switch {
+		case update.Message != nil:
+			chatID = update.Message.Chat.ID
+		case update.CallbackQuery != nil:
+			chatID = update.CallbackQuery.Message.Message.Chat.ID
+		}
+
+		_, err := bot.SendMessage(ctx, &telegramBot.SendMessageParams{
+			ChatID: chatID,
+			Text:   getLangFromContext(ctx).T(locale.Forbidden),
+		})
+		if err != nil {
+			logger.Errorf(ctx, "error send forbidden msg: %v", err)
+		}
+	}
+}
+
+// handlersFromUpdate определяет обработчики, которым может быть адресовано обновление.
+// Данные кнопки начинаются с действия, которое совпадает с названием обработчика.
+// Команда сообщения выбирает обработчик, даже если стоит не в начале текста,
+// поэтому учитываются все команды сообщения
+func handlersFromUpdate(update *models.Update) []handlerName {
+	switch {
+	case update.CallbackQuery != nil:
+		return []handlerName{handlerName(callback.ActionOf(update.CallbackQuery.Data))}
+	case update.Message != nil:
+		return append(messageCommands(update.Message.Text, update.Message.Entities),
+			messageCommands(update.Message.Caption, update.Message.CaptionEntities)...)
+	}
+
+	return nil
+}
+
+// messageCommands возвращает команды текста text, включая команду в его начале.
+// Telegram задает смещения сущностей в UTF-16, а библиотека бота сравнивает команды по смещениям в байтах,
+// поэтому команда сущности читается обоими способами
+func messageCommands(text string, entities []models.MessageEntity) []handlerName {
+	commands := make([]handlerName, 0, len(entities)+1)
+	if strings.HasPrefix(text, "/") {
+		commands = append(commands, commandName(strings.Fields(text)[0]))
+	}
+
+	utf16Text := utf16.Encode([]rune(text))
+	for _, e := range entities {
+		if e.Type != models.MessageEntityTypeBotCommand || e.Offset < 0 || e.Length <= 1 {
+			continue
+		}
+
+		end := e.Offset + e.Length
+		if end <= len(text) {
+			commands = append(commands, commandName(text[e.Offset:end]))
+		}
+		if end <= len(utf16Text) {
+			commands = append(commands, commandName(string(utf16.Decode(utf16Text[e.Offset:end]))))
+		}
+	}
+
+	return commands
+}
+
+// commandName название обработчика команды "/command@bot"
+func commandName(command string) handlerName {
+	name, _, _ := strings.Cut(strings.TrimPrefix(command, "/"), "@")
+	return handlerName(name)
+}
+
 // AddLoggerToContextMiddleware помещает logger в context
 func AddLoggerToContextMiddleware(sugarLogger *zap.SugaredLogger) func(next telegramBot.HandlerFunc) telegramBot.HandlerFunc {
 	return func(next telegramBot.HandlerFunc) telegramBot.HandlerFunc {
@@ -0,0 +1,95 @@
+package telegram_bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestHandlersFromUpdate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		message *models.Message
+		want    handlerName
+	}{
+		{
+			name: "command at start",
+			message: &models.Message{
+				Text:     "/ban_user Telegram ID: 1",
+				Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 0, Length: 9}},
+			},
+			want: banUserHandler,
+		},
+		{
+			name: "command with bot name",
+			message: &models.Message{
+				Text: "/ban_user@shop_bot Telegram ID: 1",
+			},
+			want: banUserHandler,
+		},
+		{
+			name: "command after text",
+			message: &models.Message{
+				Text:     "x /ban_user Telegram ID: 1",
+				Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 2, Length: 9}},
+			},
+			want: banUserHandler,
+		},
+		{
+			name: "command after cyrillic text",
+			message: &models.Message{
+				Text:     "привет /ban_user Telegram ID: 1",
+				Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 7, Length: 9}},
+			},
+			want: banUserHandler,
+		},
+		{
+			name: "second command",
+			message: &models.Message{
+				Text: "/menu /set_role",
+				Entities: []models.MessageEntity{
+					{Type: models.MessageEntityTypeBotCommand, Offset: 0, Length: 5},
+					{Type: models.MessageEntityTypeBotCommand, Offset: 6, Length: 9},
+				},
+			},
+			want: setRoleHandler,
+		},
+		{
+			name: "command in caption",
+			message: &models.Message{
+				Caption:         "x /ban_user",
+				CaptionEntities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 2, Length: 9}},
+			},
+			want: banUserHandler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := handlersFromUpdate(&models.Update{Message: tt.message})
+			if !slices.Contains(got, tt.want) {
+				t.Errorf("handlersFromUpdate() = %v, want to contain %s", got, tt.want)
+			}
+
+			if _, ok := tt.want.Permission(); !ok {
+				t.Errorf("%s has no permission", tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlersFromUpdate_PlainText(t *testing.T) {
+	t.Parallel()
+	got := handlersFromUpdate(&models.Update{Message: &models.Message{
+		Text:     "ban_user please",
+		Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBold, Offset: 0, Length: 8}},
+	}})
+	for _, h := range got {
+		if _, ok := h.Permission(); ok {
+			t.Errorf("handlersFromUpdate() = %v, want no admin handlers", got)
+		}
+	}
+}
@@ -3,66 +3,100 @@ package telegram_bot
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strconv"
-	"strings"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domainOrder "github.com/kdv2001/onlySubscription/internal/domain/order"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 func (i *Implementation) CreateOrder(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	oldMsg := update.CallbackQuery.Message.Message
-	strID := strings.TrimPrefix(update.CallbackQuery.Data, createOrder.String())
-	if strID == "" {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.createOrderForTariff(ctx, bot, update.CallbackQuery.Message.Message, strID)
+}
+
+// Renew создает заказ на тот же тариф из напоминания о продлении подписки
+func (i *Implementation) Renew(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
 		return
 	}
 
+	i.createOrderForTariff(ctx, bot, update.CallbackQuery.Message.Message, strID)
+}
+
+func (i *Implementation) createOrderForTariff(ctx context.Context,
+	bot *telegramBot.Bot,
+	oldMsg *models.Message,
+	strID string,
+) {
 	appUserID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		sendErrorMsg(ctx, bot, oldMsg, err, "")
 		return
 	}
 
-	productID := domainProducts.NewID(strID)
+	lang := getLangFromContext(ctx)
 	orderID, err := i.orderUseCase.CreateOrder(ctx, domainOrder.CreateOrder{
-		UserID:    appUserID,
-		ProductID: domainProducts.NewID(strID),
+		UserID:   appUserID,
+		TariffID: domainProducts.NewTariffID(strID),
 	})
 	if err != nil {
+		if errors.Is(err, domainOrder.ErrFreeProductClaimed) {
+			sendErrorMsg(ctx, bot, oldMsg, err, lang.T(locale.FreeClaimed))
+			return
+		}
+		if errors.Is(err, domainProducts.ErrOutOfStock) {
+			sendErrorMsg(ctx, bot, oldMsg, err, lang.T(locale.OutOfStock))
+			return
+		}
 		sendErrorMsg(ctx, bot, oldMsg, err, "")
 		return
 	}
 
-	_, err = i.orderUseCase.GetOrder(ctx, orderID, appUserID)
+	order, err := i.orderUseCase.GetOrder(ctx, orderID, appUserID)
 	if err != nil {
 		sendErrorMsg(ctx, bot, oldMsg, err, "")
 		return
 	}
 
-	keyboard := [][]models.InlineKeyboardButton{
-		{
+	text := lang.T(locale.OrderCreated,
+		order.Product.Title,
+		order.Product.TariffName,
+		order.TotalPrice.Value,
+		currencyToIcon(order.TotalPrice.Currency))
+
+	var keyboard [][]models.InlineKeyboardButton
+	if order.IsFree() {
+		text += lang.T(locale.OrderFree)
+	} else {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
 			{
-				Text:         "Оплатить⭐️",
-				CallbackData: fmt.Sprint(createInvoice.String(), orderID),
+				Text:         lang.T(locale.PayButton) + "⭐️",
+				CallbackData: createInvoice.Data().ID(orderID.String()).String(),
 			},
-		},
+		})
+	}
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			{
-				Text:         "Назад",
-				CallbackData: createOrder.GetBackHandler().String() + productID.String(),
-			},
+			Text:         lang.T(locale.BackButton),
+			CallbackData: createOrder.GetBackHandler().Data().ID(order.Product.ProductID.String()).String(),
 		},
-	}
+	})
 
 	sender := msgInlineSender{
 		ChatID:       oldMsg.Chat.ID,
 		CurMessageID: oldMsg.ID,
-		Text:         "Заказ создан",
+		Text:         text,
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -73,9 +107,8 @@ func (i *Implementation) CreateOrder(ctx context.Context, bot *telegramBot.Bot,
 
 func (i *Implementation) GetOrder(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
-	strID := strings.TrimPrefix(update.CallbackQuery.Data, getOrderHandler.String())
-	if strID == "" {
-		sendErrorMsg(ctx, bot, oldMsg, errors.New("empty order id"), "")
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
 		return
 	}
 
@@ -91,22 +124,41 @@ func (i *Implementation) GetOrder(ctx context.Context, bot *telegramBot.Bot, upd
 		return
 	}
 
+	lang := getLangFromContext(ctx)
 	var keyboard [][]models.InlineKeyboardButton
 	if order.Status == domainOrder.ExpectPayments {
 		keyboard = [][]models.InlineKeyboardButton{
 			{
 				{
-					Text:         "Оплатить",
-					CallbackData: fmt.Sprint(createInvoice.String(), order.ID),
+					Text:         lang.T(locale.PayButton),
+					CallbackData: createInvoice.Data().ID(order.ID.String()).String(),
 					Pay:          true,
 				},
 			},
 		}
 	}
 
+	var renewalText string
+	if order.Status == domainOrder.Performed {
+		renewal, errR := i.subscriptionUseCase.GetRenewalByOrder(ctx, order.ID)
+		switch {
+		case errR == nil:
+			renewalText = lang.T(locale.OrderRenewal, formatRenewal(renewal))
+			keyboard = append(keyboard, []models.InlineKeyboardButton{
+				{
+					Text:         lang.T(locale.RenewalHistory),
+					CallbackData: subscriptionHistoryHandler.Data().ID(renewal.SubscriptionID.String()).String(),
+				},
+			})
+		case !errors.Is(errR, custom_errors.ErrorNotFound):
+			sendErrorMsg(ctx, bot, oldMsg, errR, "")
+			return
+		}
+	}
+
 	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			Text:         "Назад",
+			Text:         lang.T(locale.BackButton),
 			CallbackData: getOrderHandler.GetBackHandler().String(),
 		},
 	})
@@ -114,12 +166,13 @@ func (i *Implementation) GetOrder(ctx context.Context, bot *telegramBot.Bot, upd
 	sender := msgInlineSender{
 		ChatID:       oldMsg.Chat.ID,
 		CurMessageID: oldMsg.ID,
-		Text: fmt.Sprintf("Заказ: %s\nописание: %s\n\n цена: %s %s \nпродукт:%s",
+		Text: lang.T(locale.OrderText,
 			order.ID,
 			order.Product.ProductID,
 			order.TotalPrice.Value,
+			currencyToIcon(order.TotalPrice.Currency),
 			order.Product.Title,
-			currencyToIcon(order.TotalPrice.Currency)),
+			order.Product.TariffName) + renewalText,
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -133,17 +186,17 @@ const (
 )
 
 func (i *Implementation) OrderList(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	offsetStr := strings.TrimPrefix(update.CallbackQuery.Data, getOrderListHandler.String())
 	oldMsg := update.CallbackQuery.Message.Message
 
-	var offset int64
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.ParseInt(offsetStr, 10, 64)
-		if err != nil {
-			sendErrorMsg(ctx, bot, oldMsg, err, "")
-			return
-		}
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
 	}
 
 	userID, err := getUserIDFromContext(ctx)
@@ -156,7 +209,7 @@ func (i *Implementation) OrderList(ctx context.Context, bot *telegramBot.Bot, up
 	orders, err := i.orderUseCase.GetOrderList(ctx, userID, domainOrder.RequestList{
 		Pagination: &primitives.Pagination{
 			Num:    uint64(cardsNum),
-			Offset: uint64(offset) * uint64(cardsNum),
+			Offset: offset * uint64(cardsNum),
 		},
 		Filters: &domainOrder.Filters{
 			UserID: userID,
@@ -178,20 +231,22 @@ func (i *Implementation) OrderList(ctx context.Context, bot *telegramBot.Bot, up
 		})
 	}
 
+	lang := getLangFromContext(ctx)
 	list := paginatorHandlerList{
-		nextHandler:        getOrderHandler.String(),
-		curHandler:         getOrderListHandler.String(),
+		nextHandler:        getOrderHandler,
+		curHandler:         getOrderListHandler,
 		maxProductsColumns: maxOrdersColumns,
+		lang:               lang,
 	}
 
 	keyboard := list.paginationKeyboard(pag, primitives.Pagination{
 		Num:    uint64(cardsNum),
-		Offset: uint64(offset),
+		Offset: offset,
 	})
 
 	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			Text:         "Назад",
+			Text:         lang.T(locale.BackButton),
 			CallbackData: profileHandler.GetBackHandler().String(),
 		},
 	})
@@ -199,7 +254,7 @@ func (i *Implementation) OrderList(ctx context.Context, bot *telegramBot.Bot, up
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text:         "Страница заказов.",
+		Text:         lang.T(locale.OrdersText),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
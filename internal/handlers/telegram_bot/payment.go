@@ -3,7 +3,6 @@ package telegram_bot
 import (
 	"context"
 	"errors"
-	"strings"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -15,9 +14,8 @@ import (
 func (i *Implementation) CreateInvoice(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
 
-	strID := strings.TrimPrefix(update.CallbackQuery.Data, createInvoice.String())
-	if strID == "" {
-		sendErrorMsg(ctx, bot, oldMsg, errors.New("error parse productID"), "")
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
 		return
 	}
 
@@ -0,0 +1,408 @@
+package telegram_bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainPrivacy "github.com/kdv2001/onlySubscription/internal/domain/privacy"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+)
+
+// exportUserDataForm форма выгрузки данных пользователя администратором
+var exportUserDataForm = &dialog.Form{
+	Name:  "export_user_data",
+	Title: string(locale.ExportUserDataFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+	},
+}
+
+// deleteUserDataForm форма удаления данных пользователя администратором
+var deleteUserDataForm = &dialog.Form{
+	Name:  "delete_user_data",
+	Title: string(locale.DeleteUserDataFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+	},
+}
+
+// GetMyData отправляет пользователю выгрузку его данных в JSON
+func (i *Implementation) GetMyData(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	msg := update.Message
+	lang := getLangFromContext(ctx)
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	export, err := i.privacyUseCase.Export(ctx, userID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	if err = sendExport(ctx, bot, msg.Chat.ID, export, lang.T(locale.MyDataCaption)); err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+}
+
+// GetDeleteMeInfo описывает последствия удаления данных и просит подтверждения
+func (i *Implementation) GetDeleteMeInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	lang := getLangFromContext(ctx)
+
+	sender := msgInlineSender{
+		ChatID: update.Message.Chat.ID,
+		Text:   lang.T(locale.DeleteMeText),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.DeleteMeButton),
+						CallbackData: confirmDeleteMeHandler.Data().ExpiresIn(confirmTTL).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.CancelButton),
+						CallbackData: confirmDeleteMeHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// DeleteMe удаляет данные пользователя после подтверждения
+func (i *Implementation) DeleteMe(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	// подтверждение действует ограниченное время
+	if _, ok := parseCallback(ctx, bot, update); !ok {
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	_, err = i.privacyUseCase.Delete(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domainPrivacy.ErrStaffDeletion) {
+			sendErrorMsg(ctx, bot, oldMsg, err, lang.T(locale.DeleteMeStaff))
+			return
+		}
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	// после удаления бот не узнает пользователя, поэтому кнопки меню не показываются
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.DeleteMeDone),
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetUserDataInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	lang := getLangFromContext(ctx)
+	sender := msgInlineSender{
+		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
+		CurMessageID: update.CallbackQuery.Message.Message.ID,
+		Text:         lang.T(locale.UserDataInfoText),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ExportUserDataButton),
+						CallbackData: exportUserDataHandler.String(),
+					},
+					{
+						Text:         lang.T(locale.DeleteUserDataButton),
+						CallbackData: deleteUserDataHandler.String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: userDataInfoHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetExportUserDataInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, exportUserDataForm, nil)
+}
+
+func (i *Implementation) GetDeleteUserDataInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, deleteUserDataForm, nil)
+}
+
+// exportUserData отправляет выгрузку данных пользователя из заполненной формы
+func (i *Implementation) exportUserData(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	user, ok := i.userFromForm(ctx, bot, msg, s)
+	if !ok {
+		return
+	}
+
+	export, err := i.privacyUseCase.Export(ctx, user.ID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	caption := getLangFromContext(ctx).T(locale.UserDataCaption, user.Contact.TelegramID)
+	if err = sendExport(ctx, bot, msg.Chat.ID, export, caption); err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+}
+
+// deleteUserData удаляет данные пользователя из заполненной формы
+func (i *Implementation) deleteUserData(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	user, ok := i.userFromForm(ctx, bot, msg, s)
+	if !ok {
+		return
+	}
+
+	deletion, err := i.privacyUseCase.Delete(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, domainPrivacy.ErrStaffDeletion) {
+			sendErrorMsg(ctx, bot, msg, err, lang.T(locale.StaffDeletion))
+			return
+		}
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	text := lang.T(locale.UserDataDeleted, user.Contact.TelegramID)
+	if deletion.CancelledOrders != 0 {
+		text += lang.T(locale.UserOrdersCancelled, deletion.CancelledOrders)
+	}
+
+	sendToAdminMenu(ctx, bot, msg, text)
+}
+
+// userFromForm возвращает пользователя по telegram ID из заполненной формы.
+// При ошибке пользователю уже отправлено сообщение, ok=false
+func (i *Implementation) userFromForm(ctx context.Context,
+	bot *telegramBot.Bot,
+	msg *models.Message,
+	s dialog.Session,
+) (user domainUser.User, ok bool) {
+	tgID, err := strconv.ParseInt(s.Value("tg"), 10, 64)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, getLangFromContext(ctx).T(locale.InvalidTelegramID))
+		return domainUser.User{}, false
+	}
+
+	user, err = i.userUseCase.GetUserByTelegramID(ctx, domainUser.NewTelegramID(tgID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return domainUser.User{}, false
+	}
+
+	return user, true
+}
+
+// sendExport отправляет выгрузку данных пользователя JSON-файлом
+func sendExport(ctx context.Context,
+	bot *telegramBot.Bot,
+	chatID int64,
+	export domainPrivacy.Export,
+	caption string,
+) error {
+	data, err := json.MarshalIndent(newExportJSON(export), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendDocument(ctx, &telegramBot.SendDocumentParams{
+		ChatID: chatID,
+		Document: &models.InputFileUpload{
+			Filename: fmt.Sprintf("data_%s.json", export.CreatedAt.Format(time.DateOnly)),
+			Data:     bytes.NewReader(data),
+		},
+		Caption: caption,
+	})
+
+	return err
+}
+
+type exportJSON struct {
+	ExportedAt    time.Time          `json:"exported_at"`
+	Profile       profileJSON        `json:"profile"`
+	Orders        []orderJSON        `json:"orders"`
+	Invoices      []invoiceJSON      `json:"invoices"`
+	Subscriptions []subscriptionJSON `json:"subscriptions"`
+}
+
+type profileJSON struct {
+	ID               string     `json:"id"`
+	TelegramID       string     `json:"telegram_id"`
+	ChatID           int64      `json:"chat_id"`
+	Username         string     `json:"username,omitempty"`
+	FirstName        string     `json:"first_name,omitempty"`
+	LastName         string     `json:"last_name,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	State            string     `json:"state"`
+	Language         string     `json:"language,omitempty"`
+	TelegramLanguage string     `json:"telegram_language,omitempty"`
+	UnreachableAt    *time.Time `json:"unreachable_at,omitempty"`
+	Ban              *banJSON   `json:"ban,omitempty"`
+}
+
+type banJSON struct {
+	Reason    string     `json:"reason"`
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type orderJSON struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Product   string    `json:"product"`
+	Tariff    string    `json:"tariff,omitempty"`
+	Price     string    `json:"price"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type invoiceJSON struct {
+	ID            string    `json:"id"`
+	OrderID       string    `json:"order_id"`
+	State         string    `json:"state"`
+	PaymentMethod string    `json:"payment_method"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	ProviderID    string    `json:"provider_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type subscriptionJSON struct {
+	ID          string     `json:"id"`
+	OrderID     string     `json:"order_id"`
+	ProductID   string     `json:"product_id"`
+	TariffID    string     `json:"tariff_id,omitempty"`
+	Description string     `json:"description"`
+	State       string     `json:"state"`
+	Deadline    time.Time  `json:"deadline"`
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
+	GraceUntil  *time.Time `json:"grace_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// optionalTime нулевое время в выгрузке не выводится
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func newExportJSON(e domainPrivacy.Export) exportJSON {
+	u := e.User
+	res := exportJSON{
+		ExportedAt: e.CreatedAt,
+		Profile: profileJSON{
+			ID:               u.ID.String(),
+			TelegramID:       u.Contact.TelegramID.String(),
+			ChatID:           u.Contact.TelegramBotChatID,
+			Username:         u.Contact.TelegramUsername,
+			FirstName:        u.Contact.FirstName,
+			LastName:         u.Contact.LastName,
+			Role:             u.Role.String(),
+			State:            u.State.String(),
+			Language:         u.Language.String(),
+			TelegramLanguage: u.TelegramLanguage,
+			UnreachableAt:    optionalTime(u.UnreachableAt),
+		},
+		Orders:        make([]orderJSON, 0, len(e.Orders)),
+		Invoices:      make([]invoiceJSON, 0, len(e.Invoices)),
+		Subscriptions: make([]subscriptionJSON, 0, len(e.Subscriptions)),
+	}
+
+	if u.State == domainUser.BlockedState {
+		res.Profile.Ban = &banJSON{
+			Reason:    u.Ban.Reason,
+			Until:     optionalTime(u.Ban.Until),
+			CreatedAt: u.Ban.CreatedAt,
+		}
+	}
+
+	for _, o := range e.Orders {
+		res.Orders = append(res.Orders, orderJSON{
+			ID:        o.ID.String(),
+			Status:    o.Status.String(),
+			Product:   o.Product.Title,
+			Tariff:    o.Product.TariffName,
+			Price:     o.TotalPrice.Value.String(),
+			Currency:  o.TotalPrice.Currency.String(),
+			CreatedAt: o.CreatedAt,
+			UpdatedAt: o.UpdatedAt,
+		})
+	}
+
+	for _, inv := range e.Invoices {
+		res.Invoices = append(res.Invoices, invoiceJSON{
+			ID:            inv.ID.String(),
+			OrderID:       inv.OrderID.String(),
+			State:         inv.State.String(),
+			PaymentMethod: inv.PaymentMethod.String(),
+			Amount:        inv.Price.Value.String(),
+			Currency:      inv.Price.Currency.String(),
+			ProviderID:    inv.ProviderID.String(),
+			CreatedAt:     inv.CreatedAt,
+			UpdatedAt:     inv.UpdatedAt,
+		})
+	}
+
+	for _, s := range e.Subscriptions {
+		res.Subscriptions = append(res.Subscriptions, subscriptionJSON{
+			ID:          s.ID.String(),
+			OrderID:     s.OrderID.String(),
+			ProductID:   s.ProductID.String(),
+			TariffID:    s.TariffID.String(),
+			Description: s.Description,
+			State:       s.State.String(),
+			Deadline:    s.Deadline,
+			PausedAt:    optionalTime(s.PausedAt),
+			GraceUntil:  optionalTime(s.GraceUntil),
+			CreatedAt:   s.CreatedAt,
+			UpdatedAt:   s.UpdatedAt,
+		})
+	}
+
+	return res
+}
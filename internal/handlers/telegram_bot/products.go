@@ -2,40 +2,39 @@ package telegram_bot
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
 )
 
 func (i *Implementation) GetProducts(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
-	l := strings.TrimPrefix(update.CallbackQuery.Data, productsHandler.String())
 	oldMsg := update.CallbackQuery.Message.Message
 
-	var offset int64
-	if l != "" {
-		var err error
-		offset, err = strconv.ParseInt(l, 10, 64)
-		if err != nil {
-			sendErrorMsg(ctx, bot, oldMsg, err, "")
-			return
-		}
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
 	}
 
 	cardsNum := maxProductsLines * maxProductsColumns
 	products, err := i.productsUseCase.GetProducts(ctx, domainProducts.RequestList{
 		Filters: &domainProducts.Filters{
-			ItemsExist: true,
+			ItemsExist:  true,
+			PublishedAt: time.Now().UTC(),
 		},
 		Pagination: &primitives.Pagination{
 			Num:    maxProductsLines * maxProductsColumns,
-			Offset: uint64(offset) * uint64(cardsNum),
+			Offset: offset * uint64(cardsNum),
 		},
 	})
 	if err != nil {
@@ -43,28 +42,31 @@ func (i *Implementation) GetProducts(ctx context.Context, bot *telegramBot.Bot,
 		return
 	}
 
-	pag := make([]*paginatorItem, 0, len(products))
-	for _, curItem := range products {
+	lang := getLangFromContext(ctx)
+	pag := make([]*paginatorItem, 0, len(products.Products))
+	for _, curItem := range products.Products {
 		pag = append(pag, &paginatorItem{
 			id:   curItem.ID.String(),
-			name: curItem.Name,
+			name: curItem.Localized(lang).Name + formatAvailable(curItem),
 		})
 	}
 
 	list := paginatorHandlerList{
-		nextHandler:        productHandler.String(),
-		curHandler:         productsHandler.String(),
+		nextHandler:        productHandler,
+		curHandler:         productsHandler,
 		maxProductsColumns: maxProductsColumns,
+		total:              products.Total,
+		lang:               lang,
 	}
 
 	keyboard := list.paginationKeyboard(pag, primitives.Pagination{
 		Num:    uint64(cardsNum),
-		Offset: uint64(offset),
+		Offset: offset,
 	})
 
 	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			Text:         "Назад",
+			Text:         lang.T(locale.BackButton),
 			CallbackData: productsHandler.GetBackHandler().String(),
 		},
 	})
@@ -72,7 +74,7 @@ func (i *Implementation) GetProducts(ctx context.Context, bot *telegramBot.Bot,
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text:         "Выбирай товар и переходи к подробному описанию.",
+		Text:         lang.T(locale.ProductsText, offset+1, pagesNum(products.Total, uint64(cardsNum))),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -84,9 +86,8 @@ func (i *Implementation) GetProducts(ctx context.Context, bot *telegramBot.Bot,
 func (i *Implementation) GetProduct(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
 
-	productIDStr := strings.TrimPrefix(update.CallbackQuery.Data, productHandler.String())
-	if productIDStr == "" {
-		sendErrorMsg(ctx, bot, oldMsg, errors.New("error parse productID"), "")
+	productIDStr, ok := callbackID(ctx, bot, update)
+	if !ok {
 		return
 	}
 
@@ -97,30 +98,33 @@ func (i *Implementation) GetProduct(ctx context.Context, bot *telegramBot.Bot, u
 		return
 	}
 
-	keyboard := [][]models.InlineKeyboardButton{
-		{
+	lang := getLangFromContext(ctx)
+	product = product.Localized(lang)
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(product.Tariffs)+1)
+	for _, t := range product.Tariffs {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
 			{
-				Text:         "Забронировать",
-				CallbackData: fmt.Sprint(createOrder, productID),
+				Text:         formatTariff(lang, t),
+				CallbackData: createOrder.Data().ID(t.ID.String()).String(),
 				Pay:          true,
 			},
-		},
+		})
+	}
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
 		{
-			{
-				Text:         "Назад",
-				CallbackData: productHandler.GetBackHandler().String(),
-			},
+			Text:         lang.T(locale.BackButton),
+			CallbackData: productHandler.GetBackHandler().String(),
 		},
-	}
+	})
 
 	sender := msgInlineSender{
 		ChatID:       update.CallbackQuery.Message.Message.Chat.ID,
 		CurMessageID: update.CallbackQuery.Message.Message.ID,
-		Text: fmt.Sprintf("Товар: %s\nописание: %s\n\n цена: %s %s",
+		Text: lang.T(locale.ProductText,
 			product.Name,
 			product.Description,
-			product.Price.Value,
-			currencyToIcon(product.Price.Currency)),
+			formatAvailableNum(lang, product)),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: keyboard,
 		},
@@ -0,0 +1,82 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainReferral "github.com/kdv2001/onlySubscription/internal/domain/referral"
+)
+
+// GetReferral показывает реферальную ссылку пользователя и статистику приглашений
+func (i *Implementation) GetReferral(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	stats, err := i.referralUseCase.GetStats(ctx, userID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", me.Username, stats.Code.StartParam())
+	text := lang.T(locale.ReferralText,
+		link,
+		formatReferralReward(lang, i.referralUseCase.Policy()),
+		stats.Invited,
+		stats.PaidOrders)
+	if stats.BonusDays != 0 {
+		text += lang.T(locale.ReferralBonusDays, stats.BonusDays)
+	}
+	if stats.PendingDays != 0 {
+		text += lang.T(locale.ReferralPendingDays, stats.PendingDays)
+	}
+	if stats.Discounts != 0 {
+		text += lang.T(locale.ReferralDiscounts, stats.Discounts)
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: referralHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// formatReferralReward описывает награду за приглашение
+func formatReferralReward(lang locale.Lang, p domainReferral.Policy) string {
+	switch p.Kind {
+	case domainReferral.DaysReward:
+		return lang.T(locale.ReferralRewardDays, p.Days, lang.Plural(p.Days, locale.DayUnit))
+	case domainReferral.DiscountReward:
+		return lang.T(locale.ReferralRewardDiscount, p.DiscountPercent)
+	}
+
+	return lang.T(locale.ReferralNoReward)
+}
@@ -0,0 +1,130 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+// roleForm форма назначения роли сотрудника
+var roleForm = &dialog.Form{
+	Name:  "set_role",
+	Title: string(locale.RoleFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+		{Field: "role", Title: string(locale.RoleField), Hint: string(locale.RoleHint), Validate: validateRole},
+	},
+}
+
+func (i *Implementation) GetStaff(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	staff, err := i.userUseCase.GetStaff(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	lines := make([]string, 0, len(staff))
+	for _, u := range staff {
+		// ID личного чата с ботом совпадает с ID пользователя в телеграм
+		line := fmt.Sprintf("%d - %s", u.Contact.TelegramBotChatID, u.Role)
+		if name := u.Contact.DisplayName(); name != "" {
+			line = fmt.Sprintf("%d (%s) - %s", u.Contact.TelegramBotChatID, name, u.Role)
+		}
+		lines = append(lines, line)
+	}
+
+	lang := getLangFromContext(ctx)
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.StaffText, strings.Join(lines, "\n")),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.AssignRoleButton),
+						CallbackData: setRoleHandler.String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: staffHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetSetRoleInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, roleForm, nil)
+}
+
+// setRole назначает или снимает роль сотрудника по заполненной форме
+func (i *Implementation) setRole(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	tgID, err := strconv.ParseInt(s.Value("tg"), 10, 64)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, lang.T(locale.InvalidTelegramID))
+		return
+	}
+
+	role, err := domainUser.RoleFromString(s.Value("role"))
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, custom_errors.NewBadRequestError(err), lang.T(locale.InvalidRole))
+		return
+	}
+
+	actorID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	_, err = i.userUseCase.SetRole(ctx, actorID, domainUser.NewTelegramID(tgID), role)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			sendErrorMsg(ctx, bot, msg, err, lang.T(locale.UserNotFound))
+			return
+		}
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	text := lang.T(locale.RoleAssigned, tgID, role)
+	if role == domainUser.CustomerRole {
+		text = lang.T(locale.RoleRemoved, tgID)
+	}
+
+	sender := msgInlineSender{
+		ChatID: msg.Chat.ID,
+		Text:   text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ToStaffButton),
+						CallbackData: staffHandler.String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
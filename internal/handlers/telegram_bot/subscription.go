@@ -0,0 +1,360 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	domainSubscription "github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// maxRenewalsLines кол-во продлений в истории
+const maxRenewalsLines = 10
+
+func (i *Implementation) GetSubscriptionHistory(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sub, err := i.subscriptionUseCase.GetUserSubscription(ctx, domainSubscription.NewID(strID), userID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	renewals, err := i.subscriptionUseCase.GetRenewals(ctx, sub.ID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	lang := getLangFromContext(ctx)
+	lines := make([]string, 0, len(renewals))
+	for j, r := range renewals {
+		if j == maxRenewalsLines {
+			lines = append(lines, lang.T(locale.RenewalsMore, len(renewals)-maxRenewalsLines))
+			break
+		}
+
+		lines = append(lines, lang.T(locale.RenewalItem,
+			r.CreatedAt.Format(consts.DateTimeLayout), formatRenewal(r), r.OrderID))
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text: lang.T(locale.RenewalsText,
+			sub.Deadline.Format(consts.DateTimeLayout),
+			sub.OrderID,
+			strings.Join(lines, "\n\n")),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: subscriptionHistoryHandler.GetBackHandler().Data().ID(sub.ID.String()).String(),
+					},
+				},
+			},
+		},
+	}
+	sender.sendInlineMsg(ctx, bot)
+}
+
+const (
+	maxSubscriptionsLines   = 5
+	maxSubscriptionsColumns = 1
+)
+
+func (i *Implementation) GetSubscriptions(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	cardsNum := maxSubscriptionsLines * maxSubscriptionsColumns
+	subscriptions, err := i.subscriptionUseCase.GetUserSubscriptions(ctx, userID, primitives.Pagination{
+		Num:    uint64(cardsNum),
+		Offset: offset * uint64(cardsNum),
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	now := time.Now().UTC()
+	lang := getLangFromContext(ctx)
+	names := make(map[domainProducts.ID]string, len(subscriptions))
+	pag := make([]*paginatorItem, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		name, ok := names[s.ProductID]
+		if !ok {
+			name = i.productName(ctx, lang, s.ProductID)
+			names[s.ProductID] = name
+		}
+
+		pag = append(pag, &paginatorItem{
+			id: s.ID.String(),
+			name: fmt.Sprintf("%s %s · %s", subscriptionStateIcon(s, now), name,
+				formatRemaining(lang, s.Deadline, now)),
+		})
+	}
+
+	list := paginatorHandlerList{
+		nextHandler:        getSubscriptionHandler,
+		curHandler:         subscriptionListHandler,
+		maxProductsColumns: maxSubscriptionsColumns,
+		lang:               lang,
+	}
+
+	keyboard := list.paginationKeyboard(pag, primitives.Pagination{
+		Num:    uint64(cardsNum),
+		Offset: offset,
+	})
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
+		{
+			Text:         lang.T(locale.BackButton),
+			CallbackData: subscriptionListHandler.GetBackHandler().String(),
+		},
+	})
+
+	text := lang.T(locale.SubscriptionList)
+	if len(subscriptions) == 0 && offset == 0 {
+		text = lang.T(locale.NoSubscriptions)
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		},
+	}
+	sender.sendInlineMsg(ctx, bot)
+}
+
+// productName возвращает название продукта на языке lang для списков, при ошибке - ID продукта
+func (i *Implementation) productName(ctx context.Context, lang locale.Lang, id domainProducts.ID) string {
+	product, err := i.productsUseCase.GetProduct(ctx, id)
+	if err != nil {
+		logger.Errorf(ctx, "error get product %s: %v", id, err)
+		return id.String()
+	}
+
+	return product.Localized(lang).Name
+}
+
+func (i *Implementation) GetSubscription(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sub, err := i.subscriptionUseCase.GetUserSubscription(ctx, domainSubscription.NewID(strID), userID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	tariff, err := i.productsUseCase.GetTariff(ctx, sub.TariffID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	now := time.Now().UTC()
+	lang := getLangFromContext(ctx)
+	remaining := formatRemaining(lang, sub.Deadline, now)
+	switch sub.State {
+	case domainSubscription.PausedState:
+		// на паузе оставшееся время заморожено
+		remaining = formatRemaining(lang, sub.Deadline, sub.PausedAt)
+	case domainSubscription.GraceState:
+		remaining = lang.T(locale.UntilAccessEnd, formatRemaining(lang, sub.GraceUntil, now))
+	}
+
+	var accessText string
+	if sub.AccessChatID != 0 && sub.HasAccess(now) {
+		access, errA := i.subscriptionUseCase.GetChatAccess(ctx, sub.ID)
+		switch {
+		case errA == nil && !access.IsRevoked():
+			accessText = lang.T(locale.AccessChatLink, access.InviteLink)
+		case errA != nil && !errors.Is(errA, custom_errors.ErrorNotFound):
+			sendErrorMsg(ctx, bot, oldMsg, errA, "")
+			return
+		}
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text: lang.T(locale.SubscriptionText,
+			i.productName(ctx, lang, sub.ProductID),
+			tariff.Name,
+			formatSubscriptionState(lang, sub, now),
+			sub.Deadline.Format(consts.DateTimeLayout),
+			remaining) + accessText,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.RenewButton),
+						CallbackData: renewHandler.Data().ID(sub.TariffID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.ShowKeyButton),
+						CallbackData: subscriptionKeyHandler.Data().ID(sub.ID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.RenewalHistory),
+						CallbackData: subscriptionHistoryHandler.Data().ID(sub.ID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: getSubscriptionHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetSubscriptionKey(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sub, err := i.subscriptionUseCase.GetUserSubscription(ctx, domainSubscription.NewID(strID), userID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	renewals, err := i.subscriptionUseCase.GetRenewals(ctx, sub.ID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	// актуальная полезная нагрузка выдана последним заказом продления
+	orderID := sub.OrderID
+	if len(renewals) != 0 {
+		orderID = renewals[0].OrderID
+	}
+
+	lang := getLangFromContext(ctx)
+	payload, err := i.orderUseCase.RevealOrderPayload(ctx, orderID, userID)
+	if err != nil {
+		if errors.Is(err, domainProducts.ErrPayloadPurged) {
+			sendErrorMsg(ctx, bot, oldMsg, err, lang.T(locale.PayloadPurged))
+			return
+		}
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.KeyText, orderID, payload),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: subscriptionKeyHandler.GetBackHandler().Data().ID(sub.ID.String()).String(),
+					},
+				},
+			},
+		},
+	}
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func subscriptionStateIcon(s domainSubscription.Subscription, now time.Time) string {
+	switch {
+	case s.State == domainSubscription.PausedState:
+		return "⏸"
+	case s.State == domainSubscription.RevokedState:
+		return "⛔"
+	case s.State == domainSubscription.GraceState && s.HasAccess(now):
+		return "⚠️"
+	case s.HasAccess(now):
+		return "✅"
+	}
+
+	return "⌛"
+}
+
+// formatSubscriptionState форматирует статус подписки к моменту now
+func formatSubscriptionState(lang locale.Lang, s domainSubscription.Subscription, now time.Time) string {
+	switch {
+	case s.State == domainSubscription.PausedState:
+		return lang.T(locale.StatePaused)
+	case s.State == domainSubscription.RevokedState:
+		return lang.T(locale.StateRevoked)
+	case s.State == domainSubscription.GraceState && s.HasAccess(now):
+		return lang.T(locale.StateGrace, s.GraceUntil.Format(consts.DateTimeLayout))
+	case s.HasAccess(now):
+		return lang.T(locale.StateActive)
+	}
+
+	return lang.T(locale.StateExpired)
+}
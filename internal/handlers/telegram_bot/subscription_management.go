@@ -0,0 +1,257 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainSubscription "github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+)
+
+const (
+	// maxManagedSubscriptions кол-во подписок пользователя в результатах поиска
+	maxManagedSubscriptions = 15
+	// maxChangesLines кол-во изменений в карточке подписки
+	maxChangesLines = 5
+)
+
+// findSubscriptionsForm форма поиска подписок пользователя
+var findSubscriptionsForm = &dialog.Form{
+	Name:  "find_subscriptions",
+	Title: string(locale.FindSubscriptionsFormTitle),
+	Steps: []dialog.Step{
+		{Field: "tg", Title: string(locale.TelegramIDField), Hint: string(locale.TelegramIDHint),
+			Validate: validateTelegramID},
+	},
+}
+
+// changeSubscriptionForm форма изменения подписки администратором, кол-во дней нужно только для продления
+var changeSubscriptionForm = &dialog.Form{
+	Name:  "change_subscription",
+	Title: string(locale.ChangeSubscriptionFormTitle),
+	Steps: []dialog.Step{
+		{Field: "action", Title: string(locale.ActionField), Hint: string(locale.ActionHint),
+			Validate: validateAction},
+		{Field: "days", Title: string(locale.DaysField), Hint: string(locale.DaysHint), Optional: true,
+			Validate: validateCount},
+		{Field: "reason", Title: string(locale.ReasonField)},
+	},
+}
+
+func (i *Implementation) GetSubscriptionSearchInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	i.startDialog(ctx, bot, update.CallbackQuery, findSubscriptionsForm, nil)
+}
+
+// findSubscriptions показывает подписки пользователя из заполненной формы
+func (i *Implementation) findSubscriptions(ctx context.Context,
+	bot *telegramBot.Bot,
+	oldMsg *models.Message,
+	s dialog.Session,
+) {
+	lang := getLangFromContext(ctx)
+	tgID, err := strconv.ParseInt(s.Value("tg"), 10, 64)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, lang.T(locale.InvalidTelegramID))
+		return
+	}
+
+	user, err := i.userUseCase.GetUserByTelegramID(ctx, domainUser.NewTelegramID(tgID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	subscriptions, err := i.subscriptionUseCase.GetUserSubscriptions(ctx, user.ID, primitives.Pagination{
+		Num: maxManagedSubscriptions,
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	now := time.Now().UTC()
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(subscriptions)+1)
+	for _, sub := range subscriptions {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{
+				Text: lang.T(locale.ManagedSubscription, subscriptionStateIcon(sub, now),
+					i.productName(ctx, locale.Default, sub.ProductID), sub.Deadline.Format(consts.DateTimeLayout)),
+				CallbackData: manageSubscriptionHandler.Data().ID(sub.ID.String()).String(),
+			},
+		})
+	}
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
+		{
+			Text:         lang.T(locale.BackButton),
+			CallbackData: subscriptionSearchInfoHandler.String(),
+		},
+	})
+
+	text := lang.T(locale.UserSubscriptions, tgID)
+	if len(subscriptions) == 0 {
+		text = lang.T(locale.UserNoSubscriptions, tgID)
+	}
+
+	sender := msgInlineSender{
+		ChatID: oldMsg.Chat.ID,
+		Text:   text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) ManageSubscription(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	sub, err := i.subscriptionUseCase.GetSubscription(ctx, domainSubscription.NewID(strID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	changes, err := i.subscriptionUseCase.GetChanges(ctx, sub.ID)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	lang := getLangFromContext(ctx)
+	now := time.Now().UTC()
+	text := lang.T(locale.ManagedSubscriptionText,
+		i.productName(ctx, locale.Default, sub.ProductID),
+		sub.UserID.String(),
+		formatSubscriptionState(lang, sub, now),
+		sub.Deadline.Format(consts.DateTimeLayout),
+	)
+	if !sub.PausedAt.IsZero() {
+		text += lang.T(locale.ManagedPausedText, sub.PausedAt.Format(consts.DateTimeLayout))
+	}
+
+	if len(changes) != 0 {
+		lines := make([]string, 0, min(len(changes), maxChangesLines))
+		for j, c := range changes {
+			if j == maxChangesLines {
+				break
+			}
+			lines = append(lines, formatSubscriptionChange(lang, c))
+		}
+		text += lang.T(locale.ManagedChangesText, strings.Join(lines, "\n"))
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ChangeSubscriptionButton),
+						CallbackData: changeSubscriptionHandler.Data().ID(sub.ID.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: manageSubscriptionHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetChangeSubscriptionInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, changeSubscriptionForm, map[string]string{
+		subscriptionParam: strID,
+	})
+}
+
+// changeSubscription изменяет подписку по заполненной форме
+func (i *Implementation) changeSubscription(ctx context.Context,
+	bot *telegramBot.Bot,
+	msg *models.Message,
+	s dialog.Session,
+) {
+	lang := getLangFromContext(ctx)
+	action := domainSubscription.AdminActionFromString(s.Value("action"))
+	if action == domainSubscription.UnknownAction {
+		sendErrorMsg(ctx, bot, msg, domainSubscription.ErrUnknownAction, lang.T(locale.InvalidAction))
+		return
+	}
+
+	var days int
+	if action == domainSubscription.ExtendAction {
+		var err error
+		days, err = strconv.Atoi(s.Value("days"))
+		if err != nil {
+			sendErrorMsg(ctx, bot, msg, err, lang.T(locale.DaysRequired))
+			return
+		}
+	}
+
+	adminID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sub, err := i.subscriptionUseCase.ChangeSubscription(ctx, domainSubscription.ChangeRequest{
+		SubscriptionID: domainSubscription.NewID(s.Param(subscriptionParam)),
+		Action:         action,
+		Days:           days,
+		Reason:         s.Value("reason"),
+		AdminID:        adminID,
+	})
+	if err != nil {
+		if errors.Is(err, domainSubscription.ErrSubscriptionChanged) {
+			sendErrorMsg(ctx, bot, msg, err, lang.T(locale.SubscriptionChangedRace))
+			return
+		}
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID: msg.Chat.ID,
+		Text: lang.T(locale.SubscriptionChanged,
+			formatSubscriptionState(lang, sub, time.Now().UTC()), sub.Deadline.Format(consts.DateTimeLayout)),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ToSubscriptionButton),
+						CallbackData: manageSubscriptionHandler.Data().ID(sub.ID.String()).String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
@@ -0,0 +1,86 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/pkg/dialog"
+)
+
+// translationForm форма перевода продукта, без названия перевод удаляется, без описания показывается исходное
+var translationForm = &dialog.Form{
+	Name:  "set_translation",
+	Title: string(locale.TranslationFormTitle),
+	Steps: []dialog.Step{
+		{Field: "lang", Title: string(locale.LanguageField), Hint: string(locale.LanguageHint),
+			Validate: validateLanguage},
+		{Field: "name", Title: string(locale.NameField), Hint: string(locale.TranslationNameHint), Optional: true},
+		{Field: "desc", Title: string(locale.DescriptionField), Hint: string(locale.TranslationDescHint),
+			Optional: true},
+	},
+}
+
+// formatTranslations форматирует переводы продукта в порядке языков
+func formatTranslations(lang locale.Lang, translations domainProducts.Translations) string {
+	if len(translations) == 0 {
+		return lang.T(locale.NoValue)
+	}
+
+	lines := make([]string, 0, len(translations))
+	for _, t := range translations {
+		lines = append(lines, fmt.Sprintf("%s: %s", t.Lang, t.Name))
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n")
+}
+
+func (i *Implementation) GetTranslationInfo(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	i.startDialog(ctx, bot, update.CallbackQuery, translationForm, map[string]string{
+		productParam: strID,
+	})
+}
+
+// setTranslation сохраняет или удаляет перевод продукта по заполненной форме
+func (i *Implementation) setTranslation(ctx context.Context, bot *telegramBot.Bot, msg *models.Message, s dialog.Session) {
+	lang := getLangFromContext(ctx)
+	translationLang, ok := locale.Parse(s.Value("lang"))
+	if !ok {
+		sendErrorMsg(ctx, bot, msg, errors.New("unknown language "+s.Value("lang")), lang.T(locale.InvalidLanguage))
+		return
+	}
+
+	var err error
+	productID := domainProducts.NewID(s.Param(productParam))
+	text := lang.T(locale.TranslationSaved)
+	if s.Value("name") == "" {
+		err = i.productsUseCase.DeleteTranslation(ctx, productID, translationLang)
+		text = lang.T(locale.TranslationDeleted)
+	} else {
+		err = i.productsUseCase.SetTranslation(ctx, domainProducts.Translation{
+			ProductID:   productID,
+			Lang:        translationLang,
+			Name:        s.Value("name"),
+			Description: s.Value("desc"),
+		})
+	}
+	if err != nil {
+		sendErrorMsg(ctx, bot, msg, err, "")
+		return
+	}
+
+	sendToProduct(ctx, bot, msg, productID, text)
+}
@@ -2,24 +2,45 @@ package telegram_bot
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 )
 
 func (i *Implementation) GetProfile(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 	results := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Список заказов",
+				Text:         lang.T(locale.ProfileOrders),
 				CallbackData: getOrderListHandler.String(),
 			},
 		},
 		{
 			{
-				Text:         "Назад",
+				Text:         lang.T(locale.ProfileSubscriptions),
+				CallbackData: subscriptionListHandler.String(),
+			},
+		},
+		{
+			{
+				Text:         lang.T(locale.ProfileLanguage),
+				CallbackData: languageHandler.String(),
+			},
+		},
+		{
+			{
+				Text:         lang.T(locale.ProfileReferral),
+				CallbackData: referralHandler.String(),
+			},
+		},
+		{
+			{
+				Text:         lang.T(locale.BackButton),
 				CallbackData: menu.GetBackHandler().String(),
 			},
 		},
@@ -28,7 +49,7 @@ func (i *Implementation) GetProfile(ctx context.Context, bot *telegramBot.Bot, u
 	sender := msgInlineSender{
 		ChatID:       oldMsg.Chat.ID,
 		CurMessageID: oldMsg.ID,
-		Text:         fmt.Sprintf("Твой userID: %d", oldMsg.From.ID),
+		Text:         lang.T(locale.ProfileText, oldMsg.From.ID),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: results,
 		},
@@ -39,10 +60,11 @@ func (i *Implementation) GetProfile(ctx context.Context, bot *telegramBot.Bot, u
 
 func (i *Implementation) GetHelp(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
 	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
 	results := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "Назад",
+				Text:         lang.T(locale.BackButton),
 				CallbackData: menu.GetBackHandler().String(),
 			},
 		},
@@ -51,7 +73,7 @@ func (i *Implementation) GetHelp(ctx context.Context, bot *telegramBot.Bot, upda
 	sender := msgInlineSender{
 		ChatID:       oldMsg.Chat.ID,
 		CurMessageID: oldMsg.ID,
-		Text:         "Подробное описание магазина",
+		Text:         lang.T(locale.HelpText),
 		Keyboard: &models.InlineKeyboardMarkup{
 			InlineKeyboard: results,
 		},
@@ -59,3 +81,86 @@ func (i *Implementation) GetHelp(ctx context.Context, bot *telegramBot.Bot, upda
 
 	sender.sendInlineMsg(ctx, bot)
 }
+
+// autoLanguage значение кнопки выбора языка по настройкам Telegram
+const autoLanguage = "auto"
+
+// GetLanguage показывает выбор языка интерфейса
+func (i *Implementation) GetLanguage(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	sendLanguageMenu(ctx, bot, update.CallbackQuery.Message.Message, getLangFromContext(ctx))
+}
+
+// SetLanguage сохраняет выбранный язык интерфейса, "auto" - язык из настроек Telegram
+func (i *Implementation) SetLanguage(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	code, err := data.Str(0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	lang, ok := locale.Parse(code)
+	if !ok && code != autoLanguage {
+		sendErrorMsg(ctx, bot, oldMsg, errors.New("unknown language "+code), "")
+		return
+	}
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	if err = i.userUseCase.SetLanguage(ctx, userID, lang); err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	if lang == "" {
+		lang = locale.FromTelegram(update.CallbackQuery.From.LanguageCode)
+	}
+
+	sendLanguageMenu(ctx, bot, oldMsg, lang)
+}
+
+func sendLanguageMenu(ctx context.Context, bot *telegramBot.Bot, oldMsg *models.Message, lang locale.Lang) {
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(locale.Langs)+2)
+	for _, l := range locale.Langs {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{
+				Text:         l.Name(),
+				CallbackData: setLanguageHandler.Data().Str(l.String()).String(),
+			},
+		})
+	}
+
+	keyboard = append(keyboard,
+		[]models.InlineKeyboardButton{
+			{
+				Text:         lang.T(locale.LanguageAuto),
+				CallbackData: setLanguageHandler.Data().Str(autoLanguage).String(),
+			},
+		},
+		[]models.InlineKeyboardButton{
+			{
+				Text:         lang.T(locale.BackButton),
+				CallbackData: languageHandler.GetBackHandler().String(),
+			},
+		})
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.LanguageText, lang.Name()),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
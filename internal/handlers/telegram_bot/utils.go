@@ -2,12 +2,12 @@ package telegram_bot
 
 import (
 	"context"
-	"fmt"
 
 	telegramBot "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/google/uuid"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	"github.com/kdv2001/onlySubscription/pkg/logger"
 )
@@ -43,9 +43,17 @@ func (msg *msgInlineSender) sendInlineMsg(ctx context.Context, bot *telegramBot.
 }
 
 type paginatorHandlerList struct {
-	nextHandler        string
-	curHandler         string
+	// nextHandler обработчик кнопки элемента, ID элемента передается первым аргументом
+	nextHandler handlerName
+	// curHandler обработчик кнопок страниц, номер страницы передается последним аргументом
+	curHandler handlerName
+	// curID ID, который передается обработчику страниц перед номером страницы, пустой - не передается
+	curID              string
 	maxProductsColumns int
+	// total общее кол-во элементов, 0 - неизвестно
+	total uint64
+	// lang язык кнопок, пустой - язык по умолчанию
+	lang locale.Lang
 }
 
 type paginatorItem struct {
@@ -73,7 +81,7 @@ func (p *paginatorHandlerList) paginationKeyboard(products []*paginatorItem,
 			pr := products[j*p.maxProductsColumns+k]
 			m = append(m, models.InlineKeyboardButton{
 				Text:         pr.GetName(),
-				CallbackData: fmt.Sprint(p.nextHandler, pr.GetID()),
+				CallbackData: p.nextHandler.Data().ID(pr.GetID()).String(),
 			})
 		}
 		results = append(results, m)
@@ -84,7 +92,7 @@ func (p *paginatorHandlerList) paginationKeyboard(products []*paginatorItem,
 		pr := products[len(products)-j]
 		m = append(m, models.InlineKeyboardButton{
 			Text:         pr.GetName(),
-			CallbackData: fmt.Sprint(p.nextHandler, pr.GetID()),
+			CallbackData: p.nextHandler.Data().ID(pr.GetID()).String(),
 		})
 	}
 	if len(m) > 0 {
@@ -95,16 +103,21 @@ func (p *paginatorHandlerList) paginationKeyboard(products []*paginatorItem,
 	if offset != 0 {
 		paginationButtons = append(paginationButtons,
 			models.InlineKeyboardButton{
-				Text:         "Пред.",
-				CallbackData: p.curHandler + fmt.Sprint(offset-1),
+				Text:         p.lang.T(locale.PrevButton),
+				CallbackData: p.pageData(offset - 1),
 			})
 	}
 
-	if uint64(len(products)) == cardsNum {
+	hasNext := uint64(len(products)) == cardsNum
+	if p.total != 0 {
+		hasNext = (offset+1)*cardsNum < p.total
+	}
+
+	if hasNext {
 		paginationButtons = append(paginationButtons,
 			models.InlineKeyboardButton{
-				Text:         "След.",
-				CallbackData: p.curHandler + fmt.Sprint(offset+1),
+				Text:         p.lang.T(locale.NextButton),
+				CallbackData: p.pageData(offset + 1),
 			})
 	}
 
@@ -115,7 +128,15 @@ func (p *paginatorHandlerList) paginationKeyboard(products []*paginatorItem,
 	return results
 }
 
-const defaultError = "Что-то пошло не так.\nПожалуйста, попробуйте снова и сообщите об этом администратору.\n\nКода ошибки: %s"
+// pageData данные кнопки перехода на страницу page
+func (p *paginatorHandlerList) pageData(page uint64) string {
+	b := p.curHandler.Data()
+	if p.curID != "" {
+		b.ID(p.curID)
+	}
+
+	return b.Int(int64(page)).String()
+}
 
 func sendErrorMsg(ctx context.Context,
 	bot *telegramBot.Bot,
@@ -128,8 +149,9 @@ func sendErrorMsg(ctx context.Context,
 		logger.Errorf(ctx, "uuid: %s; error: %v", errorUUID.String(), err)
 	}
 
+	lang := getLangFromContext(ctx)
 	if text == "" {
-		text = fmt.Sprintf(defaultError, errorUUID)
+		text = lang.T(locale.DefaultError, errorUUID)
 	}
 
 	_, err = bot.SendMessage(ctx, &telegramBot.SendMessageParams{
@@ -139,7 +161,7 @@ func sendErrorMsg(ctx context.Context,
 			InlineKeyboard: [][]models.InlineKeyboardButton{
 				{
 					{
-						Text:         "Меню",
+						Text:         lang.T(locale.MenuButton),
 						CallbackData: menu.String(),
 					},
 				},
@@ -0,0 +1,203 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+
+	telegramBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	domainWebhook "github.com/kdv2001/onlySubscription/internal/domain/webhook"
+)
+
+const (
+	maxDeliveriesLines   = 5
+	maxDeliveriesColumns = 1
+)
+
+func (i *Implementation) GetWebhookDeliveries(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	data, ok := parseCallback(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	offset, err := callbackPage(data, 0)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	cardsNum := maxDeliveriesLines * maxDeliveriesColumns
+	page, err := i.webhookUseCase.GetDeliveries(ctx, domainWebhook.RequestList{
+		Pagination: &primitives.Pagination{
+			Num:    uint64(cardsNum),
+			Offset: offset * uint64(cardsNum),
+		},
+	})
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	pag := make([]*paginatorItem, 0, len(page.Deliveries))
+	for _, d := range page.Deliveries {
+		pag = append(pag, &paginatorItem{
+			id: d.ID.String(),
+			name: fmt.Sprintf("%s %s → %s",
+				deliveryStatusIcon(d.Status), d.Event.Type, d.Endpoint),
+		})
+	}
+
+	paginator := paginatorHandlerList{
+		nextHandler:        getWebhookDeliveryHandler,
+		curHandler:         webhookListHandler,
+		maxProductsColumns: maxDeliveriesColumns,
+		total:              page.Total,
+	}
+
+	keyboard := paginator.paginationKeyboard(pag, primitives.Pagination{
+		Num:    uint64(cardsNum),
+		Offset: offset,
+	})
+
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
+		{
+			Text:         lang.T(locale.BackButton),
+			CallbackData: webhookListHandler.GetBackHandler().String(),
+		},
+	})
+
+	text := lang.T(locale.WebhooksText, offset+1, pagesNum(page.Total, uint64(cardsNum)))
+	if page.Total == 0 && offset == 0 {
+		text = lang.T(locale.WebhooksEmpty)
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         text,
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: keyboard,
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) GetWebhookDelivery(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+
+	d, err := i.webhookUseCase.GetDelivery(ctx, domainWebhook.NewDeliveryID(strID))
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	delivered := "-"
+	if !d.DeliveredAt.IsZero() {
+		delivered = d.DeliveredAt.Format(consts.DateTimeLayout)
+	}
+
+	lastError := d.LastError
+	if lastError == "" {
+		lastError = "-"
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text: lang.T(locale.WebhookDeliveryText,
+			d.ID,
+			d.Endpoint,
+			d.Event.Type,
+			d.Event.ID,
+			d.Status,
+			d.Attempts,
+			domainWebhook.MaxAttempts,
+			d.NextAttemptAt.Format(consts.DateTimeLayout),
+			delivered,
+			lastError,
+			formatEventData(d.Event.Data)),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ReplayButton),
+						CallbackData: replayWebhookHandler.Data().ID(d.ID.String()).ExpiresIn(confirmTTL).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: getWebhookDeliveryHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func (i *Implementation) ReplayWebhookDelivery(ctx context.Context, bot *telegramBot.Bot, update *models.Update) {
+	oldMsg := update.CallbackQuery.Message.Message
+	lang := getLangFromContext(ctx)
+
+	strID, ok := callbackID(ctx, bot, update)
+	if !ok {
+		return
+	}
+	id := domainWebhook.NewDeliveryID(strID)
+	err := i.webhookUseCase.ReplayDelivery(ctx, id)
+	if err != nil {
+		sendErrorMsg(ctx, bot, oldMsg, err, "")
+		return
+	}
+
+	sender := msgInlineSender{
+		ChatID:       oldMsg.Chat.ID,
+		CurMessageID: oldMsg.ID,
+		Text:         lang.T(locale.DeliveryReplayed),
+		Keyboard: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:         lang.T(locale.ToDeliveryButton),
+						CallbackData: getWebhookDeliveryHandler.Data().ID(id.String()).String(),
+					},
+				},
+				{
+					{
+						Text:         lang.T(locale.BackButton),
+						CallbackData: replayWebhookHandler.GetBackHandler().String(),
+					},
+				},
+			},
+		},
+	}
+
+	sender.sendInlineMsg(ctx, bot)
+}
+
+func deliveryStatusIcon(s domainWebhook.Status) string {
+	switch s {
+	case domainWebhook.DeliveredStatus:
+		return "✅"
+	case domainWebhook.FailedStatus:
+		return "❌"
+	}
+
+	return "⏳"
+}
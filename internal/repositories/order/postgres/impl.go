@@ -39,7 +39,8 @@ func (i *Implementation) CreateOrder(ctx context.Context, o order.Order) (order.
     total_price ,
     currency    ,
     product_id,
-    ttl) values($1,$2,$3,$4,$5,$6,$7)`,
+    ttl,
+    tariff_id) values($1,$2,$3,$4,$5,$6,$7,$8)`,
 		uid.String(),
 		o.UserID.String(),
 		o.Status.String(),
@@ -47,6 +48,7 @@ func (i *Implementation) CreateOrder(ctx context.Context, o order.Order) (order.
 		o.TotalPrice.Currency,
 		o.Product.ItemID.String(),
 		o.TTL,
+		o.Product.TariffID.String(),
 	)
 	if err != nil {
 		return order.ID{}, custom_errors.NewInternalError(err)
@@ -65,11 +67,34 @@ type orderModel struct {
 	Status     sql.NullString
 	ItemID     sql.NullString
 	TTL        sql.NullTime
+	TariffID   sql.NullString
+}
+
+// orderColumns колонки заказа в порядке сканирования orderModel
+const orderColumns = `id, user_id, order_status, created_at, updated_at, total_price, currency, product_id, ttl, tariff_id`
+
+func (o orderModel) toDomain() order.Order {
+	return order.Order{
+		ID: order.New(o.ID.String),
+		TotalPrice: price.Price{
+			Currency: price.CurrencyFromString(o.Currency.String),
+			Value:    o.TotalPrice.Decimal,
+		},
+		Status:    order.StatusFromString(o.Status.String),
+		UserID:    user.NewID(o.UserID.String),
+		CreatedAt: o.CreatedAt.Time,
+		UpdatedAt: o.UpdatedAt.Time,
+		Product: order.Product{
+			ItemID:   domainProducts.NewItemID(o.ItemID.String),
+			TariffID: domainProducts.NewTariffID(o.TariffID.String),
+		},
+		TTL: o.TTL.Time,
+	}
 }
 
 func (i *Implementation) GetOrder(ctx context.Context, oID order.ID) (order.Order, error) {
 	o := orderModel{}
-	err := i.c.QueryRow(ctx, `select * from orders where uuid_eq(id, $1)`,
+	err := i.c.QueryRow(ctx, `select `+orderColumns+` from orders where uuid_eq(id, $1)`,
 		oID.String()).Scan(
 		&o.ID,
 		&o.UserID,
@@ -79,25 +104,36 @@ func (i *Implementation) GetOrder(ctx context.Context, oID order.ID) (order.Orde
 		&o.TotalPrice,
 		&o.Currency,
 		&o.ItemID,
-		&o.TTL)
+		&o.TTL,
+		&o.TariffID)
 	if err != nil {
 		return order.Order{}, custom_errors.NewInternalError(err)
 	}
 
-	return order.Order{
-		ID: order.New(o.ID.String),
-		TotalPrice: price.Price{
-			Currency: price.CurrencyFromString(o.Currency.String),
-			Value:    o.TotalPrice.Decimal,
-		},
-		Status:    order.StatusFromString(o.Status.String),
-		UserID:    user.NewID(o.UserID.String),
-		CreatedAt: o.CreatedAt.Time,
-		UpdatedAt: o.UpdatedAt.Time,
-		Product: order.Product{
-			ItemID: domainProducts.NewItemID(o.ItemID.String),
-		},
-	}, nil
+	return o.toDomain(), nil
+}
+
+// ClaimFreeProduct закрепляет бесплатный продукт за пользователем.
+// Возвращает ErrFreeProductClaimed, если пользователь уже получал продукт бесплатно
+func (i *Implementation) ClaimFreeProduct(ctx context.Context,
+	userID user.ID,
+	productID domainProducts.ID,
+	oID order.ID,
+) error {
+	t, err := i.c.Exec(ctx, `insert into free_claims (user_id, product_id, order_id)
+	values ($1, $2, $3) on conflict do nothing`,
+		userID.String(),
+		productID.String(),
+		oID.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewBadRequestError(order.ErrFreeProductClaimed)
+	}
+
+	return nil
 }
 
 func (i *Implementation) UpdateOrderStatus(ctx context.Context, oID order.ID, changeState order.ChangeOrderStatus) error {
@@ -129,7 +165,7 @@ func (i *Implementation) UpdateOrderStatus(ctx context.Context, oID order.ID, ch
 
 func (i *Implementation) GetOrders(ctx context.Context, r order.RequestList) ([]order.Order, error) {
 	// TODO переделать на умный builder
-	query := `select * from orders`
+	query := `select ` + orderColumns + ` from orders`
 	values := []any{}
 
 	if r.Filters != nil {
@@ -186,6 +222,10 @@ func (i *Implementation) GetOrders(ctx context.Context, r order.RequestList) ([]
 			values = append(values, r.Pagination.Num)
 			query += ` limit $` + fmt.Sprint(len(values))
 		}
+		if r.Pagination.Offset != 0 {
+			values = append(values, r.Pagination.Offset)
+			query += ` offset $` + fmt.Sprint(len(values))
+		}
 	}
 
 	res, err := i.c.Query(ctx, query,
@@ -207,25 +247,13 @@ func (i *Implementation) GetOrders(ctx context.Context, r order.RequestList) ([]
 			&o.Currency,
 			&o.ItemID,
 			&o.TTL,
+			&o.TariffID,
 		)
 		if err != nil {
 			return nil, custom_errors.NewInternalError(err)
 		}
 
-		itemsResult = append(itemsResult, order.Order{
-			ID: order.New(o.ID.String),
-			TotalPrice: price.Price{
-				Currency: price.CurrencyFromString(o.Currency.String),
-				Value:    o.TotalPrice.Decimal,
-			},
-			Status:    order.StatusFromString(o.Status.String),
-			UserID:    user.NewID(o.UserID.String),
-			CreatedAt: o.CreatedAt.Time,
-			UpdatedAt: o.UpdatedAt.Time,
-			Product: order.Product{
-				ItemID: domainProducts.NewItemID(o.ItemID.String),
-			},
-		})
+		itemsResult = append(itemsResult, o.toDomain())
 	}
 
 	return itemsResult, nil
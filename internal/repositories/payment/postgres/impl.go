@@ -15,6 +15,7 @@ import (
 	domainPayment "github.com/kdv2001/onlySubscription/internal/domain/payment"
 	"github.com/kdv2001/onlySubscription/internal/domain/price"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	"github.com/kdv2001/onlySubscription/internal/domain/user"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
@@ -250,3 +251,56 @@ func (i *Implementation) GetProcessingInvoices(
 
 	return itemsResult, nil
 }
+
+// GetUserInvoices возвращает счета по заказам пользователя, начиная с самых ранних
+func (i *Implementation) GetUserInvoices(ctx context.Context, userID user.ID) ([]domainPayment.Invoice, error) {
+	res, err := i.conn.Query(ctx, `select invoices.id, invoices.state, invoices.payment_method,
+		invoices.created_at, invoices.updated_at, invoices.order_id, invoices.amount, invoices.currency,
+		invoices.provider_id
+		from invoices join orders on orders.id = invoices.order_id
+		where uuid_eq(orders.user_id, $1)
+		order by invoices.created_at`, userID.String())
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer res.Close()
+
+	invoices := make([]domainPayment.Invoice, 0)
+	for res.Next() {
+		o := invoiceModel{}
+		err = res.Scan(
+			&o.ID,
+			&o.State,
+			&o.PaymentMethod,
+			&o.CreatedAt,
+			&o.UpdatedAt,
+			&o.OrderID,
+			&o.Amount,
+			&o.Currency,
+			&o.ProviderID,
+		)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		invoices = append(invoices, domainPayment.Invoice{
+			ID:        domainPayment.New(o.ID.String),
+			OrderID:   order.New(o.OrderID.String),
+			State:     domainPayment.StateFromString(o.State.String),
+			CreatedAt: o.CreatedAt.Time,
+			UpdatedAt: o.UpdatedAt.Time,
+			Price: price.Price{
+				Currency: price.CurrencyFromString(o.Currency.String),
+				Value:    o.Amount.Decimal,
+			},
+			PaymentMethod: domainPayment.PaymentMethodFromString(o.PaymentMethod.String),
+			ProviderID:    domainPayment.NewProviderID(o.ProviderID.String),
+		})
+	}
+
+	if err = res.Err(); err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return invoices, nil
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
-	"github.com/kdv2001/onlySubscription/internal/domain/price"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
@@ -33,118 +33,378 @@ func NewImplementation(conn *pgxpool.Pool) (*Implementation, error) {
 	}, nil
 }
 
-// GetProducts возвращает продукты
-func (i *Implementation) GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.Products, error) {
-	rows, errQ := i.conn.Query(ctx, "select *from products where record_status = $1 offset $2 limit $3",
-		activeRecord,
-		req.Pagination.Offset,
-		req.Pagination.Num)
+// GetProducts возвращает страницу продуктов вместе с состоянием склада
+// и общим кол-вом продуктов, подходящих под фильтры
+func (i *Implementation) GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.ProductsPage, error) {
+	query := `select ` + productColumns + `, st.on_sale, st.issued, st.shared, st.withdrawn, count(*) over ()
+	from products ` + stockJoin + ` where record_status = $1`
+	values := []any{activeRecord}
+	if req.Filters != nil && req.Filters.Archived {
+		values[0] = deleteRecord
+	}
+
+	if req.Filters != nil && !req.Filters.PublishedAt.IsZero() {
+		values = append(values, req.Filters.PublishedAt.UTC())
+		query += ` and (publish_from is null or publish_from <= $` + fmt.Sprint(len(values)) + `)` +
+			` and (publish_until is null or publish_until > $` + fmt.Sprint(len(values)) + `)`
+	}
+
+	if req.Filters != nil && req.Filters.ItemsExist {
+		query += ` and (` + inStockCondition + `)`
+	}
+
+	query += ` order by created_at, id`
+	values = append(values, req.Pagination.Offset)
+	query += ` offset $` + fmt.Sprint(len(values))
+	values = append(values, req.Pagination.Num)
+	query += ` limit $` + fmt.Sprint(len(values))
+
+	rows, errQ := i.conn.Query(ctx, query, values...)
 	if errQ != nil {
-		return nil, custom_errors.NewInternalError(errQ).AddDetails("error get pagination req")
+		return domainProducts.ProductsPage{}, custom_errors.NewInternalError(errQ).AddDetails("error get pagination req")
 	}
+	defer rows.Close()
 
-	products := make(domainProducts.Products, 0, req.Pagination.Num)
+	page := domainProducts.ProductsPage{
+		Products: make(domainProducts.Products, 0, req.Pagination.Num),
+	}
 	for rows.Next() {
-		var p product
-		err := rows.Scan(
-			&p.ID,
-			&p.Name,
-			&p.Description,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-			&p.Type,
-			&p.RecordStatus,
-			&p.Price,
-			&p.SubscriptionPeriod)
+		p, total, err := scanProductWithStock(rows)
 		if err != nil {
-			return nil, custom_errors.NewInternalError(err).AddDetails("error scan row")
+			return domainProducts.ProductsPage{}, custom_errors.NewInternalError(err).AddDetails("error scan row")
 		}
 
-		subDur, err := time.ParseDuration(p.SubscriptionPeriod.String)
-		if err != nil {
-			return nil, custom_errors.NewInternalError(err).AddDetails("error parse dur")
-		}
+		page.Products = append(page.Products, p)
+		page.Total = total
+	}
 
-		products = append(products, domainProducts.Product{
-			ID:          domainProducts.NewID(p.ID.String),
-			Name:        p.Name.String,
-			Description: p.Description.String,
-			Type:        domainProducts.TypeFromString(p.Type.String),
-			Image:       domainProducts.Image{},
-			CreatedAt:   p.CreatedAt.Time,
-			UpdatedAt:   p.UpdatedAt.Time,
-			Price: price.Price{
-				Currency: price.XTR,
-				Value:    p.Price.Decimal,
-			},
-			SubscriptionPeriod: subDur,
-		})
+	if err := rows.Err(); err != nil {
+		return domainProducts.ProductsPage{}, custom_errors.NewInternalError(err)
 	}
 
-	return products, nil
+	return page, nil
 }
 
 // GetProduct возвращает продукт по ID
 func (i *Implementation) GetProduct(ctx context.Context, id domainProducts.ID) (domainProducts.Product, error) {
-	var p product
-	err := i.conn.QueryRow(ctx, `select * from products where uuid_eq(id, $1);`, id.String()).Scan(
-		&p.ID,
-		&p.Name,
-		&p.Description,
-		&p.CreatedAt,
-		&p.UpdatedAt,
-		&p.Type,
-		&p.RecordStatus,
-		&p.Price,
-		&p.SubscriptionPeriod)
+	p, err := scanProduct(i.conn.QueryRow(ctx, `select `+productColumns+`
+		from products where uuid_eq(id, $1);`, id.String()))
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domainProducts.Product{}, custom_errors.NewNotFoundError(err)
+		}
 		return domainProducts.Product{}, err
 	}
 
-	subDur, err := time.ParseDuration(p.SubscriptionPeriod.String)
+	return p, nil
+}
+
+// SetPublication устанавливает окно публикации продукта
+func (i *Implementation) SetPublication(ctx context.Context,
+	id domainProducts.ID,
+	publication domainProducts.Publication,
+) error {
+	_, err := i.conn.Exec(ctx, `UPDATE products
+				SET publish_from = $1, publish_until = $2, publish_announced = false,
+				    updated_at = NOW() AT TIME ZONE 'UTC'
+				WHERE uuid_eq(id, $3);`,
+		nullTime(publication.From),
+		nullTime(publication.Until),
+		id.String())
 	if err != nil {
-		return domainProducts.Product{}, custom_errors.NewInternalError(err).AddDetails("error parse dur")
+		return custom_errors.NewInternalError(err)
 	}
 
-	return domainProducts.Product{
-		ID:          domainProducts.NewID(p.ID.String),
-		Name:        p.Name.String,
-		Description: p.Description.String,
-		Type:        domainProducts.TypeFromString(p.Type.String),
-		Image:       domainProducts.Image{},
-		CreatedAt:   p.CreatedAt.Time,
-		UpdatedAt:   p.UpdatedAt.Time,
-		Price: price.Price{
-			Currency: price.XTR,
-			Value:    p.Price.Decimal,
-		},
-		SubscriptionPeriod: subDur,
-	}, nil
+	return nil
+}
+
+// GetNotAnnouncedPublications возвращает продукты, публикация которых началась,
+// но о начале которой еще не сообщено администраторам
+func (i *Implementation) GetNotAnnouncedPublications(ctx context.Context, num uint64) (domainProducts.Products, error) {
+	rows, err := i.conn.Query(ctx, `select `+productColumns+` from products
+		where record_status = $1 and publish_announced = false
+		  and publish_from is not null and publish_from <= NOW() AT TIME ZONE 'UTC'
+		limit $2`, activeRecord, num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer rows.Close()
+
+	products := make(domainProducts.Products, 0, num)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err).AddDetails("error scan row")
+		}
+
+		products = append(products, p)
+	}
+
+	return products, nil
+}
+
+// MarkPublicationAnnounced отмечает уведомление о начале публикации отправленным
+func (i *Implementation) MarkPublicationAnnounced(ctx context.Context, id domainProducts.ID) error {
+	_, err := i.conn.Exec(ctx, `UPDATE products SET publish_announced = true WHERE uuid_eq(id, $1);`, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
 }
 
 func (i *Implementation) CreateProduct(ctx context.Context, req domainProducts.Product) (domainProducts.ID, error) {
 	uuid := uuid2.New()
-	_, err := i.conn.Exec(ctx, `insert into products (id, name, description, type, price, subscription_period)
-	values ($1, $2, $3, $4, $5, $6)`,
+	err := i.transaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `insert into products (id, name, description, type)
+	values ($1, $2, $3, $4)`,
+			uuid.String(),
+			req.Name,
+			req.Description,
+			req.Type.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		// тарифы создаются вместе с продуктом, чтобы на витрине не было продукта без цены
+		for _, t := range req.Tariffs {
+			_, err = tx.Exec(ctx, `insert into tariffs (id, product_id, name, price, currency, period_count, period_unit)
+	values ($1, $2, $3, $4, $5, $6, $7)`,
+				uuid2.New().String(),
+				uuid.String(),
+				t.Name,
+				t.Price.Value,
+				t.Price.Currency.String(),
+				t.SubscriptionPeriod.Count,
+				t.SubscriptionPeriod.Unit.String())
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domainProducts.ID{}, err
+	}
+
+	return domainProducts.NewID(uuid.String()), nil
+}
+
+// CreateTariff создает тариф продукта
+func (i *Implementation) CreateTariff(ctx context.Context, req domainProducts.Tariff) (domainProducts.TariffID, error) {
+	uuid := uuid2.New()
+	_, err := i.conn.Exec(ctx, `insert into tariffs (id, product_id, name, price, currency, period_count, period_unit)
+	values ($1, $2, $3, $4, $5, $6, $7)`,
 		uuid.String(),
+		req.ProductID.String(),
 		req.Name,
-		req.Description,
-		req.Type.String(),
 		req.Price.Value,
-		req.SubscriptionPeriod.String())
+		req.Price.Currency.String(),
+		req.SubscriptionPeriod.Count,
+		req.SubscriptionPeriod.Unit.String())
 	if err != nil {
-		return domainProducts.ID{}, err
+		return domainProducts.TariffID{}, custom_errors.NewInternalError(err)
 	}
 
-	return domainProducts.NewID(uuid.String()), nil
+	return domainProducts.NewTariffID(uuid.String()), nil
 }
 
-func (i *Implementation) DeleteProduct(ctx context.Context, id domainProducts.ID) error {
-	_, err := i.conn.Exec(ctx, `UPDATE products
+// GetTariffs возвращает активные тарифы продукта
+func (i *Implementation) GetTariffs(ctx context.Context, productID domainProducts.ID) (domainProducts.Tariffs, error) {
+	rows, err := i.conn.Query(ctx, `select `+tariffColumns+` from tariffs t `+activeSaleJoin+`
+		where uuid_eq(t.product_id, $1) and t.record_status = $2 order by t.price`,
+		productID.String(),
+		activeRecord)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer rows.Close()
+
+	tariffs := make(domainProducts.Tariffs, 0)
+	for rows.Next() {
+		var t tariff
+		err = rows.Scan(
+			&t.ID,
+			&t.ProductID,
+			&t.Name,
+			&t.Price,
+			&t.Currency,
+			&t.PeriodCount,
+			&t.PeriodUnit,
+			&t.RecordStatus,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.SaleID,
+			&t.SalePrice,
+			&t.SaleStartsAt,
+			&t.SaleEndsAt)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err).AddDetails("error scan row")
+		}
+
+		domainTariff, err := t.toDomain()
+		if err != nil {
+			return nil, err
+		}
+
+		tariffs = append(tariffs, domainTariff)
+	}
+
+	return tariffs, nil
+}
+
+// GetTariff возвращает тариф по ID
+func (i *Implementation) GetTariff(ctx context.Context, id domainProducts.TariffID) (domainProducts.Tariff, error) {
+	var t tariff
+	err := i.conn.QueryRow(ctx, `select `+tariffColumns+` from tariffs t `+activeSaleJoin+`
+		where uuid_eq(t.id, $1);`, id.String()).Scan(
+		&t.ID,
+		&t.ProductID,
+		&t.Name,
+		&t.Price,
+		&t.Currency,
+		&t.PeriodCount,
+		&t.PeriodUnit,
+		&t.RecordStatus,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.SaleID,
+		&t.SalePrice,
+		&t.SaleStartsAt,
+		&t.SaleEndsAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domainProducts.Tariff{}, custom_errors.NewNotFoundError(err)
+		}
+		return domainProducts.Tariff{}, custom_errors.NewInternalError(err)
+	}
+
+	return t.toDomain()
+}
+
+// DeleteTariff деактивирует тариф
+func (i *Implementation) DeleteTariff(ctx context.Context, id domainProducts.TariffID) error {
+	_, err := i.conn.Exec(ctx, `UPDATE tariffs
 				SET record_status = $1, updated_at = NOW() AT TIME ZONE 'UTC'
 				WHERE uuid_eq(id, $2);`, deleteRecord, id.String())
 	if err != nil {
-		return err
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// DeleteProduct деактивирует продукт и обрабатывает его непроданные единицы инвентаря
+func (i *Implementation) DeleteProduct(ctx context.Context, req domainProducts.Deactivation) error {
+	return i.transaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE products
+				SET record_status = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+				WHERE uuid_eq(id, $2);`, deleteRecord, req.ProductID.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		switch req.Unsold {
+		case domainProducts.WithdrawUnsold:
+			_, err = tx.Exec(ctx, `update inventory set status = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(product_id, $2) and status = $3;`,
+				domainProducts.WithdrawnStatus.String(),
+				req.ProductID.String(),
+				domainProducts.SaleStatus.String())
+		case domainProducts.MoveUnsold:
+			_, err = tx.Exec(ctx, `update inventory set product_id = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(product_id, $2) and status = $3;`,
+				req.TargetProductID.String(),
+				req.ProductID.String(),
+				domainProducts.SaleStatus.String())
+		}
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
+}
+
+// RestoreProduct восстанавливает деактивированный продукт
+// и возвращает в продажу снятые с продажи единицы инвентаря
+func (i *Implementation) RestoreProduct(ctx context.Context, id domainProducts.ID) error {
+	return i.transaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE products
+				SET record_status = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+				WHERE uuid_eq(id, $2);`, activeRecord, id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		_, err = tx.Exec(ctx, `update inventory set status = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(product_id, $2) and status = $3;`,
+			domainProducts.SaleStatus.String(),
+			id.String(),
+			domainProducts.WithdrawnStatus.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
+}
+
+// CreateSale создает распродажу тарифа
+func (i *Implementation) CreateSale(ctx context.Context, req domainProducts.Sale) (domainProducts.SaleID, error) {
+	uuid := uuid2.New()
+	_, err := i.conn.Exec(ctx, `insert into tariff_sales (id, tariff_id, price, starts_at, ends_at)
+	values ($1, $2, $3, $4, $5)`,
+		uuid.String(),
+		req.TariffID.String(),
+		req.Price.Value,
+		req.StartsAt.UTC(),
+		req.EndsAt.UTC())
+	if err != nil {
+		return domainProducts.SaleID{}, custom_errors.NewInternalError(err)
+	}
+
+	return domainProducts.NewSaleID(uuid.String()), nil
+}
+
+// GetNotAnnouncedSales возвращает начавшиеся распродажи, о которых еще не сообщено администраторам
+func (i *Implementation) GetNotAnnouncedSales(ctx context.Context, num uint64) ([]domainProducts.Sale, error) {
+	rows, err := i.conn.Query(ctx, `select s.id, s.tariff_id, s.price, t.currency, s.starts_at, s.ends_at
+		from tariff_sales s join tariffs t on t.id = s.tariff_id
+		where s.announced = false and s.starts_at <= NOW() AT TIME ZONE 'UTC'
+		  and s.ends_at > NOW() AT TIME ZONE 'UTC'
+		limit $1`, num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer rows.Close()
+
+	sales := make([]domainProducts.Sale, 0, num)
+	for rows.Next() {
+		var curSale sale
+		err = rows.Scan(
+			&curSale.ID,
+			&curSale.TariffID,
+			&curSale.Price,
+			&curSale.Currency,
+			&curSale.StartsAt,
+			&curSale.EndsAt)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err).AddDetails("error scan row")
+		}
+
+		sales = append(sales, curSale.toDomain())
+	}
+
+	return sales, nil
+}
+
+// MarkSaleAnnounced отмечает уведомление о начале распродажи отправленным
+func (i *Implementation) MarkSaleAnnounced(ctx context.Context, id domainProducts.SaleID) error {
+	_, err := i.conn.Exec(ctx, `UPDATE tariff_sales SET announced = true WHERE uuid_eq(id, $1);`, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
 	}
 
 	return nil
@@ -152,8 +412,15 @@ func (i *Implementation) DeleteProduct(ctx context.Context, id domainProducts.ID
 
 func (i *Implementation) CreateInventoryItem(ctx context.Context, req domainProducts.Item) (domainProducts.ItemID, error) {
 	uuid := uuid2.New()
-	_, err := i.conn.Exec(ctx, `insert into inventory (id, description, product_id)
-	values ($1, $2, $3)`, uuid, req.Payload, req.ProductID)
+	_, err := i.conn.Exec(ctx, `insert into inventory
+    (id, description, product_id, payload_key_id, payload_key, payload_data)
+	values ($1, $2, $3, $4, $5, $6)`,
+		uuid,
+		req.Payload,
+		req.ProductID,
+		req.EncryptedPayload.KeyID,
+		req.EncryptedPayload.EncryptedKey,
+		req.EncryptedPayload.Data)
 	if err != nil {
 		return domainProducts.ItemID{}, err
 	}
@@ -172,39 +439,219 @@ func (i *Implementation) DeleteInventoryItem(ctx context.Context, id domainProdu
 	return nil
 }
 
-// PreReservedSProduct пререзервирует единицу товара для продажи по айди продукта
+// PreReservedSProduct пререзервирует единицу товара для продажи по айди продукта.
+// Для продуктов с общей полезной нагрузкой единица инвентаря создается копированием общей нагрузки
 func (i *Implementation) PreReservedSProduct(ctx context.Context,
 	productID domainProducts.ID,
 
 ) (domainProducts.ItemID, error) {
 	id := sql.NullString{}
 	err := i.transaction(ctx, func(tx pgx.Tx) error {
-		// находим единицу товара с указанным ID и статусом "Продается"
-
-		err := tx.QueryRow(ctx, `select id from inventory where uuid_eq(product_id, $1)
-                          and  status = $2 limit 1 for update;`, productID, domainProducts.SaleStatus.String()).
-			Scan(&id)
+		// блокируем продукт, чтобы параллельные продажи не превысили лимит
+		mode := sql.NullString{}
+		limit := sql.NullInt64{}
+		err := tx.QueryRow(ctx, `select stock_mode, stock_limit from products
+                          where uuid_eq(id, $1) for update;`, productID.String()).
+			Scan(&mode, &limit)
 		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return custom_errors.NewNotFoundError(err)
+			}
 			return custom_errors.NewInternalError(err)
 		}
 
-		// Бронируем единицу товара
-		_, err = tx.Exec(ctx, `update inventory set status = $1, 
+		stockMode := domainProducts.StockModeFromString(mode.String)
+		if stockMode == domainProducts.CappedStock {
+			issued := sql.NullInt64{}
+			err = tx.QueryRow(ctx, `select count(*) from inventory where uuid_eq(product_id, $1)
+                          and status not in ($2, $3, $4, $5);`,
+				productID.String(),
+				domainProducts.SaleStatus.String(),
+				domainProducts.SharedStatus.String(),
+				domainProducts.WithdrawnStatus.String(),
+				domainProducts.ReleasedStatus.String()).
+				Scan(&issued)
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+
+			if issued.Int64 >= limit.Int64 {
+				return custom_errors.NewBadRequestError(domainProducts.ErrOutOfStock)
+			}
+		}
+
+		// для общей полезной нагрузки единицы "Продается" не берутся: они могли остаться
+		// от прежнего режима склада и содержать устаревшую нагрузку
+		if stockMode.IsShared() {
+			err = pgx.ErrNoRows
+		} else {
+			// находим единицу товара с указанным ID и статусом "Продается"
+			err = tx.QueryRow(ctx, `select id from inventory where uuid_eq(product_id, $1)
+                          and  status = $2 limit 1 for update skip locked;`, productID.String(),
+				domainProducts.SaleStatus.String()).
+				Scan(&id)
+		}
+
+		switch {
+		case err == nil:
+			// Бронируем единицу товара
+			_, err = tx.Exec(ctx, `update inventory set status = $1, 
                      updated_at = NOW() AT TIME ZONE 'UTC' where id = $2;`,
-			domainProducts.PreReservedStatus, id)
+				domainProducts.PreReservedStatus, id)
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+
+			return nil
+		case !errors.Is(err, pgx.ErrNoRows):
+			return custom_errors.NewInternalError(err)
+		case !stockMode.IsShared():
+			return custom_errors.NewBadRequestError(domainProducts.ErrOutOfStock)
+		}
+
+		// копируем общую полезную нагрузку в новую единицу инвентаря
+		uuid := uuid2.New()
+		t, err := tx.Exec(ctx, `insert into inventory
+    (id, product_id, status, description, payload_key_id, payload_key, payload_data)
+	select $1, product_id, $2, description, payload_key_id, payload_key, payload_data
+	from inventory where uuid_eq(product_id, $3) and status = $4;`,
+			uuid.String(),
+			domainProducts.PreReservedStatus.String(),
+			productID.String(),
+			domainProducts.SharedStatus.String())
 		if err != nil {
 			return custom_errors.NewInternalError(err)
 		}
 
+		if t.RowsAffected() == 0 {
+			return custom_errors.NewBadRequestError(domainProducts.ErrOutOfStock).
+				SetDescription("shared payload is not set")
+		}
+
+		id = sql.NullString{String: uuid.String(), Valid: true}
+
 		return nil
 	})
 	if err != nil {
-		return domainProducts.ItemID{}, nil
+		return domainProducts.ItemID{}, err
 	}
 
 	return domainProducts.NewItemID(id.String), nil
 }
 
+// ReleaseSharedCopy снимает бронь копии общей полезной нагрузки: полезная нагрузка удаляется,
+// а запись остается, чтобы заказ по-прежнему ссылался на нее. Повторное снятие брони не является ошибкой
+func (i *Implementation) ReleaseSharedCopy(ctx context.Context, id domainProducts.ItemID) error {
+	t, err := i.conn.Exec(ctx, `update inventory set status = $1, description = '',
+                     payload_key_id = null, payload_key = null, payload_data = null,
+                     payload_purged_at = coalesce(payload_purged_at, NOW() AT TIME ZONE 'UTC'),
+                     updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(id, $2) and status in ($3, $4, $1);`,
+		domainProducts.ReleasedStatus.String(),
+		id.String(),
+		domainProducts.PreReservedStatus.String(),
+		domainProducts.ReservedStatus.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewBadRequestError(errors.New("item is not reserved"))
+	}
+
+	return nil
+}
+
+// SetStockMode устанавливает режим склада продукта
+func (i *Implementation) SetStockMode(ctx context.Context,
+	id domainProducts.ID,
+	mode domainProducts.StockMode,
+	limit int64,
+) error {
+	_, err := i.conn.Exec(ctx, `UPDATE products
+				SET stock_mode = $1, stock_limit = $2, updated_at = NOW() AT TIME ZONE 'UTC'
+				WHERE uuid_eq(id, $3);`,
+		mode.String(),
+		limit,
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// SetAccessChat задает приватный канал или группу продукта, 0 - без доступа
+func (i *Implementation) SetAccessChat(ctx context.Context, id domainProducts.ID, chatID int64) error {
+	_, err := i.conn.Exec(ctx, `UPDATE products
+				SET access_chat_id = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+				WHERE uuid_eq(id, $2);`,
+		sql.NullInt64{Int64: chatID, Valid: chatID != 0},
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// SetSharedPayload заменяет общую полезную нагрузку продукта
+func (i *Implementation) SetSharedPayload(ctx context.Context,
+	productID domainProducts.ID,
+	payload domainProducts.EncryptedPayload,
+) error {
+	return i.transaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `delete from inventory where uuid_eq(product_id, $1) and status = $2;`,
+			productID.String(),
+			domainProducts.SharedStatus.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		_, err = tx.Exec(ctx, `insert into inventory
+    (id, product_id, status, description, payload_key_id, payload_key, payload_data)
+	values ($1, $2, $3, '', $4, $5, $6)`,
+			uuid2.New().String(),
+			productID.String(),
+			domainProducts.SharedStatus.String(),
+			payload.KeyID,
+			payload.EncryptedKey,
+			payload.Data)
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
+}
+
+// GetStock возвращает состояние склада продукта
+func (i *Implementation) GetStock(ctx context.Context, productID domainProducts.ID) (domainProducts.Stock, error) {
+	var onSale, issued, shared, withdrawn sql.NullInt64
+	err := i.conn.QueryRow(ctx, `select
+    count(*) filter (where status = $2),
+    count(*) filter (where status not in ($2, $3, $4, $5)),
+    count(*) filter (where status = $3),
+    count(*) filter (where status = $4)
+	from inventory where uuid_eq(product_id, $1)`,
+		productID.String(),
+		domainProducts.SaleStatus.String(),
+		domainProducts.SharedStatus.String(),
+		domainProducts.WithdrawnStatus.String(),
+		domainProducts.ReleasedStatus.String()).
+		Scan(&onSale, &issued, &shared, &withdrawn)
+	if err != nil {
+		return domainProducts.Stock{}, custom_errors.NewInternalError(err)
+	}
+
+	return domainProducts.Stock{
+		OnSale:           onSale.Int64,
+		Issued:           issued.Int64,
+		HasSharedPayload: shared.Int64 > 0,
+		Withdrawn:        withdrawn.Int64,
+	}, nil
+}
+
 // ChangeItemStatus бронирует товар.
 func (i *Implementation) ChangeItemStatus(ctx context.Context,
 	itemID domainProducts.ItemID,
@@ -238,7 +685,7 @@ func (i *Implementation) GetItems(
 	ctx context.Context,
 	req domainProducts.RequestList,
 ) ([]domainProducts.Item, error) {
-	query := `select *from inventory`
+	query := `select ` + itemColumns + ` from inventory`
 	values := []any{}
 
 	if req.Filters != nil {
@@ -300,55 +747,249 @@ func (i *Implementation) GetItems(
 		return nil, err
 	}
 
+	defer res.Close()
+
 	itemsResult := make([]domainProducts.Item, 0)
 	for res.Next() {
-		var curItem item
-		err = res.Scan(
-			&curItem.ID,
-			&curItem.ProductID,
-			&curItem.Status,
-			&curItem.CreatedAt,
-			&curItem.UpdatedAt,
-			&curItem.Description,
-		)
+		curItem, err := scanItem(res)
 		if err != nil {
 			return nil, custom_errors.NewInternalError(err)
 		}
 
-		itemsResult = append(itemsResult, domainProducts.Item{
-			ID:        domainProducts.NewItemID(curItem.ID.String),
-			ProductID: domainProducts.NewID(curItem.ProductID.String),
-			Status:    domainProducts.ItemStatusFromString(curItem.Status.String),
-			CreatedAt: curItem.CreatedAt.Time,
-			UpdatedAt: curItem.UpdatedAt.Time,
-			Payload:   curItem.Description.String,
-		})
+		itemsResult = append(itemsResult, curItem)
 	}
 
 	return itemsResult, nil
 }
 
 func (i *Implementation) GetItem(ctx context.Context, id domainProducts.ItemID) (domainProducts.Item, error) {
-	var p item
-	err := i.conn.QueryRow(ctx, `select * from inventory where uuid_eq(id, $1);`, id.String()).Scan(
-		&p.ID,
-		&p.ProductID,
-		&p.Status,
-		&p.CreatedAt,
-		&p.UpdatedAt,
-		&p.Description)
+	curItem, err := scanItem(i.conn.QueryRow(ctx, `select `+itemColumns+` from inventory where uuid_eq(id, $1);`,
+		id.String()))
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domainProducts.Item{}, custom_errors.NewNotFoundError(err)
+		}
 		return domainProducts.Item{}, custom_errors.NewInternalError(err)
 	}
 
-	return domainProducts.Item{
-		ID:        domainProducts.NewItemID(p.ID.String),
-		ProductID: domainProducts.NewID(p.ProductID.String),
-		Status:    domainProducts.ItemStatusFromString(p.Status.String),
-		CreatedAt: p.CreatedAt.Time,
-		UpdatedAt: p.UpdatedAt.Time,
-		Payload:   p.Description.String,
-	}, nil
+	return curItem, nil
+}
+
+// GetItemsForRekey возвращает единицы инвентаря, полезная нагрузка которых
+// не зашифрована активным мастер-ключом
+func (i *Implementation) GetItemsForRekey(ctx context.Context,
+	activeKeyID string,
+	num uint64,
+) ([]domainProducts.Item, error) {
+	res, err := i.conn.Query(ctx, `select `+itemColumns+` from inventory
+		where (payload_key_id is null or payload_key_id = '' or payload_key_id <> $1)
+		  and payload_purged_at is null
+		limit $2`, activeKeyID, num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer res.Close()
+
+	itemsResult := make([]domainProducts.Item, 0, num)
+	for res.Next() {
+		curItem, err := scanItem(res)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		itemsResult = append(itemsResult, curItem)
+	}
+
+	return itemsResult, nil
+}
+
+// UpdateItemPayload сохраняет зашифрованную полезную нагрузку
+// и удаляет открытую, если она была
+func (i *Implementation) UpdateItemPayload(ctx context.Context,
+	id domainProducts.ItemID,
+	payload domainProducts.EncryptedPayload,
+) error {
+	_, err := i.conn.Exec(ctx, `update inventory set description = '',
+                     payload_key_id = $1, payload_key = $2, payload_data = $3,
+                     updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(id, $4);`,
+		payload.KeyID,
+		payload.EncryptedKey,
+		payload.Data,
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ConfirmItemDelivery отмечает выдачу единицы инвентаря покупателю
+func (i *Implementation) ConfirmItemDelivery(ctx context.Context, id domainProducts.ItemID) error {
+	_, err := i.conn.Exec(ctx, `update inventory set delivered_at = NOW() AT TIME ZONE 'UTC',
+                     updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(id, $1) and delivered_at is null;`, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetDeliveredItems возвращает оплаченные единицы инвентаря, выдача которых подтверждена
+// и время очередной попытки обработки которых наступило
+func (i *Implementation) GetDeliveredItems(ctx context.Context, num uint64) ([]domainProducts.Item, error) {
+	res, err := i.conn.Query(ctx, `select `+itemColumns+` from inventory
+		where status = $1 and delivered_at is not null
+		  and (post_sale_next_attempt_at is null or post_sale_next_attempt_at <= NOW() AT TIME ZONE 'UTC')
+		order by coalesce(post_sale_next_attempt_at, delivered_at) limit $2`, domainProducts.PerformedStatus.String(), num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer res.Close()
+
+	itemsResult := make([]domainProducts.Item, 0, num)
+	for res.Next() {
+		curItem, err := scanItem(res)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		itemsResult = append(itemsResult, curItem)
+	}
+
+	return itemsResult, nil
+}
+
+// MarkItemRealized переводит единицу инвентаря в статус "реализован" и фиксирует время реализации
+func (i *Implementation) MarkItemRealized(ctx context.Context, id domainProducts.ItemID) error {
+	t, err := i.conn.Exec(ctx, `update inventory set status = $1, realized_at = NOW() AT TIME ZONE 'UTC',
+                     updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(id, $2) and status = $3;`,
+		domainProducts.RealizedStatus.String(),
+		id.String(),
+		domainProducts.PerformedStatus.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewBadRequestError(errors.New("item is not performed"))
+	}
+
+	return nil
+}
+
+// MarkPostSaleFailed фиксирует неудачную попытку обработки реализации и откладывает следующую до nextAttemptAt
+func (i *Implementation) MarkPostSaleFailed(ctx context.Context,
+	id domainProducts.ItemID,
+	nextAttemptAt time.Time,
+) error {
+	_, err := i.conn.Exec(ctx, `update inventory set post_sale_attempts = post_sale_attempts + 1,
+                     post_sale_next_attempt_at = $1, updated_at = NOW() AT TIME ZONE 'UTC'
+                 where uuid_eq(id, $2) and status = $3;`,
+		nextAttemptAt.UTC(),
+		id.String(),
+		domainProducts.PerformedStatus.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// PurgeRealizedPayloads удаляет полезную нагрузку единиц инвентаря, реализованных раньше before.
+// Возвращает кол-во обработанных единиц инвентаря
+func (i *Implementation) PurgeRealizedPayloads(ctx context.Context, before time.Time, num uint64) (int64, error) {
+	t, err := i.conn.Exec(ctx, `update inventory set description = '',
+                     payload_key_id = null, payload_key = null, payload_data = null,
+                     payload_purged_at = NOW() AT TIME ZONE 'UTC'
+                 where id in (select id from inventory
+                     where status = $1 and realized_at < $2 and payload_purged_at is null
+                     limit $3);`,
+		domainProducts.RealizedStatus.String(),
+		before.UTC(),
+		num)
+	if err != nil {
+		return 0, custom_errors.NewInternalError(err)
+	}
+
+	return t.RowsAffected(), nil
+}
+
+// SaveTranslation сохраняет перевод продукта, существующий перевод на тот же язык заменяется
+func (i *Implementation) SaveTranslation(ctx context.Context, t domainProducts.Translation) error {
+	_, err := i.conn.Exec(ctx, `INSERT INTO product_translations (product_id, lang, name, description)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_id, lang) DO UPDATE
+			SET name = excluded.name, description = excluded.description, updated_at = NOW() AT TIME ZONE 'UTC';`,
+		t.ProductID.String(),
+		t.Lang.String(),
+		t.Name,
+		t.Description)
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// DeleteTranslation удаляет перевод продукта на язык lang
+func (i *Implementation) DeleteTranslation(ctx context.Context, id domainProducts.ID, lang locale.Lang) error {
+	_, err := i.conn.Exec(ctx, `DELETE FROM product_translations WHERE uuid_eq(product_id, $1) AND lang = $2;`,
+		id.String(),
+		lang.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetTranslations возвращает переводы продуктов ids
+func (i *Implementation) GetTranslations(ctx context.Context,
+	ids ...domainProducts.ID,
+) (map[domainProducts.ID]domainProducts.Translations, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	strIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strIDs = append(strIDs, id.String())
+	}
+
+	rows, err := i.conn.Query(ctx, `SELECT product_id, lang, name, description FROM product_translations
+			WHERE product_id = ANY($1::uuid[]);`, strIDs)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer rows.Close()
+
+	res := make(map[domainProducts.ID]domainProducts.Translations, len(ids))
+	for rows.Next() {
+		var productID, lang, name, description sql.NullString
+		if err = rows.Scan(&productID, &lang, &name, &description); err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		id := domainProducts.NewID(productID.String)
+		if res[id] == nil {
+			res[id] = make(domainProducts.Translations)
+		}
+		res[id][locale.Lang(lang.String)] = domainProducts.Translation{
+			ProductID:   id,
+			Lang:        locale.Lang(lang.String),
+			Name:        name.String,
+			Description: description.String,
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return res, nil
 }
 
 func (i *Implementation) transaction(ctx context.Context, fnc func(tx pgx.Tx) error) error {
@@ -374,14 +1015,3 @@ func (i *Implementation) transaction(ctx context.Context, fnc func(tx pgx.Tx) er
 
 	return nil
 }
-
-func (i *Implementation) CountItemsForProduct(ctx context.Context, productID domainProducts.ID) (int64, error) {
-	num := sql.NullInt64{}
-	err := i.conn.QueryRow(ctx, `select count(*) from inventory where uuid_eq(product_id, $1) and status = $2`, productID, domainProducts.SaleStatus).Scan(
-		&num)
-	if err != nil {
-		return 0, custom_errors.NewInternalError(err)
-	}
-
-	return num.Int64, nil
-}
@@ -2,8 +2,14 @@ package postgres
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/price"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 type product struct {
@@ -15,16 +21,261 @@ type product struct {
 	Type         sql.NullString
 	Status       sql.NullString
 	RecordStatus sql.NullString
+	PublishFrom  sql.NullTime
+	PublishUntil sql.NullTime
+	StockMode    sql.NullString
+	StockLimit   sql.NullInt64
+	AccessChatID sql.NullInt64
+}
+
+// productColumns колонки продукта в порядке сканирования scanProduct
+const productColumns = `id, name, description, created_at, updated_at, type, record_status, publish_from, publish_until,
+	stock_mode, stock_limit, access_chat_id`
+
+func scanProduct(row pgx.Row) (domainProducts.Product, error) {
+	var p product
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Description,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Type,
+		&p.RecordStatus,
+		&p.PublishFrom,
+		&p.PublishUntil,
+		&p.StockMode,
+		&p.StockLimit,
+		&p.AccessChatID)
+	if err != nil {
+		return domainProducts.Product{}, err
+	}
+
+	return p.toDomain(), nil
+}
+
+func (p product) toDomain() domainProducts.Product {
+	return domainProducts.Product{
+		ID:           domainProducts.NewID(p.ID.String),
+		Name:         p.Name.String,
+		Description:  p.Description.String,
+		Type:         domainProducts.TypeFromString(p.Type.String),
+		Image:        domainProducts.Image{},
+		CreatedAt:    p.CreatedAt.Time,
+		UpdatedAt:    p.UpdatedAt.Time,
+		PublishFrom:  p.PublishFrom.Time,
+		PublishUntil: p.PublishUntil.Time,
+		StockMode:    domainProducts.StockModeFromString(p.StockMode.String),
+		StockLimit:   p.StockLimit.Int64,
+		Archived:     p.RecordStatus.String == string(deleteRecord),
+		AccessChatID: p.AccessChatID.Int64,
+	}
+}
+
+// stockJoin присоединяет к продукту products состояние его склада st
+const stockJoin = `left join lateral (select
+		count(*) filter (where status = 'sale') as on_sale,
+		count(*) filter (where status not in ('sale', 'shared', 'withdrawn', 'released')) as issued,
+		count(*) filter (where status = 'shared') as shared,
+		count(*) filter (where status = 'withdrawn') as withdrawn
+		from inventory where inventory.product_id = products.id) st on true`
+
+// inStockCondition условие наличия товара для продажи, повторяет domainProducts.Product.InStock
+const inStockCondition = `case products.stock_mode
+		when 'unlimited' then st.on_sale > 0 or st.shared > 0
+		when 'capped' then st.issued < products.stock_limit and (st.on_sale > 0 or st.shared > 0)
+		else st.on_sale > 0 end`
+
+// scanProductWithStock сканирует продукт, состояние его склада и общее кол-во строк выборки
+func scanProductWithStock(row pgx.Row) (domainProducts.Product, uint64, error) {
+	var p product
+	var onSale, issued, shared, withdrawn, total sql.NullInt64
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Description,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Type,
+		&p.RecordStatus,
+		&p.PublishFrom,
+		&p.PublishUntil,
+		&p.StockMode,
+		&p.StockLimit,
+		&p.AccessChatID,
+		&onSale,
+		&issued,
+		&shared,
+		&withdrawn,
+		&total)
+	if err != nil {
+		return domainProducts.Product{}, 0, err
+	}
+
+	res := p.toDomain()
+	res.Stock = domainProducts.Stock{
+		OnSale:           onSale.Int64,
+		Issued:           issued.Int64,
+		HasSharedPayload: shared.Int64 > 0,
+		Withdrawn:        withdrawn.Int64,
+	}
+
+	return res, uint64(total.Int64), nil
+}
+
+// nullTime преобразует нулевое время в NULL
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: true,
+	}
+}
+
+type tariff struct {
+	ID           sql.NullString
+	ProductID    sql.NullString
+	Name         sql.NullString
 	Price        decimal.NullDecimal
+	Currency     sql.NullString
+	PeriodCount  sql.NullInt64
+	PeriodUnit   sql.NullString
+	RecordStatus sql.NullString
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	SaleID       sql.NullString
+	SalePrice    decimal.NullDecimal
+	SaleStartsAt sql.NullTime
+	SaleEndsAt   sql.NullTime
+}
+
+// tariffColumns колонки тарифа с действующей распродажей в порядке сканирования tariff
+const tariffColumns = `t.id, t.product_id, t.name, t.price, t.currency, t.period_count, t.period_unit,
+	t.record_status, t.created_at, t.updated_at, s.id, s.price, s.starts_at, s.ends_at`
+
+// activeSaleJoin присоединяет к тарифу t действующую распродажу s
+const activeSaleJoin = `left join lateral (select id, price, starts_at, ends_at from tariff_sales
+		where tariff_id = t.id and starts_at <= NOW() AT TIME ZONE 'UTC' and ends_at > NOW() AT TIME ZONE 'UTC'
+		order by starts_at desc limit 1) s on true`
 
-	SubscriptionPeriod sql.NullString
+type sale struct {
+	ID       sql.NullString
+	TariffID sql.NullString
+	Price    decimal.NullDecimal
+	Currency sql.NullString
+	StartsAt sql.NullTime
+	EndsAt   sql.NullTime
+}
+
+func (s sale) toDomain() domainProducts.Sale {
+	return domainProducts.Sale{
+		ID:       domainProducts.NewSaleID(s.ID.String),
+		TariffID: domainProducts.NewTariffID(s.TariffID.String),
+		Price: price.Price{
+			Currency: price.CurrencyFromString(s.Currency.String),
+			Value:    s.Price.Decimal,
+		},
+		StartsAt: s.StartsAt.Time,
+		EndsAt:   s.EndsAt.Time,
+	}
 }
 
 type item struct {
-	ID          sql.NullString
-	ProductID   sql.NullString
-	Status      sql.NullString
-	CreatedAt   sql.NullTime
-	UpdatedAt   sql.NullTime
-	Description sql.NullString
+	ID           sql.NullString
+	ProductID    sql.NullString
+	Status       sql.NullString
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	Description  sql.NullString
+	PayloadKeyID sql.NullString
+	PayloadKey   []byte
+	PayloadData  []byte
+	DeliveredAt  sql.NullTime
+	RealizedAt   sql.NullTime
+	PurgedAt     sql.NullTime
+	Attempts     sql.NullInt64
+}
+
+// itemColumns колонки единицы инвентаря в порядке сканирования scanItem
+const itemColumns = `id, product_id, status, created_at, updated_at, description,
+	payload_key_id, payload_key, payload_data, delivered_at, realized_at, payload_purged_at, post_sale_attempts`
+
+func scanItem(row pgx.Row) (domainProducts.Item, error) {
+	var curItem item
+	err := row.Scan(
+		&curItem.ID,
+		&curItem.ProductID,
+		&curItem.Status,
+		&curItem.CreatedAt,
+		&curItem.UpdatedAt,
+		&curItem.Description,
+		&curItem.PayloadKeyID,
+		&curItem.PayloadKey,
+		&curItem.PayloadData,
+		&curItem.DeliveredAt,
+		&curItem.RealizedAt,
+		&curItem.PurgedAt,
+		&curItem.Attempts,
+	)
+	if err != nil {
+		return domainProducts.Item{}, err
+	}
+
+	return domainProducts.Item{
+		ID:        domainProducts.NewItemID(curItem.ID.String),
+		ProductID: domainProducts.NewID(curItem.ProductID.String),
+		Status:    domainProducts.ItemStatusFromString(curItem.Status.String),
+		CreatedAt: curItem.CreatedAt.Time,
+		UpdatedAt: curItem.UpdatedAt.Time,
+		Payload:   curItem.Description.String,
+		EncryptedPayload: domainProducts.EncryptedPayload{
+			KeyID:        curItem.PayloadKeyID.String,
+			EncryptedKey: curItem.PayloadKey,
+			Data:         curItem.PayloadData,
+		},
+		DeliveredAt:      curItem.DeliveredAt.Time,
+		RealizedAt:       curItem.RealizedAt.Time,
+		PayloadPurgedAt:  curItem.PurgedAt.Time,
+		PostSaleAttempts: int(curItem.Attempts.Int64),
+	}, nil
+}
+
+func (t tariff) toDomain() (domainProducts.Tariff, error) {
+	period := domainProducts.NewPeriod(int(t.PeriodCount.Int64), domainProducts.PeriodUnitFromString(t.PeriodUnit.String))
+	if period.Unit == domainProducts.UnknownPeriodUnit {
+		return domainProducts.Tariff{}, custom_errors.NewInternalError(domainProducts.ErrInvalidPeriod).
+			AddDetails(t.PeriodUnit.String)
+	}
+
+	var activeSale *domainProducts.Sale
+	if t.SaleID.Valid {
+		activeSale = &domainProducts.Sale{
+			ID:       domainProducts.NewSaleID(t.SaleID.String),
+			TariffID: domainProducts.NewTariffID(t.ID.String),
+			Price: price.Price{
+				Currency: price.CurrencyFromString(t.Currency.String),
+				Value:    t.SalePrice.Decimal,
+			},
+			StartsAt: t.SaleStartsAt.Time,
+			EndsAt:   t.SaleEndsAt.Time,
+		}
+	}
+
+	return domainProducts.Tariff{
+		ID:        domainProducts.NewTariffID(t.ID.String),
+		ProductID: domainProducts.NewID(t.ProductID.String),
+		Name:      t.Name.String,
+		Price: price.Price{
+			Currency: price.CurrencyFromString(t.Currency.String),
+			Value:    t.Price.Decimal,
+		},
+		SubscriptionPeriod: period,
+		Sale:               activeSale,
+		Deleted:            t.RecordStatus.String == string(deleteRecord),
+		CreatedAt:          t.CreatedAt.Time,
+		UpdatedAt:          t.UpdatedAt.Time,
+	}, nil
 }
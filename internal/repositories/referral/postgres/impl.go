@@ -0,0 +1,345 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	uuid2 "github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	domainOrder "github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/referral"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	"github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+type Implementation struct {
+	conn *pgxpool.Pool
+}
+
+func NewImplementation(conn *pgxpool.Pool) (*Implementation, error) {
+	return &Implementation{
+		conn: conn,
+	}, nil
+}
+
+// SaveCode сохраняет реферальный код пользователя и возвращает действующий код.
+// Если код уже занят другим пользователем, возвращается ошибка NotFound
+func (i *Implementation) SaveCode(ctx context.Context, userID user.ID, code referral.Code) (referral.Code, error) {
+	_, err := i.conn.Exec(ctx, `insert into referral_codes (user_id, code) values ($1, $2)
+		on conflict do nothing`,
+		userID.String(),
+		code.String())
+	if err != nil {
+		return "", custom_errors.NewInternalError(err)
+	}
+
+	return i.GetCode(ctx, userID)
+}
+
+// GetCode возвращает реферальный код пользователя
+func (i *Implementation) GetCode(ctx context.Context, userID user.ID) (referral.Code, error) {
+	code := sql.NullString{}
+	err := i.conn.QueryRow(ctx, `select code from referral_codes where uuid_eq(user_id, $1)`,
+		userID.String()).Scan(&code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", custom_errors.NewNotFoundError(err)
+		}
+		return "", custom_errors.NewInternalError(err)
+	}
+
+	return referral.Code(code.String), nil
+}
+
+// GetUserByCode возвращает владельца реферального кода
+func (i *Implementation) GetUserByCode(ctx context.Context, code referral.Code) (user.ID, error) {
+	userID := sql.NullString{}
+	err := i.conn.QueryRow(ctx, `select user_id from referral_codes where code = $1`,
+		code.String()).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.ID{}, custom_errors.NewNotFoundError(err)
+		}
+		return user.ID{}, custom_errors.NewInternalError(err)
+	}
+
+	return user.NewID(userID.String), nil
+}
+
+// CreateReferral сохраняет приглашение, повторное приглашение пользователя игнорируется
+func (i *Implementation) CreateReferral(ctx context.Context, r referral.Referral) error {
+	_, err := i.conn.Exec(ctx, `insert into referrals (referred_id, referrer_id) values ($1, $2)
+		on conflict (referred_id) do nothing`,
+		r.ReferredID.String(),
+		r.ReferrerID.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetReferral возвращает приглашение пользователя
+func (i *Implementation) GetReferral(ctx context.Context, referredID user.ID) (referral.Referral, error) {
+	var referrerID, referred sql.NullString
+	var createdAt sql.NullTime
+	err := i.conn.QueryRow(ctx, `select referrer_id, referred_id, created_at from referrals
+		where uuid_eq(referred_id, $1)`,
+		referredID.String()).Scan(&referrerID, &referred, &createdAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return referral.Referral{}, custom_errors.NewNotFoundError(err)
+		}
+		return referral.Referral{}, custom_errors.NewInternalError(err)
+	}
+
+	return referral.Referral{
+		ReferrerID: user.NewID(referrerID.String),
+		ReferredID: user.NewID(referred.String),
+		CreatedAt:  createdAt.Time,
+	}, nil
+}
+
+// CreateReward сохраняет награду за заказ.
+// Если награда за заказ уже начислена, возвращается referral.ErrRewardExists
+func (i *Implementation) CreateReward(ctx context.Context, r referral.Reward) (referral.Reward, error) {
+	res, err := i.conn.Query(ctx, `insert into referral_rewards
+		(id, referrer_id, referred_id, order_id, kind, days, discount_percent, state)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		on conflict (order_id) do nothing
+		returning `+rewardColumns,
+		uuid2.New().String(),
+		r.ReferrerID.String(),
+		r.ReferredID.String(),
+		r.OrderID.String(),
+		r.Kind.String(),
+		r.Days,
+		r.DiscountPercent,
+		r.State.String())
+	if err != nil {
+		return referral.Reward{}, custom_errors.NewInternalError(err)
+	}
+
+	rewards, err := scanRewards(res)
+	if err != nil {
+		return referral.Reward{}, err
+	}
+
+	if len(rewards) == 0 {
+		return referral.Reward{}, custom_errors.NewBadRequestError(referral.ErrRewardExists)
+	}
+
+	return rewards[0], nil
+}
+
+// GetPendingRewards возвращает ожидающие бонусные дни пользователей, у которых появилась действующая подписка
+func (i *Implementation) GetPendingRewards(ctx context.Context, num uint64) ([]referral.Reward, error) {
+	res, err := i.conn.Query(ctx, `select `+rewardColumns+` from referral_rewards r
+		where r.state = $1 and r.kind = $2 and exists (
+			select 1 from subscription s
+			where s.user_id = r.referrer_id and s.state = any($3))
+		order by r.created_at
+		limit $4`,
+		referral.PendingReward.String(),
+		referral.DaysReward.String(),
+		[]string{subscription.ActiveState.String(), subscription.GraceState.String()},
+		num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return scanRewards(res)
+}
+
+// MarkDaysApplied отмечает бонусные дни начисленными на подписку
+func (i *Implementation) MarkDaysApplied(ctx context.Context, id referral.RewardID, subID subscription.ID) error {
+	_, err := i.conn.Exec(ctx, `update referral_rewards
+		set state = $1, subscription_id = $2, applied_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(id, $3) and state = $4`,
+		referral.AppliedReward.String(),
+		subID.String(),
+		id.String(),
+		referral.PendingReward.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ClaimDiscount занимает самую раннюю доступную скидку пользователя.
+// Если доступных скидок нет, возвращается ошибка NotFound
+func (i *Implementation) ClaimDiscount(ctx context.Context, userID user.ID) (referral.Reward, error) {
+	res, err := i.conn.Query(ctx, `update referral_rewards
+		set state = $1, applied_at = NOW() AT TIME ZONE 'UTC'
+		where id = (select id from referral_rewards
+			where uuid_eq(referrer_id, $2) and kind = $3 and state = $4
+			order by created_at
+			limit 1
+			for update skip locked)
+		returning `+rewardColumns,
+		referral.AppliedReward.String(),
+		userID.String(),
+		referral.DiscountReward.String(),
+		referral.AvailableReward.String())
+	if err != nil {
+		return referral.Reward{}, custom_errors.NewInternalError(err)
+	}
+
+	rewards, err := scanRewards(res)
+	if err != nil {
+		return referral.Reward{}, err
+	}
+
+	if len(rewards) == 0 {
+		return referral.Reward{}, custom_errors.NewNotFoundError(errors.New("no available discounts"))
+	}
+
+	return rewards[0], nil
+}
+
+// BindDiscount запоминает заказ, к которому применена скидка
+func (i *Implementation) BindDiscount(ctx context.Context, id referral.RewardID, orderID domainOrder.ID) error {
+	_, err := i.conn.Exec(ctx, `update referral_rewards set used_order_id = $1 where uuid_eq(id, $2)`,
+		orderID.String(),
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ReleaseDiscount возвращает занятую скидку в доступные
+func (i *Implementation) ReleaseDiscount(ctx context.Context, id referral.RewardID) error {
+	_, err := i.conn.Exec(ctx, `update referral_rewards
+		set state = $1, used_order_id = null, applied_at = null
+		where uuid_eq(id, $2) and state = $3`,
+		referral.AvailableReward.String(),
+		id.String(),
+		referral.AppliedReward.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ReleaseOrderDiscount возвращает в доступные скидку, примененную к заказу
+func (i *Implementation) ReleaseOrderDiscount(ctx context.Context, orderID domainOrder.ID) error {
+	_, err := i.conn.Exec(ctx, `update referral_rewards
+		set state = $1, used_order_id = null, applied_at = null
+		where uuid_eq(used_order_id, $2) and state = $3`,
+		referral.AvailableReward.String(),
+		orderID.String(),
+		referral.AppliedReward.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetStats возвращает статистику приглашений пользователя, без реферального кода
+func (i *Implementation) GetStats(ctx context.Context, userID user.ID) (referral.Stats, error) {
+	var invited, paidOrders, bonusDays, pendingDays, discounts sql.NullInt64
+	err := i.conn.QueryRow(ctx, `select
+		(select count(*) from referrals where uuid_eq(referrer_id, $1)),
+		(select count(*) from orders o join referrals r on o.user_id = r.referred_id
+			where uuid_eq(r.referrer_id, $1) and o.order_status = $2 and o.total_price > 0),
+		(select coalesce(sum(days), 0) from referral_rewards
+			where uuid_eq(referrer_id, $1) and kind = $3 and state = $4),
+		(select coalesce(sum(days), 0) from referral_rewards
+			where uuid_eq(referrer_id, $1) and kind = $3 and state = $5),
+		(select count(*) from referral_rewards
+			where uuid_eq(referrer_id, $1) and kind = $6 and state = $7)`,
+		userID.String(),
+		domainOrder.Performed.String(),
+		referral.DaysReward.String(),
+		referral.AppliedReward.String(),
+		referral.PendingReward.String(),
+		referral.DiscountReward.String(),
+		referral.AvailableReward.String(),
+	).Scan(&invited, &paidOrders, &bonusDays, &pendingDays, &discounts)
+	if err != nil {
+		return referral.Stats{}, custom_errors.NewInternalError(err)
+	}
+
+	return referral.Stats{
+		Invited:     int(invited.Int64),
+		PaidOrders:  int(paidOrders.Int64),
+		BonusDays:   int(bonusDays.Int64),
+		PendingDays: int(pendingDays.Int64),
+		Discounts:   int(discounts.Int64),
+	}, nil
+}
+
+const rewardColumns = `id, referrer_id, referred_id, order_id, kind, days, discount_percent, state,
+	subscription_id, used_order_id, created_at, applied_at`
+
+type rewardModel struct {
+	ID              sql.NullString
+	ReferrerID      sql.NullString
+	ReferredID      sql.NullString
+	OrderID         sql.NullString
+	Kind            sql.NullString
+	Days            sql.NullInt64
+	DiscountPercent sql.NullInt64
+	State           sql.NullString
+	SubscriptionID  sql.NullString
+	UsedOrderID     sql.NullString
+	CreatedAt       sql.NullTime
+	AppliedAt       sql.NullTime
+}
+
+func scanRewards(res pgx.Rows) ([]referral.Reward, error) {
+	defer res.Close()
+
+	rewards := make([]referral.Reward, 0, 10)
+	for res.Next() {
+		r := rewardModel{}
+		err := res.Scan(
+			&r.ID,
+			&r.ReferrerID,
+			&r.ReferredID,
+			&r.OrderID,
+			&r.Kind,
+			&r.Days,
+			&r.DiscountPercent,
+			&r.State,
+			&r.SubscriptionID,
+			&r.UsedOrderID,
+			&r.CreatedAt,
+			&r.AppliedAt,
+		)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		kind, _ := referral.RewardKindFromString(r.Kind.String)
+		rewards = append(rewards, referral.Reward{
+			ID:              referral.NewRewardID(r.ID.String),
+			ReferrerID:      user.NewID(r.ReferrerID.String),
+			ReferredID:      user.NewID(r.ReferredID.String),
+			OrderID:         domainOrder.New(r.OrderID.String),
+			Kind:            kind,
+			Days:            int(r.Days.Int64),
+			DiscountPercent: int(r.DiscountPercent.Int64),
+			State:           referral.NewRewardState(r.State.String),
+			SubscriptionID:  subscription.NewID(r.SubscriptionID.String),
+			UsedOrderID:     domainOrder.New(r.UsedOrderID.String),
+			CreatedAt:       r.CreatedAt.Time,
+			AppliedAt:       r.AppliedAt.Time,
+		})
+	}
+
+	if err := res.Err(); err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return rewards, nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	uuid2 "github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -29,20 +31,211 @@ func NewImplementation(conn *pgxpool.Pool) (*Implementation, error) {
 
 func (i *Implementation) CreateSubscription(ctx context.Context, req subscription.Subscription) (domainProducts.ID, error) {
 	uuid := uuid2.New()
-	_, err := i.conn.Exec(ctx, `insert into subscription
-    (id, user_id, order_id, description, state, deadline)
-	values ($1, $2, $3, $4, $5, $6)`,
+	// повторная обработка того же заказа не создает вторую подписку
+	id := sql.NullString{}
+	err := i.conn.QueryRow(ctx, `insert into subscription
+    (id, user_id, order_id, description, state, deadline, tariff_id, product_id, access_chat_id)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	on conflict (order_id) do update set order_id = excluded.order_id
+	returning id`,
 		uuid.String(),
 		req.UserID.String(),
 		req.OrderID.String(),
 		req.Description,
 		req.State.String(),
-		req.Deadline)
+		req.Deadline,
+		req.TariffID.String(),
+		req.ProductID.String(),
+		sql.NullInt64{Int64: req.AccessChatID, Valid: req.AccessChatID != 0}).Scan(&id)
 	if err != nil {
-		return domainProducts.ID{}, err
+		return domainProducts.ID{}, custom_errors.NewInternalError(err)
 	}
 
-	return domainProducts.NewID(uuid.String()), nil
+	return domainProducts.NewID(id.String), nil
+}
+
+// GetSubscription возвращает подписку по ID
+func (i *Implementation) GetSubscription(ctx context.Context, id subscription.ID) (subscription.Subscription, error) {
+	res, err := i.conn.Query(ctx, `select `+subscriptionColumns+` from subscription
+		where uuid_eq(id, $1)`, id.String())
+	if err != nil {
+		return subscription.Subscription{}, custom_errors.NewInternalError(err)
+	}
+
+	subscriptions, err := scanSubscriptions(res)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	if len(subscriptions) == 0 {
+		return subscription.Subscription{}, custom_errors.NewNotFoundError(errors.New("subscription not found"))
+	}
+
+	return subscriptions[0], nil
+}
+
+// ExtendSubscription продлевает подписку и сохраняет продление в историю.
+// Продление по уже учтенному заказу повторно не применяется, возвращается сохраненное продление.
+// Если после расчета продления подписка изменилась (отозвана, истекла, продлена другим заказом),
+// возвращается ErrSubscriptionChanged
+func (i *Implementation) ExtendSubscription(ctx context.Context, r subscription.Renewal) (subscription.Renewal, error) {
+	var result subscription.Renewal
+	err := i.transaction(ctx, func(tx pgx.Tx) error {
+		state := sql.NullString{}
+		deadline := sql.NullTime{}
+		err := tx.QueryRow(ctx, `select state, deadline from subscription where uuid_eq(id, $1)
+			limit 1 for update`, r.SubscriptionID.String()).
+			Scan(&state, &deadline)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return custom_errors.NewNotFoundError(errors.New("subscription not found"))
+			}
+			return custom_errors.NewInternalError(err)
+		}
+
+		t, err := tx.Exec(ctx, `insert into subscription_renewals
+    		(id, subscription_id, order_id, tariff_id, previous_deadline, new_deadline)
+			values ($1, $2, $3, $4, $5, $6)
+			on conflict (order_id) do nothing`,
+			uuid2.New().String(),
+			r.SubscriptionID.String(),
+			r.OrderID.String(),
+			r.TariffID.String(),
+			r.PreviousDeadline.UTC(),
+			r.NewDeadline.UTC())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		if t.RowsAffected() != 0 {
+			// продление рассчитано от окончания подписки, которое должно совпадать с заблокированной записью
+			if !subscription.CanRenew(subscription.NewState(state.String)) ||
+				!deadline.Time.Equal(r.PreviousDeadline) {
+				return custom_errors.NewBadRequestError(subscription.ErrSubscriptionChanged)
+			}
+
+			// приостановленная подписка остается приостановленной
+			_, err = tx.Exec(ctx, `update subscription set deadline = $1,
+                state = case when state = $4 then state else $2 end, grace_until = null,
+                updated_at = NOW() AT TIME ZONE 'UTC'
+				where uuid_eq(id, $3)`,
+				r.NewDeadline.UTC(),
+				subscription.ActiveState.String(),
+				r.SubscriptionID.String(),
+				subscription.PausedState.String())
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+
+			// напоминания об окончании нового периода отправляются заново
+			_, err = tx.Exec(ctx, `delete from subscription_reminders where uuid_eq(subscription_id, $1)`,
+				r.SubscriptionID.String())
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+		}
+
+		res, err := tx.Query(ctx, `select `+renewalColumns+` from subscription_renewals
+			where uuid_eq(order_id, $1)`, r.OrderID.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		renewals, err := scanRenewals(res)
+		if err != nil {
+			return err
+		}
+
+		if len(renewals) == 0 {
+			return custom_errors.NewInternalError(errors.New("renewal not saved"))
+		}
+
+		result = renewals[0]
+		return nil
+	})
+	if err != nil {
+		return subscription.Renewal{}, err
+	}
+
+	return result, nil
+}
+
+// GetRenewals возвращает историю продлений подписки, от новых к старым
+func (i *Implementation) GetRenewals(ctx context.Context, id subscription.ID) ([]subscription.Renewal, error) {
+	res, err := i.conn.Query(ctx, `select `+renewalColumns+` from subscription_renewals
+		where uuid_eq(subscription_id, $1)
+		order by created_at desc`, id.String())
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return scanRenewals(res)
+}
+
+// GetRenewalByOrder возвращает продление, оформленное заказом orderID
+func (i *Implementation) GetRenewalByOrder(ctx context.Context, orderID domainOrder.ID) (subscription.Renewal, error) {
+	res, err := i.conn.Query(ctx, `select `+renewalColumns+` from subscription_renewals
+		where uuid_eq(order_id, $1)`, orderID.String())
+	if err != nil {
+		return subscription.Renewal{}, custom_errors.NewInternalError(err)
+	}
+
+	renewals, err := scanRenewals(res)
+	if err != nil {
+		return subscription.Renewal{}, err
+	}
+
+	if len(renewals) == 0 {
+		return subscription.Renewal{}, custom_errors.NewNotFoundError(errors.New("renewal not found"))
+	}
+
+	return renewals[0], nil
+}
+
+// renewalColumns колонки продления в порядке сканирования scanRenewals
+const renewalColumns = `id, subscription_id, order_id, tariff_id, previous_deadline, new_deadline, created_at`
+
+type renewalModel struct {
+	ID               sql.NullString
+	SubscriptionID   sql.NullString
+	OrderID          sql.NullString
+	TariffID         sql.NullString
+	PreviousDeadline sql.NullTime
+	NewDeadline      sql.NullTime
+	CreatedAt        sql.NullTime
+}
+
+func scanRenewals(res pgx.Rows) ([]subscription.Renewal, error) {
+	defer res.Close()
+
+	renewals := make([]subscription.Renewal, 0, 10)
+	for res.Next() {
+		r := renewalModel{}
+		err := res.Scan(
+			&r.ID,
+			&r.SubscriptionID,
+			&r.OrderID,
+			&r.TariffID,
+			&r.PreviousDeadline,
+			&r.NewDeadline,
+			&r.CreatedAt,
+		)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		renewals = append(renewals, subscription.Renewal{
+			ID:               subscription.NewRenewalID(r.ID.String),
+			SubscriptionID:   subscription.NewID(r.SubscriptionID.String),
+			OrderID:          domainOrder.New(r.OrderID.String),
+			TariffID:         domainProducts.NewTariffID(r.TariffID.String),
+			PreviousDeadline: r.PreviousDeadline.Time,
+			NewDeadline:      r.NewDeadline.Time,
+			CreatedAt:        r.CreatedAt.Time,
+		})
+	}
+
+	return renewals, nil
 }
 
 type subscriptionModel struct {
@@ -54,45 +247,76 @@ type subscriptionModel struct {
 	UpdatedAt   sql.NullTime
 	State       sql.NullString
 	Deadline    sql.NullTime
+	TariffID    sql.NullString
+	ProductID   sql.NullString
+	ChatID      sql.NullInt64
+	PausedAt    sql.NullTime
+	GraceUntil  sql.NullTime
 }
 
 func (i *Implementation) GetSubscriptions(ctx context.Context, r subscription.RequestList) ([]subscription.Subscription, error) {
-	query := `select id, user_id, order_id, description, created_at, updated_at, state, deadline from subscription`
+	query := `select ` + subscriptionColumns + ` from subscription`
 	values := []any{}
 
 	if r.Filters != nil {
-		query += " where"
+		conditions := make([]string, 0, 5)
+		if !r.Filters.UserID.IsEmpty() {
+			values = append(values, r.Filters.UserID.String())
+			conditions = append(conditions, `uuid_eq(user_id, $`+fmt.Sprint(len(values))+`)`)
+		}
+
+		if r.Filters.ProductID.String() != "" {
+			values = append(values, r.Filters.ProductID.String())
+			conditions = append(conditions, `uuid_eq(product_id, $`+fmt.Sprint(len(values))+`)`)
+		}
+
 		if r.Filters.Statuses != nil {
-			query += " state in ("
-			for j, s := range r.Filters.Statuses {
-				if j != 0 {
-					query += ", "
-				}
+			statuses := make([]string, 0, len(r.Filters.Statuses))
+			for _, s := range r.Filters.Statuses {
 				values = append(values, s.String())
-				query += `$` + fmt.Sprint(len(values))
+				statuses = append(statuses, `$`+fmt.Sprint(len(values)))
 			}
-			query += ")"
+			conditions = append(conditions, `state in (`+strings.Join(statuses, ", ")+`)`)
 		}
 
 		if r.Filters.Deadline != nil {
 			if !r.Filters.Deadline.From.IsZero() {
 				values = append(values, r.Filters.Deadline.From.UTC())
-				query += ` and deadline > $` +
-					fmt.Sprint(len(values))
+				conditions = append(conditions, `deadline > $`+fmt.Sprint(len(values)))
 			}
 			if !r.Filters.Deadline.To.IsZero() {
 				values = append(values, r.Filters.Deadline.To.UTC())
-				query += ` and deadline < $` +
-					fmt.Sprint(len(values))
+				conditions = append(conditions, `deadline < $`+fmt.Sprint(len(values)))
 			}
 		}
+
+		if r.Filters.GraceUntil != nil {
+			if !r.Filters.GraceUntil.From.IsZero() {
+				values = append(values, r.Filters.GraceUntil.From.UTC())
+				conditions = append(conditions, `grace_until > $`+fmt.Sprint(len(values)))
+			}
+			if !r.Filters.GraceUntil.To.IsZero() {
+				values = append(values, r.Filters.GraceUntil.To.UTC())
+				conditions = append(conditions, `grace_until < $`+fmt.Sprint(len(values)))
+			}
+		}
+
+		if len(conditions) != 0 {
+			query += " where " + strings.Join(conditions, " and ")
+		}
 	}
 
+	query += " order by deadline desc"
+
 	if r.Pagination != nil {
 		if r.Pagination.Num != 0 {
 			values = append(values, r.Pagination.Num)
 			query += ` limit $` + fmt.Sprint(len(values))
 		}
+		if r.Pagination.Offset != 0 {
+			values = append(values, r.Pagination.Offset)
+			query += ` offset $` + fmt.Sprint(len(values))
+		}
 	}
 
 	res, err := i.conn.Query(ctx, query,
@@ -101,10 +325,20 @@ func (i *Implementation) GetSubscriptions(ctx context.Context, r subscription.Re
 		return nil, err
 	}
 
+	return scanSubscriptions(res)
+}
+
+// subscriptionColumns колонки подписки в порядке сканирования scanSubscriptions
+const subscriptionColumns = `id, user_id, order_id, description, created_at, updated_at, state, deadline, tariff_id,
+	product_id, access_chat_id, paused_at, grace_until`
+
+func scanSubscriptions(res pgx.Rows) ([]subscription.Subscription, error) {
+	defer res.Close()
+
 	itemsResult := make([]subscription.Subscription, 0, 10)
 	for res.Next() {
 		o := subscriptionModel{}
-		err = res.Scan(
+		err := res.Scan(
 			&o.ID,
 			&o.UserID,
 			&o.OrderID,
@@ -113,27 +347,109 @@ func (i *Implementation) GetSubscriptions(ctx context.Context, r subscription.Re
 			&o.UpdatedAt,
 			&o.State,
 			&o.Deadline,
+			&o.TariffID,
+			&o.ProductID,
+			&o.ChatID,
+			&o.PausedAt,
+			&o.GraceUntil,
 		)
 		if err != nil {
 			return nil, custom_errors.NewInternalError(err)
 		}
 
 		itemsResult = append(itemsResult, subscription.Subscription{
-			ID:          subscription.NewID(o.ID.String),
-			UserID:      user.NewID(o.UserID.String),
-			OrderID:     domainOrder.New(o.OrderID.String),
-			State:       subscription.NewState(o.State.String),
-			Deadline:    o.Deadline.Time,
-			CreatedAt:   o.CreatedAt.Time,
-			UpdatedAt:   o.UpdatedAt.Time,
-			Description: o.Description.String,
+			ID:           subscription.NewID(o.ID.String),
+			UserID:       user.NewID(o.UserID.String),
+			OrderID:      domainOrder.New(o.OrderID.String),
+			TariffID:     domainProducts.NewTariffID(o.TariffID.String),
+			ProductID:    domainProducts.NewID(o.ProductID.String),
+			AccessChatID: o.ChatID.Int64,
+			PausedAt:     o.PausedAt.Time,
+			GraceUntil:   o.GraceUntil.Time,
+			State:        subscription.NewState(o.State.String),
+			Deadline:     o.Deadline.Time,
+			CreatedAt:    o.CreatedAt.Time,
+			UpdatedAt:    o.UpdatedAt.Time,
+			Description:  o.Description.String,
 		})
 	}
 
 	return itemsResult, nil
 }
 
-// ChangeStatus изменяет статус
+// GetSubscriptionsForReminder возвращает подписки в состоянии state, до окончания которых осталось не больше offset
+// и по которым еще не отправлено напоминание с таким же или меньшим отступом.
+// Для подписок в льготном периоде отступ считается от окончания льготного периода
+func (i *Implementation) GetSubscriptionsForReminder(ctx context.Context,
+	state subscription.State,
+	offset time.Duration,
+	num uint64,
+) ([]subscription.Subscription, error) {
+	end := "s.deadline"
+	if state == subscription.GraceState {
+		end = "s.grace_until"
+	}
+
+	res, err := i.conn.Query(ctx, `select `+subscriptionColumns+` from subscription s
+		where s.state = $1
+		  and `+end+` > NOW() AT TIME ZONE 'UTC'
+		  and `+end+` <= NOW() AT TIME ZONE 'UTC' + make_interval(secs => $2)
+		  and not exists (select 1 from subscription_reminders r
+		      where r.subscription_id = s.id and r.state = $1 and r.offset_seconds <= $3)
+		order by `+end+`
+		limit $4`,
+		state.String(),
+		offset.Seconds(),
+		int64(offset.Seconds()),
+		num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return scanSubscriptions(res)
+}
+
+// MarkReminderSent отмечает отправку напоминания с отступом offset для подписки в состоянии state
+func (i *Implementation) MarkReminderSent(ctx context.Context,
+	id subscription.ID,
+	state subscription.State,
+	offset time.Duration,
+) error {
+	_, err := i.conn.Exec(ctx, `insert into subscription_reminders (subscription_id, state, offset_seconds)
+	values ($1, $2, $3) on conflict do nothing`,
+		id.String(),
+		state.String(),
+		int64(offset.Seconds()))
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// StartGrace переводит истекшую активную подписку в льготный период до until.
+// Если подписку успели продлить, возвращается subscription.ErrSubscriptionChanged
+func (i *Implementation) StartGrace(ctx context.Context, id subscription.ID, until time.Time) error {
+	t, err := i.conn.Exec(ctx, `update subscription
+		set state = $1, grace_until = $2, updated_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(id, $3) and state = $4 and deadline <= NOW() AT TIME ZONE 'UTC'`,
+		subscription.GraceState.String(),
+		until.UTC(),
+		id.String(),
+		subscription.ActiveState.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewBadRequestError(subscription.ErrSubscriptionChanged)
+	}
+
+	return nil
+}
+
+// ChangeStatus изменяет статус истекшей подписки. Если подписку продлили после выборки,
+// ее окончание еще не наступило и возвращается ErrSubscriptionChanged
 func (i *Implementation) ChangeStatus(ctx context.Context,
 	subID subscription.ID,
 	changeState subscription.ChangeState,
@@ -148,14 +464,15 @@ func (i *Implementation) ChangeStatus(ctx context.Context,
 		}
 
 		t, err := tx.Exec(ctx, `update subscription set state = $1, updated_at = NOW() AT TIME ZONE 'UTC'
-                 where uuid_eq(id, $2) and state = $3;`,
+                 where uuid_eq(id, $2) and state = $3 and deadline <= NOW() AT TIME ZONE 'UTC'
+                   and (grace_until is null or grace_until <= NOW() AT TIME ZONE 'UTC');`,
 			changeState.To, subID, changeState.From)
 		if err != nil {
 			return custom_errors.NewInternalError(err)
 		}
 
 		if t.RowsAffected() == 0 {
-			return custom_errors.NewBadRequestError(errors.New("booking is expired"))
+			return custom_errors.NewBadRequestError(subscription.ErrSubscriptionChanged)
 		}
 
 		return nil
@@ -185,3 +502,266 @@ func (i *Implementation) transaction(ctx context.Context, fnc func(tx pgx.Tx) er
 
 	return nil
 }
+
+// SaveChatAccess сохраняет выданный по подписке доступ в чат, повторное сохранение игнорируется
+func (i *Implementation) SaveChatAccess(ctx context.Context, a subscription.ChatAccess) error {
+	_, err := i.conn.Exec(ctx, `insert into chat_access
+    (subscription_id, chat_id, user_id, telegram_user_id, invite_link)
+	values ($1, $2, $3, $4, $5)
+	on conflict (subscription_id) do update
+	    set invite_link = excluded.invite_link, revoked_at = null, created_at = NOW() AT TIME ZONE 'UTC'
+	    where chat_access.revoked_at is not null`,
+		a.SubscriptionID.String(),
+		a.ChatID,
+		a.UserID.String(),
+		a.TelegramUserID,
+		a.InviteLink)
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetChatAccess возвращает доступ в чат, выданный по подписке
+func (i *Implementation) GetChatAccess(ctx context.Context, id subscription.ID) (subscription.ChatAccess, error) {
+	res, err := i.conn.Query(ctx, `select `+chatAccessColumns+` from chat_access
+		where uuid_eq(subscription_id, $1)`, id.String())
+	if err != nil {
+		return subscription.ChatAccess{}, custom_errors.NewInternalError(err)
+	}
+
+	accesses, err := scanChatAccesses(res)
+	if err != nil {
+		return subscription.ChatAccess{}, err
+	}
+
+	if len(accesses) == 0 {
+		return subscription.ChatAccess{}, custom_errors.NewNotFoundError(errors.New("chat access not found"))
+	}
+
+	return accesses[0], nil
+}
+
+// GetChatAccessToRevoke возвращает неотозванные доступы пользователей,
+// у которых нет действующей подписки с доступом в тот же чат
+func (i *Implementation) GetChatAccessToRevoke(ctx context.Context, num uint64) ([]subscription.ChatAccess, error) {
+	res, err := i.conn.Query(ctx, `select `+chatAccessColumns+` from chat_access a
+		where a.revoked_at is null
+		  and not exists (select 1 from subscription s
+		      where s.user_id = a.user_id
+		        and s.access_chat_id = a.chat_id
+		        and ((s.state = $1 and s.deadline > NOW() AT TIME ZONE 'UTC')
+		          or (s.state = $2 and s.grace_until > NOW() AT TIME ZONE 'UTC')))
+		order by a.created_at
+		limit $3`,
+		subscription.ActiveState.String(),
+		subscription.GraceState.String(),
+		num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return scanChatAccesses(res)
+}
+
+// MarkChatAccessRevoked отмечает доступ в чат отозванным
+func (i *Implementation) MarkChatAccessRevoked(ctx context.Context, id subscription.ID) error {
+	_, err := i.conn.Exec(ctx, `update chat_access set revoked_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(subscription_id, $1) and revoked_at is null`, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// chatAccessColumns колонки доступа в чат в порядке сканирования scanChatAccesses
+const chatAccessColumns = `subscription_id, chat_id, user_id, telegram_user_id, invite_link, created_at, revoked_at`
+
+type chatAccessModel struct {
+	SubscriptionID sql.NullString
+	ChatID         sql.NullInt64
+	UserID         sql.NullString
+	TelegramUserID sql.NullInt64
+	InviteLink     sql.NullString
+	CreatedAt      sql.NullTime
+	RevokedAt      sql.NullTime
+}
+
+func scanChatAccesses(res pgx.Rows) ([]subscription.ChatAccess, error) {
+	defer res.Close()
+
+	accesses := make([]subscription.ChatAccess, 0, 10)
+	for res.Next() {
+		a := chatAccessModel{}
+		err := res.Scan(
+			&a.SubscriptionID,
+			&a.ChatID,
+			&a.UserID,
+			&a.TelegramUserID,
+			&a.InviteLink,
+			&a.CreatedAt,
+			&a.RevokedAt,
+		)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		accesses = append(accesses, subscription.ChatAccess{
+			SubscriptionID: subscription.NewID(a.SubscriptionID.String),
+			ChatID:         a.ChatID.Int64,
+			UserID:         user.NewID(a.UserID.String),
+			TelegramUserID: a.TelegramUserID.Int64,
+			InviteLink:     a.InviteLink.String,
+			CreatedAt:      a.CreatedAt.Time,
+			RevokedAt:      a.RevokedAt.Time,
+		})
+	}
+
+	return accesses, nil
+}
+
+// ApplyChange применяет изменение подписки администратором и сохраняет его в журнал.
+// Если подписка изменилась после расчета изменения, возвращается subscription.ErrSubscriptionChanged
+func (i *Implementation) ApplyChange(ctx context.Context, c subscription.Change) (subscription.Change, error) {
+	var result subscription.Change
+	err := i.transaction(ctx, func(tx pgx.Tx) error {
+		t, err := tx.Exec(ctx, `update subscription
+			set state = $1, deadline = $2, paused_at = $3, grace_until = null,
+			    updated_at = NOW() AT TIME ZONE 'UTC'
+			where uuid_eq(id, $4) and state = $5 and deadline = $6`,
+			c.State.To.String(),
+			c.NewDeadline.UTC(),
+			sql.NullTime{Time: c.PausedAt.UTC(), Valid: !c.PausedAt.IsZero()},
+			c.SubscriptionID.String(),
+			c.State.From.String(),
+			c.PreviousDeadline.UTC())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		if t.RowsAffected() == 0 {
+			return custom_errors.NewBadRequestError(subscription.ErrSubscriptionChanged)
+		}
+
+		// напоминания об окончании нового периода отправляются заново
+		if !c.NewDeadline.Equal(c.PreviousDeadline) {
+			_, err = tx.Exec(ctx, `delete from subscription_reminders where uuid_eq(subscription_id, $1)`,
+				c.SubscriptionID.String())
+			if err != nil {
+				return custom_errors.NewInternalError(err)
+			}
+		}
+
+		res, err := tx.Query(ctx, `insert into subscription_changes
+    		(id, subscription_id, action, reason, admin_id, from_state, to_state, previous_deadline, new_deadline)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			returning `+changeColumns,
+			uuid2.New().String(),
+			c.SubscriptionID.String(),
+			c.Action.String(),
+			c.Reason,
+			sql.NullString{String: c.AdminID.String(), Valid: !c.AdminID.IsEmpty()},
+			c.State.From.String(),
+			c.State.To.String(),
+			c.PreviousDeadline.UTC(),
+			c.NewDeadline.UTC())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		changes, err := scanChanges(res)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return custom_errors.NewInternalError(errors.New("change not saved"))
+		}
+
+		result = changes[0]
+		result.PausedAt = c.PausedAt
+		return nil
+	})
+	if err != nil {
+		return subscription.Change{}, err
+	}
+
+	return result, nil
+}
+
+// GetChanges возвращает журнал изменений подписки администраторами, от новых к старым
+func (i *Implementation) GetChanges(ctx context.Context, id subscription.ID, num uint64) ([]subscription.Change, error) {
+	res, err := i.conn.Query(ctx, `select `+changeColumns+` from subscription_changes
+		where uuid_eq(subscription_id, $1)
+		order by created_at desc
+		limit $2`, id.String(), num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return scanChanges(res)
+}
+
+// changeColumns колонки изменения подписки в порядке сканирования scanChanges
+const changeColumns = `id, subscription_id, action, reason, admin_id, from_state, to_state,
+	previous_deadline, new_deadline, created_at`
+
+type changeModel struct {
+	ID               sql.NullString
+	SubscriptionID   sql.NullString
+	Action           sql.NullString
+	Reason           sql.NullString
+	AdminID          sql.NullString
+	FromState        sql.NullString
+	ToState          sql.NullString
+	PreviousDeadline sql.NullTime
+	NewDeadline      sql.NullTime
+	CreatedAt        sql.NullTime
+}
+
+func scanChanges(res pgx.Rows) ([]subscription.Change, error) {
+	defer res.Close()
+
+	changes := make([]subscription.Change, 0, 10)
+	for res.Next() {
+		c := changeModel{}
+		err := res.Scan(
+			&c.ID,
+			&c.SubscriptionID,
+			&c.Action,
+			&c.Reason,
+			&c.AdminID,
+			&c.FromState,
+			&c.ToState,
+			&c.PreviousDeadline,
+			&c.NewDeadline,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		changes = append(changes, subscription.Change{
+			ID:             subscription.NewChangeID(c.ID.String),
+			SubscriptionID: subscription.NewID(c.SubscriptionID.String),
+			Action:         subscription.AdminActionFromString(c.Action.String),
+			Reason:         c.Reason.String,
+			AdminID:        user.NewID(c.AdminID.String),
+			State: subscription.ChangeState{
+				From: subscription.NewState(c.FromState.String),
+				To:   subscription.NewState(c.ToState.String),
+			},
+			PreviousDeadline: c.PreviousDeadline.Time,
+			NewDeadline:      c.NewDeadline.Time,
+			CreatedAt:        c.CreatedAt.Time,
+		})
+	}
+
+	if err := res.Err(); err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return changes, nil
+}
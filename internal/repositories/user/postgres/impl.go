@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domain "github.com/kdv2001/onlySubscription/internal/domain/user"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
@@ -26,8 +27,53 @@ func NewImplementation(c *pgxpool.Pool) (*Implementation, error) {
 }
 
 type user struct {
-	UserID sql.NullString
-	ChatID sql.NullString
+	UserID       sql.NullString
+	ChatID       sql.NullString
+	Role         sql.NullString
+	State        sql.NullString
+	BanReason    sql.NullString
+	BanUntil     sql.NullTime
+	BanAdminID   sql.NullString
+	BanCreatedAt sql.NullTime
+	Language     sql.NullString
+	TgLanguage   sql.NullString
+	Unreachable  sql.NullTime
+	Username     sql.NullString
+	FirstName    sql.NullString
+	LastName     sql.NullString
+	TelegramID   sql.NullString
+}
+
+// userSelect выборка пользователя с действующей блокировкой в порядке сканирования user.scanArgs
+const userSelect = `select users.id, auth_bot_telegram.chat_id, users.role, users.state,
+	ban.reason, ban.until, ban.admin_id, ban.created_at, users.language, users.telegram_language,
+	users.unreachable_at, auth_bot_telegram.username, auth_bot_telegram.first_name, auth_bot_telegram.last_name,
+	auth_bot_telegram.telegram_id
+	from users left join auth_bot_telegram on
+    auth_bot_telegram.user_id = users.id
+    left join lateral (select b.reason, b.until, b.admin_id, b.created_at from user_bans b
+        where b.user_id = users.id and b.lifted_at is null
+        order by b.created_at desc
+        limit 1) ban on true`
+
+func (u *user) scanArgs() []any {
+	return []any{
+		&u.UserID,
+		&u.ChatID,
+		&u.Role,
+		&u.State,
+		&u.BanReason,
+		&u.BanUntil,
+		&u.BanAdminID,
+		&u.BanCreatedAt,
+		&u.Language,
+		&u.TgLanguage,
+		&u.Unreachable,
+		&u.Username,
+		&u.FirstName,
+		&u.LastName,
+		&u.TelegramID,
+	}
 }
 
 type telegramBotAuth struct {
@@ -37,55 +83,81 @@ type telegramBotAuth struct {
 	CreatedAt  sql.NullTime
 }
 
-func (repo *Implementation) RegisterTelegram(ctx context.Context, telegramBotLogin domain.TelegramBotRegister) (domain.ID, error) {
-	tx, err := repo.c.Begin(ctx)
-	if err != nil {
-		return domain.ID{}, err
-	}
-	defer func() {
+// RegisterTelegram регистрирует пользователя telegram. Для уже зарегистрированного пользователя
+// обновляются чат, данные профиля и язык клиента, created=false.
+// Регистрация выполняется одним upsert, поэтому параллельные /start нового пользователя не конфликтуют
+func (repo *Implementation) RegisterTelegram(ctx context.Context,
+	telegramBotLogin domain.TelegramBotRegister,
+) (id domain.ID, created bool, err error) {
+	chatID := strconv.FormatInt(telegramBotLogin.ChatID, 10)
+	err = repo.transaction(ctx, func(tx pgx.Tx) error {
+		// пользователь создается заранее: на него ссылается запись авторизации
+		uid := uuid.New()
+		_, err := tx.Exec(ctx, `INSERT INTO users(id, state, telegram_language) values($1, $2, $3);`,
+			uid.String(),
+			domain.VerifiedState.String(),
+			telegramBotLogin.LanguageCode)
 		if err != nil {
-			// TODO  обработать ошибку
-			_ = tx.Rollback(ctx)
+			return custom_errors.NewInternalError(err)
 		}
-	}()
 
-	uid := uuid.New()
-	_, err = tx.Exec(ctx, `INSERT INTO users(id, state) values($1, $2);`, uid.String(), domain.VerifiedState.String())
-	if err != nil {
-		return domain.ID{}, err
-	}
+		userID := sql.NullString{}
+		err = tx.QueryRow(ctx, `INSERT INTO auth_bot_telegram(id, user_id, telegram_id, state, chat_id,
+			username, first_name, last_name)
+			values($1, $2, $3, $4, $5, $6, $7, $8)
+			on conflict (telegram_id) do update
+			set chat_id = excluded.chat_id, username = excluded.username,
+			    first_name = excluded.first_name, last_name = excluded.last_name
+			returning user_id, (xmax = 0)`,
+			uuid.New().String(),
+			uid.String(),
+			telegramBotLogin.TelegramID.String(),
+			domain.VerifiedState.String(),
+			chatID,
+			telegramBotLogin.Username,
+			telegramBotLogin.FirstName,
+			telegramBotLogin.LastName).Scan(&userID, &created)
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
 
-	authUid := uuid.New()
-	_, err = tx.Exec(ctx, `INSERT INTO auth_bot_telegram(id, user_id, telegram_id, state, chat_id) 
-      values($1, $2, $3, $4, $5);`, authUid.String(),
-		uid.String(),
-		telegramBotLogin.TelegramID,
-		domain.VerifiedState.String(),
-		strconv.FormatInt(telegramBotLogin.ChatID, 10))
-	if err != nil {
-		return domain.ID{}, err
-	}
+		id = domain.NewID(userID.String)
+		if created {
+			return nil
+		}
 
-	err = tx.Commit(ctx)
+		// пользователь уже зарегистрирован, заранее созданная запись не нужна
+		_, err = tx.Exec(ctx, `delete from users where id = $1`, uid.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		// повторный /start возобновляет доставку сообщений пользователю, заблокировавшему бота
+		_, err = tx.Exec(ctx, `update users set unreachable_at = null,
+			telegram_language = case when $1 = '' then telegram_language else $1 end
+			where id = $2`,
+			telegramBotLogin.LanguageCode,
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return domain.ID{}, err
+		return domain.ID{}, false, err
 	}
 
-	return domain.NewID(uid.String()), nil
+	return id, created, nil
 }
 
 func (repo *Implementation) GetUser(ctx context.Context,
 	id domain.ID) (domain.User, error) {
 
 	u := user{}
-	err := repo.c.QueryRow(ctx, `select users.id, auth_bot_telegram.chat_id
-	from users left join auth_bot_telegram on
-    auth_bot_telegram.user_id = users.id 
+	err := repo.c.QueryRow(ctx, userSelect+`
           where users.id = $1`, id).
-		Scan(
-			&u.UserID,
-			&u.ChatID,
-		)
+		Scan(u.scanArgs()...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, custom_errors.NewNotFoundError(err)
@@ -93,31 +165,16 @@ func (repo *Implementation) GetUser(ctx context.Context,
 		return domain.User{}, err
 	}
 
-	cID, err := strconv.ParseInt(u.ChatID.String, 10, 64)
-	if err != nil {
-		return domain.User{}, custom_errors.NewInternalError(errors.New("invalid chatID"))
-	}
-
-	return domain.User{
-		ID: domain.NewID(u.UserID.String),
-		Contact: domain.Contact{
-			TelegramBotChatID: cID,
-		},
-	}, nil
+	return u.toDomain()
 }
 
 func (repo *Implementation) GetUserByTelegramID(ctx context.Context,
 	tgID domain.TelegramID) (domain.User, error) {
 
 	u := user{}
-	err := repo.c.QueryRow(ctx, `select users.id, auth_bot_telegram.chat_id
-	from users left join auth_bot_telegram on
-    auth_bot_telegram.user_id = users.id 
+	err := repo.c.QueryRow(ctx, userSelect+`
           where telegram_id = $1`, tgID).
-		Scan(
-			&u.UserID,
-			&u.ChatID,
-		)
+		Scan(u.scanArgs()...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, custom_errors.NewNotFoundError(err)
@@ -125,15 +182,238 @@ func (repo *Implementation) GetUserByTelegramID(ctx context.Context,
 		return domain.User{}, err
 	}
 
+	return u.toDomain()
+}
+
+func (u user) toDomain() (domain.User, error) {
 	cID, err := strconv.ParseInt(u.ChatID.String, 10, 64)
 	if err != nil {
 		return domain.User{}, custom_errors.NewInternalError(errors.New("invalid chatID"))
 	}
 
+	role, err := domain.RoleFromString(u.Role.String)
+	if err != nil {
+		return domain.User{}, custom_errors.NewInternalError(err)
+	}
+
 	return domain.User{
 		ID: domain.NewID(u.UserID.String),
 		Contact: domain.Contact{
+			TelegramID:        domain.NewTelegramID(u.TelegramID.String),
 			TelegramBotChatID: cID,
+			TelegramUsername:  u.Username.String,
+			FirstName:         u.FirstName.String,
+			LastName:          u.LastName.String,
+		},
+		Role:  role,
+		State: domain.NewState(u.State.String),
+		Ban: domain.Ban{
+			Reason:    u.BanReason.String,
+			Until:     u.BanUntil.Time,
+			AdminID:   domain.NewID(u.BanAdminID.String),
+			CreatedAt: u.BanCreatedAt.Time,
 		},
+		Language:         locale.Lang(u.Language.String),
+		TelegramLanguage: u.TgLanguage.String,
+		UnreachableAt:    u.Unreachable.Time,
 	}, nil
 }
+
+// SetRole назначает пользователю роль
+func (repo *Implementation) SetRole(ctx context.Context, id domain.ID, role domain.Role) error {
+	t, err := repo.c.Exec(ctx, `update users set role = $1 where id = $2`, role.String(), id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewNotFoundError(errors.New("user not found"))
+	}
+
+	return nil
+}
+
+// GetStaff возвращает пользователей с ролями в панели администрирования
+func (repo *Implementation) GetStaff(ctx context.Context) ([]domain.User, error) {
+	res, err := repo.c.Query(ctx, userSelect+`
+          where users.role <> ''
+          order by users.role, auth_bot_telegram.created_at`)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+	defer res.Close()
+
+	users := make([]domain.User, 0, 10)
+	for res.Next() {
+		u := user{}
+		if err = res.Scan(u.scanArgs()...); err != nil {
+			return nil, custom_errors.NewInternalError(err)
+		}
+
+		du, errD := u.toDomain()
+		if errD != nil {
+			return nil, errD
+		}
+		users = append(users, du)
+	}
+
+	if err = res.Err(); err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	return users, nil
+}
+
+// Ban блокирует пользователя, предыдущая блокировка заменяется новой
+func (repo *Implementation) Ban(ctx context.Context, id domain.ID, ban domain.Ban) error {
+	return repo.transaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `update user_bans set lifted_at = NOW() AT TIME ZONE 'UTC', lifted_by = $1
+			where user_id = $2 and lifted_at is null`,
+			ban.AdminID.String(),
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		_, err = tx.Exec(ctx, `insert into user_bans (id, user_id, reason, until, admin_id)
+			values ($1, $2, $3, $4, $5)`,
+			uuid.New().String(),
+			id.String(),
+			ban.Reason,
+			sql.NullTime{Time: ban.Until.UTC(), Valid: !ban.Until.IsZero()},
+			ban.AdminID.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		t, err := tx.Exec(ctx, `update users set state = $1 where id = $2`,
+			domain.BlockedState.String(),
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		if t.RowsAffected() == 0 {
+			return custom_errors.NewNotFoundError(errors.New("user not found"))
+		}
+
+		return nil
+	})
+}
+
+// Unban снимает блокировку пользователя
+func (repo *Implementation) Unban(ctx context.Context, id domain.ID, adminID domain.ID) error {
+	return repo.transaction(ctx, func(tx pgx.Tx) error {
+		t, err := tx.Exec(ctx, `update users set state = $1 where id = $2 and state = $3`,
+			domain.VerifiedState.String(),
+			id.String(),
+			domain.BlockedState.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		if t.RowsAffected() == 0 {
+			return custom_errors.NewBadRequestError(errors.New("user is not blocked"))
+		}
+
+		_, err = tx.Exec(ctx, `update user_bans set lifted_at = NOW() AT TIME ZONE 'UTC', lifted_by = $1
+			where user_id = $2 and lifted_at is null`,
+			adminID.String(),
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
+}
+
+// SetLanguage сохраняет выбранный пользователем язык, пустой - как в Telegram
+func (repo *Implementation) SetLanguage(ctx context.Context, id domain.ID, lang locale.Lang) error {
+	t, err := repo.c.Exec(ctx, `update users set language = $1 where id = $2`, lang.String(), id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewNotFoundError(errors.New("user not found"))
+	}
+
+	return nil
+}
+
+// SetTelegramLanguage сохраняет код языка клиента Telegram
+func (repo *Implementation) SetTelegramLanguage(ctx context.Context, id domain.ID, code string) error {
+	_, err := repo.c.Exec(ctx, `update users set telegram_language = $1 where id = $2`, code, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// MarkUnreachable отмечает, что пользователь заблокировал бота
+func (repo *Implementation) MarkUnreachable(ctx context.Context, id domain.ID) error {
+	_, err := repo.c.Exec(ctx, `update users set unreachable_at = NOW() AT TIME ZONE 'UTC'
+		where id = $1 and unreachable_at is null`, id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// Anonymize удаляет данные telegram пользователя: telegram ID заменяется на обезличенный,
+// чат, username, имя и фамилия очищаются. Сообщения пользователю больше не отправляются
+func (repo *Implementation) Anonymize(ctx context.Context, id domain.ID) error {
+	return repo.transaction(ctx, func(tx pgx.Tx) error {
+		t, err := tx.Exec(ctx, `update auth_bot_telegram
+			set telegram_id = $1, chat_id = '0', username = '', first_name = '', last_name = '', state = $2
+			where user_id = $3`,
+			domain.DeletedTelegramID(id).String(),
+			domain.DeletedState.String(),
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		if t.RowsAffected() == 0 {
+			return custom_errors.NewNotFoundError(errors.New("user not found"))
+		}
+
+		_, err = tx.Exec(ctx, `update users set state = $1, language = '', telegram_language = '',
+			unreachable_at = coalesce(unreachable_at, NOW() AT TIME ZONE 'UTC')
+			where id = $2`,
+			domain.DeletedState.String(),
+			id.String())
+		if err != nil {
+			return custom_errors.NewInternalError(err)
+		}
+
+		return nil
+	})
+}
+
+func (repo *Implementation) transaction(ctx context.Context, fnc func(tx pgx.Tx) error) error {
+	tx, err := repo.c.Begin(ctx)
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	err = fnc(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
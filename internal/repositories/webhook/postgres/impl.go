@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	uuid2 "github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/webhook"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+type Implementation struct {
+	conn *pgxpool.Pool
+}
+
+func NewImplementation(conn *pgxpool.Pool) (*Implementation, error) {
+	return &Implementation{
+		conn: conn,
+	}, nil
+}
+
+// CreateDeliveries создает доставки события каждому получателю.
+// Повторная публикация события с тем же ID игнорируется
+func (i *Implementation) CreateDeliveries(ctx context.Context, e communication.Event, endpoints []string) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, endpoint := range endpoints {
+		batch.Queue(`insert into webhook_deliveries
+    		(id, event_id, endpoint, event_type, occurred_at, data, status)
+			values ($1, $2, $3, $4, $5, $6, $7)
+			on conflict (event_id, endpoint) do nothing`,
+			uuid2.New().String(),
+			e.ID,
+			endpoint,
+			e.Type,
+			e.OccurredAt.UTC(),
+			data,
+			webhook.PendingStatus.String())
+	}
+
+	err = i.conn.SendBatch(ctx, batch).Close()
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// GetDueDeliveries возвращает ожидающие доставки, время попытки которых наступило
+func (i *Implementation) GetDueDeliveries(ctx context.Context, num uint64) ([]webhook.Delivery, error) {
+	res, err := i.conn.Query(ctx, `select `+deliveryColumns+` from webhook_deliveries
+		where status = $1 and next_attempt_at <= NOW() AT TIME ZONE 'UTC'
+		order by next_attempt_at
+		limit $2`,
+		webhook.PendingStatus.String(),
+		num)
+	if err != nil {
+		return nil, custom_errors.NewInternalError(err)
+	}
+
+	deliveries, _, err := scanDeliveries(res, false)
+	return deliveries, err
+}
+
+// MarkDelivered отмечает успешную доставку
+func (i *Implementation) MarkDelivered(ctx context.Context, id webhook.DeliveryID) error {
+	_, err := i.conn.Exec(ctx, `update webhook_deliveries
+		set status = $1, attempts = attempts + 1, last_error = '',
+		    delivered_at = NOW() AT TIME ZONE 'UTC', updated_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(id, $2)`,
+		webhook.DeliveredStatus.String(),
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// MarkAttemptFailed сохраняет неудачную попытку доставки и время следующей попытки
+func (i *Implementation) MarkAttemptFailed(ctx context.Context,
+	id webhook.DeliveryID,
+	status webhook.Status,
+	lastError string,
+	nextAttemptAt time.Time,
+) error {
+	_, err := i.conn.Exec(ctx, `update webhook_deliveries
+		set status = $1, attempts = attempts + 1, last_error = $2,
+		    next_attempt_at = $3, updated_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(id, $4)`,
+		status.String(),
+		lastError,
+		nextAttemptAt.UTC(),
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ReplayDelivery возвращает доставку в очередь с обнуленным счетчиком попыток
+func (i *Implementation) ReplayDelivery(ctx context.Context, id webhook.DeliveryID) error {
+	t, err := i.conn.Exec(ctx, `update webhook_deliveries
+		set status = $1, attempts = 0, last_error = '', delivered_at = null,
+		    next_attempt_at = NOW() AT TIME ZONE 'UTC', updated_at = NOW() AT TIME ZONE 'UTC'
+		where uuid_eq(id, $2)`,
+		webhook.PendingStatus.String(),
+		id.String())
+	if err != nil {
+		return custom_errors.NewInternalError(err)
+	}
+
+	if t.RowsAffected() == 0 {
+		return custom_errors.NewNotFoundError(errors.New("delivery not found"))
+	}
+
+	return nil
+}
+
+// GetDelivery возвращает доставку
+func (i *Implementation) GetDelivery(ctx context.Context, id webhook.DeliveryID) (webhook.Delivery, error) {
+	res, err := i.conn.Query(ctx, `select `+deliveryColumns+` from webhook_deliveries
+		where uuid_eq(id, $1)`, id.String())
+	if err != nil {
+		return webhook.Delivery{}, custom_errors.NewInternalError(err)
+	}
+
+	deliveries, _, err := scanDeliveries(res, false)
+	if err != nil {
+		return webhook.Delivery{}, err
+	}
+
+	if len(deliveries) == 0 {
+		return webhook.Delivery{}, custom_errors.NewNotFoundError(errors.New("delivery not found"))
+	}
+
+	return deliveries[0], nil
+}
+
+// GetDeliveries возвращает журнал доставок, начиная с новых
+func (i *Implementation) GetDeliveries(ctx context.Context, r webhook.RequestList) (webhook.DeliveriesPage, error) {
+	query := `select ` + deliveryColumns + `, count(*) over () from webhook_deliveries`
+	values := []any{}
+
+	if r.Filters != nil && len(r.Filters.Statuses) != 0 {
+		statuses := make([]string, 0, len(r.Filters.Statuses))
+		for _, s := range r.Filters.Statuses {
+			values = append(values, s.String())
+			statuses = append(statuses, `$`+fmt.Sprint(len(values)))
+		}
+		query += ` where status in (` + strings.Join(statuses, ", ") + `)`
+	}
+
+	query += ` order by created_at desc, id`
+
+	if r.Pagination != nil {
+		if r.Pagination.Num != 0 {
+			values = append(values, r.Pagination.Num)
+			query += ` limit $` + fmt.Sprint(len(values))
+		}
+		if r.Pagination.Offset != 0 {
+			values = append(values, r.Pagination.Offset)
+			query += ` offset $` + fmt.Sprint(len(values))
+		}
+	}
+
+	res, err := i.conn.Query(ctx, query, values...)
+	if err != nil {
+		return webhook.DeliveriesPage{}, custom_errors.NewInternalError(err)
+	}
+
+	deliveries, total, err := scanDeliveries(res, true)
+	if err != nil {
+		return webhook.DeliveriesPage{}, err
+	}
+
+	return webhook.DeliveriesPage{
+		Deliveries: deliveries,
+		Total:      total,
+	}, nil
+}
+
+// deliveryColumns колонки доставки в порядке сканирования scanDeliveries
+const deliveryColumns = `id, event_id, endpoint, event_type, occurred_at, data, status, attempts, next_attempt_at,
+	last_error, created_at, delivered_at`
+
+type deliveryModel struct {
+	ID            sql.NullString
+	EventID       sql.NullString
+	Endpoint      sql.NullString
+	EventType     sql.NullString
+	OccurredAt    sql.NullTime
+	Data          []byte
+	Status        sql.NullString
+	Attempts      sql.NullInt64
+	NextAttemptAt sql.NullTime
+	LastError     sql.NullString
+	CreatedAt     sql.NullTime
+	DeliveredAt   sql.NullTime
+}
+
+// scanDeliveries сканирует доставки, при withTotal - и общее кол-во строк выборки
+func scanDeliveries(res pgx.Rows, withTotal bool) ([]webhook.Delivery, uint64, error) {
+	defer res.Close()
+
+	var total sql.NullInt64
+	deliveries := make([]webhook.Delivery, 0, 10)
+	for res.Next() {
+		d := deliveryModel{}
+		dest := []any{
+			&d.ID,
+			&d.EventID,
+			&d.Endpoint,
+			&d.EventType,
+			&d.OccurredAt,
+			&d.Data,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		}
+		if withTotal {
+			dest = append(dest, &total)
+		}
+
+		if err := res.Scan(dest...); err != nil {
+			return nil, 0, custom_errors.NewInternalError(err)
+		}
+
+		data := make(map[string]string)
+		if len(d.Data) != 0 {
+			if err := json.Unmarshal(d.Data, &data); err != nil {
+				return nil, 0, custom_errors.NewInternalError(err)
+			}
+		}
+
+		deliveries = append(deliveries, webhook.Delivery{
+			ID:       webhook.NewDeliveryID(d.ID.String),
+			Endpoint: d.Endpoint.String,
+			Event: communication.Event{
+				ID:         d.EventID.String,
+				Type:       d.EventType.String,
+				OccurredAt: d.OccurredAt.Time,
+				Data:       data,
+			},
+			Status:        webhook.StatusFromString(d.Status.String),
+			Attempts:      int(d.Attempts.Int64),
+			NextAttemptAt: d.NextAttemptAt.Time,
+			LastError:     d.LastError.String,
+			CreatedAt:     d.CreatedAt.Time,
+			DeliveredAt:   d.DeliveredAt.Time,
+		})
+	}
+
+	return deliveries, uint64(total.Int64), nil
+}
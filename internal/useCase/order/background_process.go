@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/order"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
 	"github.com/kdv2001/onlySubscription/pkg/parallel"
 )
 
@@ -55,31 +58,46 @@ func (i *Implementation) processingOrders(ctx context.Context) error {
 			return errU
 		}
 
-		item, errI := i.productUC.GetItem(ctx, o.Product.ItemID)
+		tariff, errI := i.productUC.GetTariff(ctx, o.Product.TariffID)
 		if errI != nil {
 			return errI
 		}
 
-		p, errI := i.productUC.GetProduct(ctx, item.ProductID)
-		if errI != nil {
-			return errI
+		product, errP := i.productUC.GetProduct(ctx, tariff.ProductID)
+		if errP != nil {
+			return errP
+		}
+
+		// повторная покупка продукта продлевает действующую подписку
+		sub, errS := i.subscriptionUC.Subscribe(ctx, subscription.Purchase{
+			UserID:       o.UserID,
+			OrderID:      o.ID,
+			ProductID:    tariff.ProductID,
+			TariffID:     tariff.ID,
+			Period:       tariff.SubscriptionPeriod,
+			AccessChatID: product.AccessChatID,
+		})
+		if errS != nil {
+			return errS
+		}
+
+		// полезная нагрузка расшифровывается только непосредственно перед выдачей
+		payload, errP := i.productUC.RevealPayload(ctx, o.Product.ItemID)
+		if errP != nil {
+			return errP
 		}
 
-		err = i.subscriptionUC.CreateSubscription(ctx, subscription.Subscription{
-			UserID:      o.UserID,
-			OrderID:     o.ID,
-			Deadline:    time.Now().UTC().Add(p.SubscriptionPeriod),
-			Description: "Обновите продукт",
+		lang := user.Lang()
+		err = i.userUC.Notify(ctx, user, communication.Message{
+			Title:       lang.T(locale.OrderTitle, o.ID),
+			Description: lang.T(locale.OrderDelivered, payload, sub.Deadline.Format(consts.DateTimeLayout)),
 		})
 		if err != nil {
 			return err
 		}
 
-		err = i.communicationClient.SendMessage(ctx, communication.Message{
-			ChatID:      user.Contact.TelegramBotChatID,
-			Title:       "Заказ № " + o.ID.String(),
-			Description: "Полезная нагрузка: " + item.Payload,
-		})
+		// после подтверждения выдачи единица инвентаря уходит в обработку реализации
+		err = i.productUC.ConfirmDelivery(ctx, o.Product.ItemID)
 		if err != nil {
 			return err
 		}
@@ -88,6 +106,11 @@ func (i *Implementation) processingOrders(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+
+		// заказ уже выполнен, поэтому ошибка начисления награды пригласившему только логируется
+		if err = i.referralUC.RewardOrder(ctx, o); err != nil {
+			logger.Errorf(ctx, "error reward referrer: %v", err)
+		}
 	}
 
 	return nil
@@ -130,10 +153,9 @@ func (i *Implementation) cancelOrders(ctx context.Context) error {
 			return errU
 		}
 
-		err = i.communicationClient.SendMessage(ctx, communication.Message{
-			ChatID:      user.Contact.TelegramBotChatID,
-			Title:       "Заказ № " + o.ID.String(),
-			Description: "Отменен по истечению времени жизни",
+		err = i.userUC.Notify(ctx, user, communication.Message{
+			Title:       user.Lang().T(locale.OrderTitle, o.ID),
+			Description: user.Lang().T(locale.OrderExpired),
 		})
 		if err != nil {
 			return err
@@ -143,6 +165,11 @@ func (i *Implementation) cancelOrders(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+
+		err = i.referralUC.ReleaseOrderDiscount(ctx, o.ID)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
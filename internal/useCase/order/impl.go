@@ -7,17 +7,24 @@ import (
 
 	"github.com/kdv2001/onlySubscription/internal/domain/communication"
 	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/internal/domain/referral"
 	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
 	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
 )
 
 type productUC interface {
 	GetProduct(ctx context.Context, id domainProducts.ID) (domainProducts.Product, error)
+	GetTariff(ctx context.Context, id domainProducts.TariffID) (domainProducts.Tariff, error)
 
 	GetItem(ctx context.Context, id domainProducts.ItemID) (domainProducts.Item, error)
+	RevealPayload(ctx context.Context, id domainProducts.ItemID) (string, error)
+	ConfirmDelivery(ctx context.Context, itemID domainProducts.ItemID) error
 	PerformedItem(ctx context.Context, itemID domainProducts.ItemID) error
 	PreReserveItem(ctx context.Context, productID domainProducts.ID) (domainProducts.ItemID, error)
 	ReserveItem(ctx context.Context, itemID domainProducts.ItemID) error
@@ -29,74 +36,120 @@ type orderRepo interface {
 	GetOrder(ctx context.Context, oID order.ID) (order.Order, error)
 	UpdateOrderStatus(ctx context.Context, oID order.ID, status order.ChangeOrderStatus) error
 	GetOrders(ctx context.Context, r order.RequestList) ([]order.Order, error)
+	ClaimFreeProduct(ctx context.Context, userID domainUser.ID, productID domainProducts.ID, oID order.ID) error
 }
 
 type userUC interface {
 	GetUser(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	Notify(ctx context.Context, u domainUser.User, msg communication.Message) error
 }
 
-type communicationClient interface {
-	SendMessage(ctx context.Context, message communication.Message) error
+type subscriptionUC interface {
+	Subscribe(ctx context.Context, p subscription.Purchase) (subscription.Subscription, error)
 }
 
-type subscriptionUC interface {
-	CreateSubscription(ctx context.Context, s subscription.Subscription) error
+type referralUC interface {
+	RewardOrder(ctx context.Context, o order.Order) error
+	ClaimDiscount(ctx context.Context, userID domainUser.ID) (referral.Reward, error)
+	BindDiscount(ctx context.Context, id referral.RewardID, orderID order.ID) error
+	ReleaseDiscount(ctx context.Context, id referral.RewardID) error
+	ReleaseOrderDiscount(ctx context.Context, orderID order.ID) error
 }
 
 type Implementation struct {
-	productUC           productUC
-	userUC              userUC
-	subscriptionUC      subscriptionUC
-	orderRepo           orderRepo
-	communicationClient communicationClient
+	productUC      productUC
+	userUC         userUC
+	subscriptionUC subscriptionUC
+	referralUC     referralUC
+	orderRepo      orderRepo
 }
 
 func NewImplementation(
 	productUC productUC,
 	userUC userUC,
 	subscriptionUC subscriptionUC,
+	referralUC referralUC,
 	orderRepo orderRepo,
-	communicationClient communicationClient,
 ) *Implementation {
 	return &Implementation{
-		productUC:           productUC,
-		orderRepo:           orderRepo,
-		userUC:              userUC,
-		subscriptionUC:      subscriptionUC,
-		communicationClient: communicationClient,
+		productUC:      productUC,
+		orderRepo:      orderRepo,
+		userUC:         userUC,
+		subscriptionUC: subscriptionUC,
+		referralUC:     referralUC,
 	}
 }
 
 // CreateOrder создает заказ
 func (i *Implementation) CreateOrder(ctx context.Context, o order.CreateOrder) (order.ID, error) {
-	product, err := i.productUC.GetProduct(ctx, o.ProductID)
+	tariff, err := i.productUC.GetTariff(ctx, o.TariffID)
 	if err != nil {
 		return order.ID{}, err
 	}
 
-	itemID, err := i.productUC.PreReserveItem(ctx, o.ProductID)
+	if tariff.Deleted {
+		return order.ID{}, custom_errors.NewBadRequestError(errors.New("tariff is deleted"))
+	}
+
+	product, err := i.productUC.GetProduct(ctx, tariff.ProductID)
+	if err != nil {
+		return order.ID{}, err
+	}
+
+	if product.Archived || !product.IsPublished(time.Now().UTC()) {
+		return order.ID{}, custom_errors.NewBadRequestError(errors.New("product is not published"))
+	}
+
+	// скидка за приглашение друга применяется только к платному тарифу.
+	// Скидка занимается до брони, при любой ошибке ниже занятое возвращается через abortCreateOrder
+	totalPrice := tariff.ActualPrice()
+	var discount referral.Reward
+	if !tariff.IsFree() {
+		discount, err = i.referralUC.ClaimDiscount(ctx, o.UserID)
+		if err != nil {
+			return order.ID{}, err
+		}
+		totalPrice = referral.ApplyDiscount(totalPrice, discount.DiscountPercent)
+	}
+
+	itemID, err := i.productUC.PreReserveItem(ctx, product.ID)
 	if err != nil {
+		i.abortCreateOrder(ctx, order.ID{}, domainProducts.ItemID{}, discount.ID)
 		return order.ID{}, err
 	}
 
 	defaultStatus := order.Form
 	orderID, err := i.orderRepo.CreateOrder(ctx, order.Order{
-		TotalPrice: product.Price,
+		TotalPrice: totalPrice,
 		Status:     defaultStatus,
 		UserID:     o.UserID,
 		Product: order.Product{
 			ItemID:    itemID,
 			ProductID: product.ID,
+			TariffID:  tariff.ID,
 		},
 		TTL: time.Now().UTC().Add(consts.DefaultOrderTimeLimit),
 	})
 	if err != nil {
+		i.abortCreateOrder(ctx, order.ID{}, itemID, discount.ID)
 		return order.ID{}, err
 	}
 
+	if !discount.ID.IsEmpty() {
+		if err = i.referralUC.BindDiscount(ctx, discount.ID, orderID); err != nil {
+			i.abortCreateOrder(ctx, orderID, itemID, discount.ID)
+			return order.ID{}, err
+		}
+	}
+
+	if tariff.IsFree() {
+		return orderID, i.fulfillFreeOrder(ctx, orderID, o.UserID, product.ID, itemID)
+	}
+
 	// TODO получить таймлимит от продукта
 	err = i.productUC.ReserveItem(ctx, itemID)
 	if err != nil {
+		i.abortCreateOrder(ctx, orderID, itemID, discount.ID)
 		return order.ID{}, err
 	}
 
@@ -112,6 +165,63 @@ func (i *Implementation) CreateOrder(ctx context.Context, o order.CreateOrder) (
 	return orderID, nil
 }
 
+// abortCreateOrder отменяет частично созданный заказ: отменяет заказ, снимает бронь и возвращает скидку.
+// Пустые ID пропускаются, ошибки только логируются, чтобы вернуть вызывающему исходную ошибку
+func (i *Implementation) abortCreateOrder(ctx context.Context,
+	orderID order.ID,
+	itemID domainProducts.ItemID,
+	discountID referral.RewardID,
+) {
+	if orderID.ID != "" {
+		c, _ := order.NewChangeOrderStatus(order.Form, order.Cancelled)
+		if err := i.orderRepo.UpdateOrderStatus(ctx, orderID, c); err != nil {
+			logger.Errorf(ctx, "error cancel order %s: %v", orderID, err)
+		}
+	}
+
+	if itemID.ID != "" {
+		if err := i.productUC.DereserveItem(ctx, itemID); err != nil {
+			logger.Errorf(ctx, "error dereserve item %s: %v", itemID, err)
+		}
+	}
+
+	if !discountID.IsEmpty() {
+		if err := i.referralUC.ReleaseDiscount(ctx, discountID); err != nil {
+			logger.Errorf(ctx, "error release discount %s: %v", discountID, err)
+		}
+	}
+}
+
+// fulfillFreeOrder передает бесплатный заказ в исполнение без выставления счета.
+// Бесплатный продукт выдается пользователю только один раз
+func (i *Implementation) fulfillFreeOrder(ctx context.Context,
+	orderID order.ID,
+	userID domainUser.ID,
+	productID domainProducts.ID,
+	itemID domainProducts.ItemID,
+) error {
+	err := i.orderRepo.ClaimFreeProduct(ctx, userID, productID, orderID)
+	if err != nil {
+		_ = i.productUC.DereserveItem(ctx, itemID)
+		c, _ := order.NewChangeOrderStatus(order.Form, order.Cancelled)
+		_ = i.orderRepo.UpdateOrderStatus(ctx, orderID, c)
+		return err
+	}
+
+	err = i.productUC.ReserveItem(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	c, _ := order.NewChangeOrderStatus(order.Form, order.Processing)
+	err = i.orderRepo.UpdateOrderStatus(ctx, orderID, c)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (i *Implementation) GetOrder(ctx context.Context, oID order.ID, userID domainUser.ID) (order.Order, error) {
 	o, err := i.orderRepo.GetOrder(ctx, oID)
 	if err != nil {
@@ -122,31 +232,31 @@ func (i *Implementation) GetOrder(ctx context.Context, oID order.ID, userID doma
 		return order.Order{}, custom_errors.NewForbiddenError(errors.New("not user order"))
 	}
 
-	item, err := i.productUC.GetItem(ctx, o.Product.ItemID)
+	err = i.fillProduct(ctx, &o)
 	if err != nil {
 		return order.Order{}, err
 	}
 
-	product, err := i.productUC.GetProduct(ctx, item.ProductID)
+	return o, nil
+}
+
+// RevealOrderPayload повторно открывает полезную нагрузку выполненного заказа пользователя
+func (i *Implementation) RevealOrderPayload(ctx context.Context, oID order.ID, userID domainUser.ID) (string, error) {
+	o, err := i.GetOrder(ctx, oID, userID)
 	if err != nil {
-		return order.Order{}, err
+		return "", err
 	}
 
-	o.SetProduct(order.Product{
-		ItemID:      o.Product.ItemID,
-		ProductID:   item.ProductID,
-		Title:       product.Name,
-		Description: product.Description,
-	})
-
-	o.Product.ProductID = item.ProductID
+	if o.Status != order.Performed {
+		return "", custom_errors.NewBadRequestError(errors.New("order is not performed"))
+	}
 
-	return o, nil
+	return i.productUC.RevealPayload(ctx, o.Product.ItemID)
 }
 
 // GetOrderList список заказов
 func (i *Implementation) GetOrderList(ctx context.Context, userID domainUser.ID, list order.RequestList) ([]order.Order, error) {
-	if list.Filters == nil {
+	if list.Filters != nil {
 		list.Filters.UserID = userID
 	} else {
 		list.Filters = &order.Filters{
@@ -161,29 +271,48 @@ func (i *Implementation) GetOrderList(ctx context.Context, userID domainUser.ID,
 
 	result := make([]order.Order, 0, len(orders))
 	for _, o := range orders {
-		item, err := i.productUC.GetItem(ctx, o.Product.ItemID)
+		err := i.fillProduct(ctx, &o)
 		if err != nil {
 			return []order.Order{}, err
 		}
 
-		product, err := i.productUC.GetProduct(ctx, item.ProductID)
-		if err != nil {
-			return []order.Order{}, err
-		}
+		result = append(result, o)
+	}
 
-		o.SetProduct(order.Product{
-			ItemID:      o.Product.ItemID,
-			ProductID:   item.ProductID,
-			Title:       product.Name,
-			Description: product.Description,
-		})
+	return result, nil
+}
 
-		o.Product.ProductID = item.ProductID
+// fillProduct заполняет данные продукта и тарифа заказа
+func (i *Implementation) fillProduct(ctx context.Context, o *order.Order) error {
+	item, err := i.productUC.GetItem(ctx, o.Product.ItemID)
+	if err != nil {
+		return err
+	}
 
-		result = append(result, o)
+	product, err := i.productUC.GetProduct(ctx, item.ProductID)
+	if err != nil {
+		return err
 	}
 
-	return result, nil
+	var tariffName string
+	if !o.Product.TariffID.IsEmpty() {
+		tariff, err := i.productUC.GetTariff(ctx, o.Product.TariffID)
+		if err != nil {
+			return err
+		}
+		tariffName = tariff.Name
+	}
+
+	o.SetProduct(order.Product{
+		ItemID:      o.Product.ItemID,
+		ProductID:   item.ProductID,
+		TariffID:    o.Product.TariffID,
+		Title:       product.Name,
+		TariffName:  tariffName,
+		Description: product.Description,
+	})
+
+	return nil
 }
 
 // PaymentHandling перевод заказа в статус "обработка оплаты"
@@ -231,10 +360,9 @@ func (i *Implementation) Processing(ctx context.Context, oID order.ID) error {
 		return errU
 	}
 
-	err = i.communicationClient.SendMessage(ctx, communication.Message{
-		ChatID:      user.Contact.TelegramBotChatID,
-		Title:       "Заказ № " + o.ID.String(),
-		Description: "Заказ оплачен, ожидайте товар придет отдельным сообщением",
+	err = i.userUC.Notify(ctx, user, communication.Message{
+		Title:       user.Lang().T(locale.OrderTitle, o.ID),
+		Description: user.Lang().T(locale.OrderPaid),
 	})
 	if err != nil {
 		return err
@@ -269,10 +397,9 @@ func (i *Implementation) Canceled(ctx context.Context, oID order.ID) error {
 		return errU
 	}
 
-	err = i.communicationClient.SendMessage(ctx, communication.Message{
-		ChatID:      user.Contact.TelegramBotChatID,
-		Title:       "Заказ № " + o.ID.String(),
-		Description: "Отменен по истечению времени жизни",
+	err = i.userUC.Notify(ctx, user, communication.Message{
+		Title:       user.Lang().T(locale.OrderTitle, o.ID),
+		Description: user.Lang().T(locale.OrderExpired),
 	})
 	if err != nil {
 		return err
@@ -283,5 +410,62 @@ func (i *Implementation) Canceled(ctx context.Context, oID order.ID) error {
 		return err
 	}
 
-	return nil
+	return i.referralUC.ReleaseOrderDiscount(ctx, oID)
+}
+
+// CancelUserOrders отменяет неоплаченные заказы пользователя и возвращает кол-во отмененных заказов.
+// Заказы с начатой оплатой не отменяются, чтобы не потерять платеж.
+// Заказ, который не удалось отменить, пропускается, остальные заказы отменяются
+func (i *Implementation) CancelUserOrders(ctx context.Context, userID domainUser.ID) (int, error) {
+	var cancelled int
+	// skipped кол-во пропущенных заказов, они остаются в выборке, поэтому пропускаются смещением
+	var skipped uint64
+	for {
+		orders, err := i.orderRepo.GetOrders(ctx, order.RequestList{
+			Pagination: &primitives.Pagination{
+				Num:    15,
+				Offset: skipped,
+			},
+			Filters: &order.Filters{
+				Statuses: []order.Status{order.ExpectPayments, order.Form},
+				UserID:   userID,
+			},
+			Sort: &order.Sort{
+				CreatedAt: primitives.Descending,
+			},
+		})
+		if err != nil {
+			return cancelled, err
+		}
+
+		if len(orders) == 0 {
+			return cancelled, nil
+		}
+
+		for _, o := range orders {
+			c, err := order.NewChangeOrderStatus(o.Status, order.Cancelled)
+			if err != nil {
+				logger.Errorf(ctx, "error cancel order %s: %v", o.ID, err)
+				skipped++
+				continue
+			}
+
+			// заказ отменяется до снятия брони, чтобы его нельзя было оплатить после возврата единицы в продажу
+			if err = i.orderRepo.UpdateOrderStatus(ctx, o.ID, c); err != nil {
+				logger.Errorf(ctx, "error cancel order %s: %v", o.ID, err)
+				skipped++
+				continue
+			}
+			cancelled++
+
+			// заказ уже отменен и вышел из выборки, ошибки снятия брони и возврата скидки только логируются
+			if err = i.productUC.DereserveItem(ctx, o.Product.ItemID); err != nil {
+				logger.Errorf(ctx, "error dereserve item of order %s: %v", o.ID, err)
+			}
+
+			if err = i.referralUC.ReleaseOrderDiscount(ctx, o.ID); err != nil {
+				logger.Errorf(ctx, "error release discount of order %s: %v", o.ID, err)
+			}
+		}
+	}
 }
@@ -29,6 +29,7 @@ type paymentRepo interface {
 		ctx context.Context,
 		rrq domainPayment.RequestList,
 	) ([]domainPayment.Invoice, error)
+	GetUserInvoices(ctx context.Context, userID user.ID) ([]domainPayment.Invoice, error)
 }
 
 type orderUC interface {
@@ -68,6 +69,11 @@ func (i *Implementation) CreateInvoice(ctx context.Context,
 		return domainPayment.ReleaseInvoice{}, err
 	}
 
+	// бесплатные заказы исполняются без оплаты, телеграм не принимает счета на 0
+	if o.IsFree() {
+		return domainPayment.ReleaseInvoice{}, custom_errors.NewBadRequestError(errors.New("order is free"))
+	}
+
 	invoiceID, err := i.paymentRepo.CreateInvoice(ctx, domainPayment.Invoice{
 		OrderID:       invoice.OrderID,
 		State:         domainPayment.ExpectPaymentState,
@@ -78,13 +84,18 @@ func (i *Implementation) CreateInvoice(ctx context.Context,
 		return domainPayment.ReleaseInvoice{}, err
 	}
 
+	title := o.Product.Title
+	if o.Product.TariffName != "" {
+		title += " (" + o.Product.TariffName + ")"
+	}
+
 	return domainPayment.ReleaseInvoice{
 		ID:            invoiceID,
 		Price:         o.TotalPrice,
 		PaymentMethod: invoice.PaymentMethod,
 		TelegramData:  invoice.TelegramData,
 		Position: domainPayment.Position{
-			Title:       o.Product.Title,
+			Title:       title,
 			Description: o.Product.Description,
 			Price:       o.TotalPrice,
 		},
@@ -95,6 +106,11 @@ func (i *Implementation) GetInvoice(ctx context.Context, id domainPayment.ID) (d
 	return i.paymentRepo.GetInvoice(ctx, id)
 }
 
+// GetUserInvoices возвращает счета по заказам пользователя
+func (i *Implementation) GetUserInvoices(ctx context.Context, userID user.ID) ([]domainPayment.Invoice, error) {
+	return i.paymentRepo.GetUserInvoices(ctx, userID)
+}
+
 func (i *Implementation) Handling(ctx context.Context,
 	id domainPayment.ID,
 	providerID domainPayment.ProviderID,
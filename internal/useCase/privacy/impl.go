@@ -0,0 +1,144 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/payment"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	"github.com/kdv2001/onlySubscription/internal/domain/privacy"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+type userUC interface {
+	GetUser(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	Anonymize(ctx context.Context, id domainUser.ID) error
+}
+
+type orderUC interface {
+	GetOrderList(ctx context.Context, userID domainUser.ID, list order.RequestList) ([]order.Order, error)
+	CancelUserOrders(ctx context.Context, userID domainUser.ID) (int, error)
+}
+
+type paymentUC interface {
+	GetUserInvoices(ctx context.Context, userID domainUser.ID) ([]payment.Invoice, error)
+}
+
+type subscriptionUC interface {
+	GetUserSubscriptions(ctx context.Context,
+		userID domainUser.ID,
+		pagination primitives.Pagination,
+	) ([]subscription.Subscription, error)
+}
+
+type Implementation struct {
+	userUC         userUC
+	orderUC        orderUC
+	paymentUC      paymentUC
+	subscriptionUC subscriptionUC
+}
+
+func NewImplementation(userUC userUC,
+	orderUC orderUC,
+	paymentUC paymentUC,
+	subscriptionUC subscriptionUC,
+) *Implementation {
+	return &Implementation{
+		userUC:         userUC,
+		orderUC:        orderUC,
+		paymentUC:      paymentUC,
+		subscriptionUC: subscriptionUC,
+	}
+}
+
+// pageSize кол-во записей, запрашиваемых за раз при выгрузке
+const pageSize = 15
+
+// Export собирает профиль, заказы, счета и подписки пользователя
+func (i *Implementation) Export(ctx context.Context, userID domainUser.ID) (privacy.Export, error) {
+	user, err := i.userUC.GetUser(ctx, userID)
+	if err != nil {
+		return privacy.Export{}, err
+	}
+
+	orders := make([]order.Order, 0)
+	for offset := uint64(0); ; offset += pageSize {
+		page, err := i.orderUC.GetOrderList(ctx, userID, order.RequestList{
+			Pagination: &primitives.Pagination{
+				Num:    pageSize,
+				Offset: offset,
+			},
+			Sort: &order.Sort{
+				CreatedAt: primitives.Descending,
+			},
+		})
+		if err != nil {
+			return privacy.Export{}, err
+		}
+
+		orders = append(orders, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	invoices, err := i.paymentUC.GetUserInvoices(ctx, userID)
+	if err != nil {
+		return privacy.Export{}, err
+	}
+
+	subscriptions := make([]subscription.Subscription, 0)
+	for offset := uint64(0); ; offset += pageSize {
+		page, err := i.subscriptionUC.GetUserSubscriptions(ctx, userID, primitives.Pagination{
+			Num:    pageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return privacy.Export{}, err
+		}
+
+		subscriptions = append(subscriptions, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	return privacy.Export{
+		User:          user,
+		Orders:        orders,
+		Invoices:      invoices,
+		Subscriptions: subscriptions,
+		CreatedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// Delete удаляет данные telegram пользователя и отменяет его неоплаченные заказы.
+// Заказы, счета и подписки сохраняются обезличенными. Данные сотрудника с ролью удалить нельзя,
+// сначала нужно снять роль
+func (i *Implementation) Delete(ctx context.Context, userID domainUser.ID) (privacy.Deletion, error) {
+	user, err := i.userUC.GetUser(ctx, userID)
+	if err != nil {
+		return privacy.Deletion{}, err
+	}
+
+	if user.Role != domainUser.CustomerRole {
+		return privacy.Deletion{}, custom_errors.NewForbiddenError(privacy.ErrStaffDeletion)
+	}
+
+	cancelled, err := i.orderUC.CancelUserOrders(ctx, userID)
+	if err != nil {
+		return privacy.Deletion{}, err
+	}
+
+	if err = i.userUC.Anonymize(ctx, userID); err != nil {
+		return privacy.Deletion{}, err
+	}
+
+	return privacy.Deletion{
+		User:            user,
+		CancelledOrders: cancelled,
+	}, nil
+}
@@ -2,52 +2,67 @@ package products
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
 	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
 	"github.com/kdv2001/onlySubscription/pkg/parallel"
 )
 
 // RunBackgroundProcess запускает фоновые процессы
 func (i *Implementation) RunBackgroundProcess(ctx context.Context, wg *sync.WaitGroup) error {
 	go parallel.BackgroundPeriodProcess(ctx, wg, 20*time.Second, i.updateExpiredItems)
+	go parallel.BackgroundPeriodProcess(ctx, wg, 30*time.Second, i.announceScheduledStarts)
+	go parallel.BackgroundPeriodProcess(ctx, wg, 10*time.Second, i.realizedItems)
+	go parallel.BackgroundPeriodProcess(ctx, wg, time.Hour, i.purgeRealizedPayloads)
 	return nil
 }
 
-// updateExpiredItems снимает пререзервацию с продуктов
-func (i *Implementation) updateExpiredItems(ctx context.Context) error {
-	items, errG := i.productsRepo.GetItems(ctx, domainProducts.RequestList{
-		Pagination: &primitives.Pagination{
-			Num: maxProcessingItems,
-		},
-		Filters: &domainProducts.Filters{
-			UpdatedAt: &primitives.IntervalFilter[time.Time]{
-				To: time.Now().UTC().Add(-consts.DefaultPrereservedTTL),
-			},
-			Statuses: []domainProducts.ItemStatus{domainProducts.PreReservedStatus},
-		},
-	})
-	if errG != nil {
-		return errG
+// announceScheduledStarts сообщает администраторам о начале запланированных публикаций и распродаж
+func (i *Implementation) announceScheduledStarts(ctx context.Context) error {
+	products, err := i.productsRepo.GetNotAnnouncedPublications(ctx, maxProcessingItems)
+	if err != nil {
+		return err
 	}
 
-	for _, item := range items {
-		c, err := domainProducts.NewChangeItemStatus(item.Status, domainProducts.SaleStatus)
-		if err != nil {
-			if errors.Is(err, domainProducts.ErrStatusIsEqual) {
-				return nil
-			}
+	// язык администраторов не хранится, объявления отправляются на языке по умолчанию
+	lang := locale.Default
+	for _, p := range products {
+		i.notifyAdmins(ctx, communication.Message{
+			Title: lang.T(locale.PublicationStartedTitle),
+			Description: lang.T(locale.PublicationStartedText,
+				p.Name, p.ID, p.PublishFrom.Format(consts.DateTimeLayout)),
+		})
 
+		if err = i.productsRepo.MarkPublicationAnnounced(ctx, p.ID); err != nil {
 			return err
 		}
+	}
+
+	sales, err := i.productsRepo.GetNotAnnouncedSales(ctx, maxProcessingItems)
+	if err != nil {
+		return err
+	}
 
-		err = i.productsRepo.ChangeItemStatus(ctx, item.ID, c)
+	for _, s := range sales {
+		tariff, err := i.productsRepo.GetTariff(ctx, s.TariffID)
 		if err != nil {
+			return err
+		}
+
+		i.notifyAdmins(ctx, communication.Message{
+			Title: lang.T(locale.SaleStartedTitle),
+			Description: lang.T(locale.SaleStartedText,
+				tariff.Name, s.Price.Value, s.Price.Currency, tariff.Price.Value,
+				s.EndsAt.Format(consts.DateTimeLayout)),
+		})
 
+		if err = i.productsRepo.MarkSaleAnnounced(ctx, s.ID); err != nil {
 			return err
 		}
 	}
@@ -55,14 +70,27 @@ func (i *Implementation) updateExpiredItems(ctx context.Context) error {
 	return nil
 }
 
-// realizedItems переводит item в статус реализован
-func (i *Implementation) realizedItems(ctx context.Context) error {
+// notifyAdmins отправляет сообщение всем администраторам
+func (i *Implementation) notifyAdmins(ctx context.Context, msg communication.Message) {
+	for _, chatID := range i.adminChatIDs {
+		msg.ChatID = chatID
+		if err := i.communicationClient.SendMessage(ctx, msg); err != nil {
+			logger.Errorf(ctx, "error send admin msg: %v", err)
+		}
+	}
+}
+
+// updateExpiredItems снимает пререзервацию с продуктов
+func (i *Implementation) updateExpiredItems(ctx context.Context) error {
 	items, errG := i.productsRepo.GetItems(ctx, domainProducts.RequestList{
 		Pagination: &primitives.Pagination{
 			Num: maxProcessingItems,
 		},
 		Filters: &domainProducts.Filters{
-			Statuses: []domainProducts.ItemStatus{domainProducts.PerformedStatus},
+			UpdatedAt: &primitives.IntervalFilter[time.Time]{
+				To: time.Now().UTC().Add(-consts.DefaultPrereservedTTL),
+			},
+			Statuses: []domainProducts.ItemStatus{domainProducts.PreReservedStatus},
 		},
 	})
 	if errG != nil {
@@ -70,18 +98,7 @@ func (i *Implementation) realizedItems(ctx context.Context) error {
 	}
 
 	for _, item := range items {
-		c, err := domainProducts.NewChangeItemStatus(item.Status, domainProducts.RealizedStatus)
-		if err != nil {
-			if errors.Is(err, domainProducts.ErrStatusIsEqual) {
-				return nil
-			}
-
-			return err
-		}
-		// TODO какая-то постобработка элемента
-		err = i.productsRepo.ChangeItemStatus(ctx, item.ID, c)
-		if err != nil {
-
+		if err := i.DereserveItem(ctx, item.ID); err != nil {
 			return err
 		}
 	}
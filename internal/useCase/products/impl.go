@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/price"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/pkg/envelope"
 	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
@@ -15,13 +20,27 @@ const maxProductsSize = 15
 const maxProcessingItems = 15
 
 type productsRepo interface {
-	GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.Products, error)
+	GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.ProductsPage, error)
 	CreateProduct(ctx context.Context, req domainProducts.Product) (domainProducts.ID, error)
-	DeleteProduct(ctx context.Context, id domainProducts.ID) error
+	DeleteProduct(ctx context.Context, req domainProducts.Deactivation) error
+	RestoreProduct(ctx context.Context, id domainProducts.ID) error
 	GetProduct(ctx context.Context, id domainProducts.ID) (domainProducts.Product, error)
 
+	CreateTariff(ctx context.Context, req domainProducts.Tariff) (domainProducts.TariffID, error)
+	GetTariffs(ctx context.Context, productID domainProducts.ID) (domainProducts.Tariffs, error)
+	GetTariff(ctx context.Context, id domainProducts.TariffID) (domainProducts.Tariff, error)
+	DeleteTariff(ctx context.Context, id domainProducts.TariffID) error
+
+	SetPublication(ctx context.Context, id domainProducts.ID, publication domainProducts.Publication) error
+	GetNotAnnouncedPublications(ctx context.Context, num uint64) (domainProducts.Products, error)
+	MarkPublicationAnnounced(ctx context.Context, id domainProducts.ID) error
+	CreateSale(ctx context.Context, req domainProducts.Sale) (domainProducts.SaleID, error)
+	GetNotAnnouncedSales(ctx context.Context, num uint64) ([]domainProducts.Sale, error)
+	MarkSaleAnnounced(ctx context.Context, id domainProducts.SaleID) error
+
 	CreateInventoryItem(ctx context.Context, req domainProducts.Item) (domainProducts.ItemID, error)
 	DeleteInventoryItem(ctx context.Context, id domainProducts.ItemID) error
+	ReleaseSharedCopy(ctx context.Context, id domainProducts.ItemID) error
 
 	PreReservedSProduct(ctx context.Context, itemID domainProducts.ID) (domainProducts.ItemID, error)
 	ChangeItemStatus(ctx context.Context,
@@ -33,64 +52,161 @@ type productsRepo interface {
 		ctx context.Context,
 		req domainProducts.RequestList,
 	) ([]domainProducts.Item, error)
-	CountItemsForProduct(ctx context.Context, productID domainProducts.ID) (int64, error)
+	GetStock(ctx context.Context, productID domainProducts.ID) (domainProducts.Stock, error)
+	SetStockMode(ctx context.Context, id domainProducts.ID, mode domainProducts.StockMode, limit int64) error
+	SetAccessChat(ctx context.Context, id domainProducts.ID, chatID int64) error
+	SetSharedPayload(ctx context.Context, productID domainProducts.ID, payload domainProducts.EncryptedPayload) error
+	GetItemsForRekey(ctx context.Context, activeKeyID string, num uint64) ([]domainProducts.Item, error)
+	UpdateItemPayload(ctx context.Context, id domainProducts.ItemID, payload domainProducts.EncryptedPayload) error
+
+	ConfirmItemDelivery(ctx context.Context, id domainProducts.ItemID) error
+	GetDeliveredItems(ctx context.Context, num uint64) ([]domainProducts.Item, error)
+	MarkItemRealized(ctx context.Context, id domainProducts.ItemID) error
+	MarkPostSaleFailed(ctx context.Context, id domainProducts.ItemID, nextAttemptAt time.Time) error
+	PurgeRealizedPayloads(ctx context.Context, before time.Time, num uint64) (int64, error)
+
+	SaveTranslation(ctx context.Context, t domainProducts.Translation) error
+	DeleteTranslation(ctx context.Context, id domainProducts.ID, lang locale.Lang) error
+	GetTranslations(ctx context.Context, ids ...domainProducts.ID) (map[domainProducts.ID]domainProducts.Translations, error)
+}
+
+type payloadCipher interface {
+	ActiveKeyID() string
+	Seal(plaintext []byte) (envelope.Sealed, error)
+	Open(sealed envelope.Sealed) ([]byte, error)
+	Rewrap(sealed envelope.Sealed) (envelope.Sealed, error)
+}
+
+type communicationClient interface {
+	SendMessage(ctx context.Context, message communication.Message) error
 }
 
 type Implementation struct {
-	productsRepo   productsRepo
+	productsRepo        productsRepo
+	communicationClient communicationClient
+	payloadCipher       payloadCipher
+	// adminChatIDs чаты администраторов для служебных уведомлений
+	adminChatIDs   []int64
+	postSale       PostSale
 	preselectedTTL time.Duration
 }
 
-func NewImplementation(productsRepo productsRepo) *Implementation {
+func NewImplementation(productsRepo productsRepo,
+	communicationClient communicationClient,
+	payloadCipher payloadCipher,
+	adminChatIDs []int64,
+	postSale PostSale,
+) *Implementation {
 	return &Implementation{
-		productsRepo: productsRepo,
+		productsRepo:        productsRepo,
+		communicationClient: communicationClient,
+		payloadCipher:       payloadCipher,
+		adminChatIDs:        adminChatIDs,
+		postSale:            postSale,
 	}
 }
 
-// GetProducts возвращает набор продуктов
-func (i *Implementation) GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.Products, error) {
-	if req.Pagination != nil && req.Pagination.Num > maxProductsSize {
-		return nil, custom_errors.NewBadRequestError(errors.New("bad products num")).
+// GetProducts возвращает страницу продуктов
+func (i *Implementation) GetProducts(ctx context.Context, req domainProducts.RequestList) (domainProducts.ProductsPage, error) {
+	if req.Pagination == nil || req.Pagination.Num > maxProductsSize {
+		return domainProducts.ProductsPage{}, custom_errors.NewBadRequestError(errors.New("bad products num")).
 			SetDescription(fmt.Sprintf("request cards num grater than %d", maxProductsSize))
 	}
 
-	res, err := i.productsRepo.GetProducts(ctx, req)
+	page, err := i.productsRepo.GetProducts(ctx, req)
 	if err != nil {
-		return nil, err
+		return page, err
 	}
 
-	if req.Filters != nil && req.Filters.ItemsExist {
-		result := make(domainProducts.Products, 0, len(res))
-		for _, r := range res {
-			num, err := i.productsRepo.CountItemsForProduct(ctx, r.ID)
-			if err != nil {
-				return nil, err
-			}
-			if num == 0 {
-				continue
-			}
+	ids := make([]domainProducts.ID, 0, len(page.Products))
+	for _, p := range page.Products {
+		ids = append(ids, p.ID)
+	}
 
-			result = append(result, r)
-		}
-		res = result
+	translations, err := i.productsRepo.GetTranslations(ctx, ids...)
+	if err != nil {
+		return page, err
+	}
+
+	for j := range page.Products {
+		page.Products[j].Translations = translations[page.Products[j].ID]
 	}
 
-	return res, nil
+	return page, nil
 }
 
-// GetProduct возвращает данные продукта
+// GetProduct возвращает данные продукта вместе с тарифами и состоянием склада
 func (i *Implementation) GetProduct(ctx context.Context, id domainProducts.ID) (domainProducts.Product, error) {
 	product, err := i.productsRepo.GetProduct(ctx, id)
 	if err != nil {
 		return product, err
 	}
 
+	product.Tariffs, err = i.productsRepo.GetTariffs(ctx, id)
+	if err != nil {
+		return product, err
+	}
+
+	product.Stock, err = i.productsRepo.GetStock(ctx, id)
+	if err != nil {
+		return product, err
+	}
+
+	translations, err := i.productsRepo.GetTranslations(ctx, id)
+	if err != nil {
+		return product, err
+	}
+	product.Translations = translations[id]
+
 	return product, nil
 }
 
-// DeactivateProduct деактивирует продукт
-func (i *Implementation) DeactivateProduct(ctx context.Context, id domainProducts.ID) error {
-	err := i.productsRepo.DeleteProduct(ctx, id)
+// SetTranslation сохраняет перевод названия и описания продукта
+func (i *Implementation) SetTranslation(ctx context.Context, t domainProducts.Translation) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return custom_errors.NewBadRequestError(domainProducts.ErrEmptyTranslationName)
+	}
+
+	if _, err := i.productsRepo.GetProduct(ctx, t.ProductID); err != nil {
+		return err
+	}
+
+	return i.productsRepo.SaveTranslation(ctx, t)
+}
+
+// DeleteTranslation удаляет перевод продукта на язык lang
+func (i *Implementation) DeleteTranslation(ctx context.Context, id domainProducts.ID, lang locale.Lang) error {
+	return i.productsRepo.DeleteTranslation(ctx, id, lang)
+}
+
+// DeactivateProduct деактивирует продукт и обрабатывает его непроданные единицы инвентаря
+func (i *Implementation) DeactivateProduct(ctx context.Context, req domainProducts.Deactivation) error {
+	product, err := i.productsRepo.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product.Archived {
+		return custom_errors.NewBadRequestError(errors.New("product already archived"))
+	}
+
+	if req.Unsold == domainProducts.MoveUnsold {
+		if req.TargetProductID == req.ProductID {
+			return custom_errors.NewBadRequestError(errors.New("move items to the same product"))
+		}
+
+		target, err := i.productsRepo.GetProduct(ctx, req.TargetProductID)
+		if err != nil {
+			return err
+		}
+
+		if target.Archived {
+			return custom_errors.NewBadRequestError(errors.New("target product is archived"))
+		}
+	}
+
+	err = i.productsRepo.DeleteProduct(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -98,8 +214,33 @@ func (i *Implementation) DeactivateProduct(ctx context.Context, id domainProduct
 	return nil
 }
 
-// CreateProduct возвращает набор продуктов
+// RestoreProduct восстанавливает продукт из архива
+func (i *Implementation) RestoreProduct(ctx context.Context, id domainProducts.ID) error {
+	product, err := i.productsRepo.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !product.Archived {
+		return custom_errors.NewBadRequestError(errors.New("product is not archived"))
+	}
+
+	return i.productsRepo.RestoreProduct(ctx, id)
+}
+
+// CreateProduct создает продукт вместе с его тарифами
 func (i *Implementation) CreateProduct(ctx context.Context, product domainProducts.Product) error {
+	if len(product.Tariffs) == 0 {
+		return custom_errors.NewBadRequestError(errors.New("product without tariffs")).
+			SetDescription("product must have at least one tariff")
+	}
+
+	for _, t := range product.Tariffs {
+		if err := validateTariff(t); err != nil {
+			return err
+		}
+	}
+
 	_, err := i.productsRepo.CreateProduct(ctx, product)
 	if err != nil {
 		return err
@@ -108,13 +249,146 @@ func (i *Implementation) CreateProduct(ctx context.Context, product domainProduc
 	return nil
 }
 
-// AddItem добавляет элемент в инвентарь
+// AddTariff добавляет тариф продукту
+func (i *Implementation) AddTariff(ctx context.Context, tariff domainProducts.Tariff) error {
+	_, err := i.productsRepo.GetProduct(ctx, tariff.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if err = validateTariff(tariff); err != nil {
+		return err
+	}
+
+	_, err = i.productsRepo.CreateTariff(ctx, tariff)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetTariff возвращает тариф
+func (i *Implementation) GetTariff(ctx context.Context, id domainProducts.TariffID) (domainProducts.Tariff, error) {
+	return i.productsRepo.GetTariff(ctx, id)
+}
+
+// DeleteTariff деактивирует тариф
+func (i *Implementation) DeleteTariff(ctx context.Context, id domainProducts.TariffID) error {
+	return i.productsRepo.DeleteTariff(ctx, id)
+}
+
+// SetPublication устанавливает окно публикации продукта
+func (i *Implementation) SetPublication(ctx context.Context,
+	id domainProducts.ID,
+	publication domainProducts.Publication,
+) error {
+	_, err := i.productsRepo.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return i.productsRepo.SetPublication(ctx, id, publication)
+}
+
+// SetStockMode устанавливает режим склада продукта.
+// Лимит продаж учитывается только в режиме CappedStock
+func (i *Implementation) SetStockMode(ctx context.Context,
+	id domainProducts.ID,
+	mode domainProducts.StockMode,
+	limit int64,
+) error {
+	_, err := i.productsRepo.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if mode != domainProducts.CappedStock {
+		limit = 0
+	} else if limit <= 0 {
+		return custom_errors.NewBadRequestError(errors.New("non-positive stock limit"))
+	}
+
+	return i.productsRepo.SetStockMode(ctx, id, mode, limit)
+}
+
+// SetAccessChat задает приватный канал или группу, доступ в которые выдает подписка на продукт.
+// chatID 0 отключает выдачу доступа
+func (i *Implementation) SetAccessChat(ctx context.Context, id domainProducts.ID, chatID int64) error {
+	_, err := i.productsRepo.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return i.productsRepo.SetAccessChat(ctx, id, chatID)
+}
+
+// AddSale планирует распродажу тарифа
+func (i *Implementation) AddSale(ctx context.Context, sale domainProducts.Sale) error {
+	tariff, err := i.productsRepo.GetTariff(ctx, sale.TariffID)
+	if err != nil {
+		return err
+	}
+
+	if !sale.EndsAt.After(sale.StartsAt) {
+		return custom_errors.NewBadRequestError(domainProducts.ErrInvalidWindow)
+	}
+
+	if tariff.IsFree() {
+		return custom_errors.NewBadRequestError(errors.New("sale for free tariff"))
+	}
+
+	// платный тариф выдается только после оплаты, поэтому распродажа не может обнулить цену
+	if !sale.Price.Value.IsPositive() {
+		return custom_errors.NewBadRequestError(errors.New("sale price must be positive"))
+	}
+
+	// распродажа всегда в валюте тарифа
+	sale.Price.Currency = tariff.Price.Currency
+	_, err = i.productsRepo.CreateSale(ctx, sale)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateTariff проверяет корректность тарифа
+func validateTariff(t domainProducts.Tariff) error {
+	if t.Name == "" {
+		return custom_errors.NewBadRequestError(errors.New("empty tariff name"))
+	}
+
+	if t.Price.Currency == price.UNKNOWN {
+		return custom_errors.NewBadRequestError(errors.New("unknown tariff currency"))
+	}
+
+	if t.Price.Value.IsNegative() {
+		return custom_errors.NewBadRequestError(errors.New("negative tariff price"))
+	}
+
+	return nil
+}
+
+// AddItem добавляет элемент в инвентарь.
+// Для продуктов с общей полезной нагрузкой заменяет ее
 func (i *Implementation) AddItem(ctx context.Context, item domainProducts.Item) error {
-	_, err := i.productsRepo.GetProduct(ctx, item.ProductID)
+	product, err := i.productsRepo.GetProduct(ctx, item.ProductID)
+	if err != nil {
+		return err
+	}
+
+	item.EncryptedPayload, err = i.sealPayload(item.Payload)
 	if err != nil {
 		return err
 	}
 
+	if product.StockMode.IsShared() {
+		return i.productsRepo.SetSharedPayload(ctx, product.ID, item.EncryptedPayload)
+	}
+	// в открытом виде полезная нагрузка не сохраняется
+	item.Payload = ""
+
 	// Реализация добавления товара в инвентарь
 	_, err = i.productsRepo.CreateInventoryItem(ctx, item)
 	if err != nil {
@@ -140,6 +414,104 @@ func (i *Implementation) GetItem(ctx context.Context, id domainProducts.ItemID)
 	return i.productsRepo.GetItem(ctx, id)
 }
 
+// RevealPayload возвращает полезную нагрузку в открытом виде.
+// Используется только при выдаче товара покупателю
+func (i *Implementation) RevealPayload(ctx context.Context, id domainProducts.ItemID) (string, error) {
+	item, err := i.productsRepo.GetItem(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if !item.PayloadPurgedAt.IsZero() {
+		return "", custom_errors.NewNotFoundError(domainProducts.ErrPayloadPurged)
+	}
+
+	// записи, созданные до включения шифрования
+	if item.EncryptedPayload.IsEmpty() {
+		return item.Payload, nil
+	}
+
+	plaintext, err := i.payloadCipher.Open(envelope.Sealed{
+		KeyID:        item.EncryptedPayload.KeyID,
+		EncryptedKey: item.EncryptedPayload.EncryptedKey,
+		Data:         item.EncryptedPayload.Data,
+	})
+	if err != nil {
+		return "", custom_errors.NewInternalError(err).AddDetails("error decrypt payload")
+	}
+
+	return string(plaintext), nil
+}
+
+// RotatePayloadKeys перешифровывает ключи данных активным мастер-ключом
+// и шифрует записи, созданные до включения шифрования.
+// Возвращает кол-во обработанных единиц инвентаря
+func (i *Implementation) RotatePayloadKeys(ctx context.Context) (int, error) {
+	processed := 0
+	for {
+		items, err := i.productsRepo.GetItemsForRekey(ctx, i.payloadCipher.ActiveKeyID(), maxProcessingItems)
+		if err != nil {
+			return processed, err
+		}
+
+		if len(items) == 0 {
+			return processed, nil
+		}
+
+		for _, item := range items {
+			var payload domainProducts.EncryptedPayload
+			if item.EncryptedPayload.IsEmpty() {
+				payload, err = i.sealPayload(item.Payload)
+			} else {
+				payload, err = i.rewrapPayload(item.EncryptedPayload)
+			}
+			if err != nil {
+				return processed, custom_errors.NewInternalError(err).
+					AddDetails("error rekey item " + item.ID.String())
+			}
+
+			err = i.productsRepo.UpdateItemPayload(ctx, item.ID, payload)
+			if err != nil {
+				return processed, err
+			}
+
+			processed++
+		}
+	}
+}
+
+// sealPayload шифрует полезную нагрузку
+func (i *Implementation) sealPayload(payload string) (domainProducts.EncryptedPayload, error) {
+	sealed, err := i.payloadCipher.Seal([]byte(payload))
+	if err != nil {
+		return domainProducts.EncryptedPayload{}, custom_errors.NewInternalError(err).AddDetails("error encrypt payload")
+	}
+
+	return domainProducts.EncryptedPayload{
+		KeyID:        sealed.KeyID,
+		EncryptedKey: sealed.EncryptedKey,
+		Data:         sealed.Data,
+	}, nil
+}
+
+// rewrapPayload перешифровывает ключ данных активным мастер-ключом
+func (i *Implementation) rewrapPayload(payload domainProducts.EncryptedPayload) (domainProducts.EncryptedPayload, error) {
+	sealed, err := i.payloadCipher.Rewrap(envelope.Sealed{
+		KeyID:        payload.KeyID,
+		EncryptedKey: payload.EncryptedKey,
+		Data:         payload.Data,
+	})
+	if err != nil {
+		return domainProducts.EncryptedPayload{}, err
+	}
+
+	return domainProducts.EncryptedPayload{
+		KeyID:        sealed.KeyID,
+		EncryptedKey: sealed.EncryptedKey,
+		Data:         sealed.Data,
+	}, nil
+}
+
 // GetItems  возвращает элементы инвентаря
 func (i *Implementation) GetItems(ctx context.Context, req domainProducts.RequestList) ([]domainProducts.Item, error) {
 	if req.Pagination != nil && req.Pagination.Num > maxProductsSize {
@@ -175,8 +547,28 @@ func (i *Implementation) ReserveItem(ctx context.Context, itemID domainProducts.
 	return i.changeItemStatus(ctx, itemID, domainProducts.ReservedStatus)
 }
 
-// DereserveItem разрезервирует элемент инвентаря
+// DereserveItem разрезервирует элемент инвентаря.
+// Копия общей полезной нагрузки не возвращается в продажу, а отмечается снятой с брони:
+// следующий покупатель получит копию актуальной нагрузки
 func (i *Implementation) DereserveItem(ctx context.Context, itemID domainProducts.ItemID) error {
+	item, err := i.productsRepo.GetItem(ctx, itemID)
+	if err != nil {
+		// копии общей полезной нагрузки раньше удалялись при снятии брони, такая бронь уже снята
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	product, err := i.productsRepo.GetProduct(ctx, item.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product.StockMode.IsShared() {
+		return i.productsRepo.ReleaseSharedCopy(ctx, itemID)
+	}
+
 	return i.changeItemStatus(ctx, itemID, domainProducts.SaleStatus)
 }
 
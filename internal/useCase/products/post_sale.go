@@ -0,0 +1,126 @@
+package products
+
+import (
+	"context"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// PostSaleHook обработчик единицы инвентаря при реализации
+type PostSaleHook interface {
+	// Name название обработчика
+	Name() string
+	// Handle обрабатывает единицу инвентаря, ошибка откладывает реализацию до следующей попытки
+	Handle(ctx context.Context, item domainProducts.Item) error
+}
+
+// PostSale настройки обработки проданных единиц инвентаря
+type PostSale struct {
+	// PayloadRetention срок хранения полезной нагрузки после реализации, 0 - хранится бессрочно
+	PayloadRetention time.Duration
+	// Hooks обработчики, выполняемые при реализации
+	Hooks []PostSaleHook
+}
+
+type eventSender interface {
+	SendEvent(ctx context.Context, e communication.Event) error
+}
+
+// itemRealizedEvent тип события реализации единицы инвентаря
+const itemRealizedEvent = "item.realized"
+
+type notifyHook struct {
+	sender eventSender
+}
+
+// NewNotifyHook создает обработчик, уведомляющий внешнюю систему о реализации единицы инвентаря
+func NewNotifyHook(sender eventSender) PostSaleHook {
+	return &notifyHook{
+		sender: sender,
+	}
+}
+
+func (h *notifyHook) Name() string {
+	return "notify"
+}
+
+func (h *notifyHook) Handle(ctx context.Context, item domainProducts.Item) error {
+	return h.sender.SendEvent(ctx, communication.Event{
+		Type:       itemRealizedEvent,
+		OccurredAt: time.Now().UTC(),
+		Data: map[string]string{
+			"item_id":      item.ID.String(),
+			"product_id":   item.ProductID.String(),
+			"delivered_at": item.DeliveredAt.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// ConfirmDelivery отмечает выдачу единицы инвентаря покупателю,
+// после чего она передается в обработку реализации
+func (i *Implementation) ConfirmDelivery(ctx context.Context, itemID domainProducts.ItemID) error {
+	return i.productsRepo.ConfirmItemDelivery(ctx, itemID)
+}
+
+// realizedItems выполняет обработчики для выданных единиц инвентаря и переводит их в статус реализован.
+// При ошибке обработчика следующая попытка откладывается, чтобы единица не занимала место в выборке
+func (i *Implementation) realizedItems(ctx context.Context) error {
+	items, err := i.productsRepo.GetDeliveredItems(ctx, maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if !i.runPostSaleHooks(ctx, item) {
+			nextAttemptAt := time.Now().UTC().Add(domainProducts.PostSaleRetryDelay(item.PostSaleAttempts + 1))
+			err = i.productsRepo.MarkPostSaleFailed(ctx, item.ID, nextAttemptAt)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = i.productsRepo.MarkItemRealized(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runPostSaleHooks выполняет обработчики по порядку, возвращает признак успешного выполнения всех
+func (i *Implementation) runPostSaleHooks(ctx context.Context, item domainProducts.Item) bool {
+	for _, hook := range i.postSale.Hooks {
+		if err := hook.Handle(ctx, item); err != nil {
+			logger.Errorf(ctx, "post sale hook %s failed for item %s: %v", hook.Name(), item.ID, err)
+			return false
+		}
+	}
+
+	return true
+}
+
+// purgeRealizedPayloads удаляет полезную нагрузку реализованных единиц инвентаря по истечении срока хранения
+func (i *Implementation) purgeRealizedPayloads(ctx context.Context) error {
+	if i.postSale.PayloadRetention <= 0 {
+		return nil
+	}
+
+	purged, err := i.productsRepo.PurgeRealizedPayloads(ctx,
+		time.Now().UTC().Add(-i.postSale.PayloadRetention),
+		maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		logger.Infof(ctx, "purged payload of %d realized items", purged)
+	}
+
+	return nil
+}
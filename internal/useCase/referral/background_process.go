@@ -0,0 +1,41 @@
+package referral
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+	"github.com/kdv2001/onlySubscription/pkg/parallel"
+)
+
+// RunBackgroundProcess запускает фоновый процесс
+func (i *Implementation) RunBackgroundProcess(ctx context.Context, wg *sync.WaitGroup) error {
+	go parallel.BackgroundPeriodProcess(ctx, wg, time.Minute, i.applyPendingRewards)
+	return nil
+}
+
+// maxProcessingItems кол-во элементов в выборке для обработки
+const maxProcessingItems = 30
+
+// applyPendingRewards начисляет ожидающие бонусные дни пользователям, оформившим подписку
+func (i *Implementation) applyPendingRewards(ctx context.Context) error {
+	rewards, err := i.referralRepo.GetPendingRewards(ctx, maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rewards {
+		user, err := i.userUC.GetUser(ctx, r.ReferrerID)
+		if err != nil {
+			logger.Errorf(ctx, "error apply referral reward: %v", err)
+			continue
+		}
+
+		if _, err = i.applyDays(ctx, r, user.Lang()); err != nil {
+			logger.Errorf(ctx, "error apply referral reward: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package referral
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/referral"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+type referralRepo interface {
+	SaveCode(ctx context.Context, userID domainUser.ID, code referral.Code) (referral.Code, error)
+	GetCode(ctx context.Context, userID domainUser.ID) (referral.Code, error)
+	GetUserByCode(ctx context.Context, code referral.Code) (domainUser.ID, error)
+	CreateReferral(ctx context.Context, r referral.Referral) error
+	GetReferral(ctx context.Context, referredID domainUser.ID) (referral.Referral, error)
+	CreateReward(ctx context.Context, r referral.Reward) (referral.Reward, error)
+	GetPendingRewards(ctx context.Context, num uint64) ([]referral.Reward, error)
+	MarkDaysApplied(ctx context.Context, id referral.RewardID, subID subscription.ID) error
+	ClaimDiscount(ctx context.Context, userID domainUser.ID) (referral.Reward, error)
+	BindDiscount(ctx context.Context, id referral.RewardID, orderID order.ID) error
+	ReleaseDiscount(ctx context.Context, id referral.RewardID) error
+	ReleaseOrderDiscount(ctx context.Context, orderID order.ID) error
+	GetStats(ctx context.Context, userID domainUser.ID) (referral.Stats, error)
+}
+
+type subscriptionUC interface {
+	AddBonusDays(ctx context.Context,
+		userID domainUser.ID,
+		days int,
+		reason string,
+	) (subscription.Subscription, error)
+}
+
+type userUC interface {
+	GetUser(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	Notify(ctx context.Context, u domainUser.User, msg communication.Message) error
+}
+
+type Implementation struct {
+	referralRepo   referralRepo
+	subscriptionUC subscriptionUC
+	userUC         userUC
+	// policy награда пригласившему за оплаченный заказ приглашенного
+	policy referral.Policy
+}
+
+func NewImplementation(referralRepo referralRepo,
+	subscriptionUC subscriptionUC,
+	userUC userUC,
+	policy referral.Policy,
+) *Implementation {
+	return &Implementation{
+		referralRepo:   referralRepo,
+		subscriptionUC: subscriptionUC,
+		userUC:         userUC,
+		policy:         policy,
+	}
+}
+
+// maxCodeAttempts кол-во попыток сгенерировать незанятый реферальный код
+const maxCodeAttempts = 5
+
+// GetCode возвращает реферальный код пользователя, при первом обращении код создается
+func (i *Implementation) GetCode(ctx context.Context, userID domainUser.ID) (referral.Code, error) {
+	code, err := i.referralRepo.GetCode(ctx, userID)
+	if !errors.Is(err, custom_errors.ErrorNotFound) {
+		return code, err
+	}
+
+	for range maxCodeAttempts {
+		code, err = referral.NewCode()
+		if err != nil {
+			return "", err
+		}
+
+		// код уже занят другим пользователем - пробуем следующий
+		code, err = i.referralRepo.SaveCode(ctx, userID, code)
+		if !errors.Is(err, custom_errors.ErrorNotFound) {
+			return code, err
+		}
+	}
+
+	return "", custom_errors.NewInternalError(errors.New("can not generate referral code"))
+}
+
+// Policy возвращает настройки награды за приглашение
+func (i *Implementation) Policy() referral.Policy {
+	return i.policy
+}
+
+// GetStats возвращает статистику приглашений пользователя
+func (i *Implementation) GetStats(ctx context.Context, userID domainUser.ID) (referral.Stats, error) {
+	code, err := i.GetCode(ctx, userID)
+	if err != nil {
+		return referral.Stats{}, err
+	}
+
+	stats, err := i.referralRepo.GetStats(ctx, userID)
+	if err != nil {
+		return referral.Stats{}, err
+	}
+	stats.Code = code
+
+	return stats, nil
+}
+
+// Attribute сохраняет, что новый пользователь referredID пришел по реферальному коду code.
+// Пользователь может быть приглашен только один раз
+func (i *Implementation) Attribute(ctx context.Context, referredID domainUser.ID, code referral.Code) error {
+	referrerID, err := i.referralRepo.GetUserByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return custom_errors.NewBadRequestError(referral.ErrInvalidCode)
+		}
+		return err
+	}
+
+	if referrerID == referredID {
+		return custom_errors.NewBadRequestError(referral.ErrSelfReferral)
+	}
+
+	return i.referralRepo.CreateReferral(ctx, referral.Referral{
+		ReferrerID: referrerID,
+		ReferredID: referredID,
+	})
+}
+
+// RewardOrder начисляет награду пригласившему за оплаченный заказ приглашенного.
+// За каждый заказ награда начисляется не более одного раза, бесплатные заказы не награждаются
+func (i *Implementation) RewardOrder(ctx context.Context, o order.Order) error {
+	if i.policy.Kind == referral.NoReward || o.IsFree() {
+		return nil
+	}
+
+	r, err := i.referralRepo.GetReferral(ctx, o.UserID)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	reward, err := i.referralRepo.CreateReward(ctx, i.policy.NewReward(r, o.ID))
+	if err != nil {
+		if errors.Is(err, referral.ErrRewardExists) {
+			return nil
+		}
+		return err
+	}
+
+	user, err := i.userUC.GetUser(ctx, reward.ReferrerID)
+	if err != nil {
+		return err
+	}
+
+	lang := user.Lang()
+	var text string
+	switch reward.Kind {
+	case referral.DaysReward:
+		applied, err := i.applyDays(ctx, reward, lang)
+		if err != nil {
+			return err
+		}
+
+		// о продлении подписки пользователь узнает из уведомления об изменении подписки
+		if applied {
+			return nil
+		}
+		text = lang.T(locale.ReferralDaysPending, reward.Days, lang.Plural(reward.Days, locale.DayUnit))
+	case referral.DiscountReward:
+		text = lang.T(locale.ReferralDiscountEarned, reward.DiscountPercent)
+	default:
+		return nil
+	}
+
+	return i.userUC.Notify(ctx, user, communication.Message{
+		Title:       lang.T(locale.ReferralRewardTitle),
+		Description: text,
+	})
+}
+
+// applyDays продлевает действующую подписку пригласившего на бонусные дни.
+// Без действующей подписки награда остается ожидающей, applied=false
+func (i *Implementation) applyDays(ctx context.Context,
+	reward referral.Reward,
+	lang locale.Lang,
+) (applied bool, err error) {
+	sub, err := i.subscriptionUC.AddBonusDays(ctx, reward.ReferrerID, reward.Days, lang.T(locale.ReferralBonusReason))
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err = i.referralRepo.MarkDaysApplied(ctx, reward.ID, sub.ID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ClaimDiscount занимает доступную скидку пользователя для нового заказа.
+// Если скидок нет, возвращается пустая награда
+func (i *Implementation) ClaimDiscount(ctx context.Context, userID domainUser.ID) (referral.Reward, error) {
+	reward, err := i.referralRepo.ClaimDiscount(ctx, userID)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return referral.Reward{}, nil
+		}
+		return referral.Reward{}, err
+	}
+
+	return reward, nil
+}
+
+// BindDiscount запоминает заказ, к которому применена занятая скидка
+func (i *Implementation) BindDiscount(ctx context.Context, id referral.RewardID, orderID order.ID) error {
+	return i.referralRepo.BindDiscount(ctx, id, orderID)
+}
+
+// ReleaseDiscount возвращает занятую скидку, если заказ не был создан
+func (i *Implementation) ReleaseDiscount(ctx context.Context, id referral.RewardID) error {
+	return i.referralRepo.ReleaseDiscount(ctx, id)
+}
+
+// ReleaseOrderDiscount возвращает скидку отмененного заказа для следующего заказа
+func (i *Implementation) ReleaseOrderDiscount(ctx context.Context, orderID order.ID) error {
+	return i.referralRepo.ReleaseOrderDiscount(ctx, orderID)
+}
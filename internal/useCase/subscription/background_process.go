@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
 	"github.com/kdv2001/onlySubscription/pkg/logger"
@@ -16,60 +18,239 @@ import (
 // RunBackgroundProcess запускает фоновые процессы
 func (i *Implementation) RunBackgroundProcess(ctx context.Context, wg *sync.WaitGroup) error {
 	go parallel.BackgroundPeriodProcess(ctx, wg, 20*time.Second, i.deactivateExpiredSubscription)
+	go parallel.BackgroundPeriodProcess(ctx, wg, time.Minute, i.remindExpiringSubscriptions)
+	go parallel.BackgroundPeriodProcess(ctx, wg, 10*time.Minute, i.revokeChatAccess)
 	return nil
 }
 
 // maxProcessingItems кол-во элементов в выборке для обработки
 const maxProcessingItems = 30
 
-// deactivateExpiredSubscription деактивирует просроченные подписки
+// remindExpiringSubscriptions напоминает о скором окончании подписки и льготного периода.
+// Отступы обрабатываются по возрастанию, поэтому подписка, до окончания которой
+// осталось меньше нескольких отступов, получает только одно, ближайшее напоминание
+func (i *Implementation) remindExpiringSubscriptions(ctx context.Context) error {
+	for _, offset := range i.reminderOffsets {
+		if err := i.remind(ctx, subscription.ActiveState, offset); err != nil {
+			return err
+		}
+
+		// о начале льготного периода пользователь уже уведомлен, поэтому
+		// напоминания с отступом не меньше льготного периода пропускаются
+		if offset < i.gracePeriod {
+			if err := i.remind(ctx, subscription.GraceState, offset); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// remind отправляет напоминания о продлении подпискам в состоянии state
+func (i *Implementation) remind(ctx context.Context, state subscription.State, offset time.Duration) error {
+	subscriptions, err := i.subscriptionRepo.GetSubscriptionsForReminder(ctx, state, offset, maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range subscriptions {
+		user, err := i.userUC.GetUser(ctx, s.UserID)
+		if err != nil {
+			logger.Errorf(ctx, "error send subscription reminder: %v", err)
+			continue
+		}
+
+		lang := user.Lang()
+		msg := communication.Message{
+			Title:       lang.T(locale.ReminderTitle),
+			Description: lang.T(locale.ActiveUntil, s.Deadline.Format(consts.DateTimeLayout)),
+			Buttons:     []communication.Button{renewButton(lang, s)},
+		}
+		if state == subscription.GraceState {
+			msg.Title = lang.T(locale.GraceReminderTitle)
+			msg.Description = lang.T(locale.GraceReminderText,
+				s.Deadline.Format(consts.DateTimeLayout),
+				s.GraceUntil.Format(consts.DateTimeLayout))
+		}
+
+		if err = i.userUC.Notify(ctx, user, msg); err != nil {
+			logger.Errorf(ctx, "error send subscription reminder: %v", err)
+			continue
+		}
+
+		if err = i.subscriptionRepo.MarkReminderSent(ctx, s.ID, state, offset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renewButton кнопка продления подписки тем же тарифом
+func renewButton(lang locale.Lang, s subscription.Subscription) communication.Button {
+	return communication.Button{
+		Text:    lang.T(locale.RenewButton),
+		Action:  communication.RenewAction,
+		Payload: s.TariffID.String(),
+	}
+}
+
+// deactivateExpiredSubscription переводит истекшие подписки в льготный период,
+// а если он не настроен или закончился - деактивирует их
 func (i *Implementation) deactivateExpiredSubscription(ctx context.Context) error {
-	subscriptions, errG := i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
+	now := time.Now().UTC()
+	expired, err := i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
 		Pagination: &primitives.Pagination{
 			Num: maxProcessingItems,
 		},
 		Filters: &subscription.Filters{
 			Deadline: &primitives.IntervalFilter[time.Time]{
-				To: time.Now().UTC(),
+				To: now,
 			},
 			Statuses: []subscription.State{subscription.ActiveState},
 		},
 	})
-	if errG != nil {
-		return errG
+	if err != nil {
+		return err
 	}
 
-	for _, s := range subscriptions {
-		c, err := subscription.NewChangeItemStatus(s.State, subscription.InactiveState)
+	for _, s := range expired {
+		if i.gracePeriod > 0 {
+			err = i.startGrace(ctx, s)
+		} else {
+			err = i.expire(ctx, s)
+		}
 		if err != nil {
-			if errors.Is(err, subscription.ErrStatusIsEqual) {
-				return nil
-			}
-
 			return err
 		}
+	}
 
-		user, err := i.userUC.GetUser(ctx, s.UserID)
-		if err != nil {
-			logger.Errorf(ctx, "error send subscription msg: %v", err)
-			continue
+	graceEnded, err := i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
+		Pagination: &primitives.Pagination{
+			Num: maxProcessingItems,
+		},
+		Filters: &subscription.Filters{
+			GraceUntil: &primitives.IntervalFilter[time.Time]{
+				To: now,
+			},
+			Statuses: []subscription.State{subscription.GraceState},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range graceEnded {
+		if err = i.expire(ctx, s); err != nil {
+			return err
 		}
+	}
 
-		msg := communication.Message{
-			ChatID:      user.Contact.TelegramBotChatID,
-			Title:       "Ваша подписка истекла",
-			Description: s.Description,
+	deactivated := len(graceEnded)
+	if i.gracePeriod == 0 {
+		deactivated += len(expired)
+	}
+
+	if deactivated == 0 {
+		return nil
+	}
+
+	// участники, чьи подписки только что истекли, исключаются из чатов сразу
+	return i.revokeChatAccess(ctx)
+}
+
+// startGrace переводит истекшую подписку в льготный период и срочно предлагает ее продлить.
+// Льготный период отсчитывается от окончания подписки, а не от момента обработки
+func (i *Implementation) startGrace(ctx context.Context, s subscription.Subscription) error {
+	s.GraceUntil = s.Deadline.Add(i.gracePeriod)
+
+	// событие публикуется до смены статуса, повторная публикация при сбое отбрасывается по ID события
+	if err := i.eventPublisher.Publish(ctx, graceStartedEvent(s)); err != nil {
+		return err
+	}
+
+	err := i.subscriptionRepo.StartGrace(ctx, s.ID, s.GraceUntil)
+	if err != nil {
+		if errors.Is(err, subscription.ErrSubscriptionChanged) {
+			// подписку продлили после выборки
+			return nil
 		}
 
-		if err = i.communicationClient.SendMessage(ctx, msg); err != nil {
-			logger.Errorf(ctx, "error send subscription msg: %v", err)
-			continue
+		return err
+	}
+
+	user, err := i.userUC.GetUser(ctx, s.UserID)
+	if err != nil {
+		logger.Errorf(ctx, "error send subscription grace msg: %v", err)
+		return nil
+	}
+
+	lang := user.Lang()
+	msg := communication.Message{
+		Title:       lang.T(locale.GraceTitle),
+		Description: lang.T(locale.GraceText, description(lang, s), s.GraceUntil.Format(consts.DateTimeLayout)),
+		Buttons:     []communication.Button{renewButton(lang, s)},
+	}
+
+	// статус уже изменен, поэтому ошибка отправки только логируется
+	if err = i.userUC.Notify(ctx, user, msg); err != nil {
+		logger.Errorf(ctx, "error send subscription grace msg: %v", err)
+	}
+
+	return nil
+}
+
+// expire деактивирует истекшую подписку и сообщает об этом пользователю
+func (i *Implementation) expire(ctx context.Context, s subscription.Subscription) error {
+	c, err := subscription.NewChangeItemStatus(s.State, subscription.InactiveState)
+	if err != nil {
+		if errors.Is(err, subscription.ErrStatusIsEqual) {
+			return nil
 		}
 
-		if err = i.subscriptionRepo.ChangeStatus(ctx, s.ID, c); err != nil {
-			return err
+		return err
+	}
+
+	err = i.subscriptionRepo.ChangeStatus(ctx, s.ID, c)
+	if err != nil {
+		if errors.Is(err, subscription.ErrSubscriptionChanged) {
+			// подписку продлили после выборки, она не истекла
+			return nil
 		}
+
+		return err
+	}
+
+	// событие публикуется только после смены статуса, чтобы не сообщить об окончании продленной подписки
+	if err = i.eventPublisher.Publish(ctx, subscriptionEvent(subscription.ExpiredEvent, s)); err != nil {
+		return err
+	}
+
+	user, err := i.userUC.GetUser(ctx, s.UserID)
+	if err != nil {
+		logger.Errorf(ctx, "error send subscription msg: %v", err)
+		return nil
+	}
+
+	msg := communication.Message{
+		Title:       user.Lang().T(locale.ExpiredTitle),
+		Description: description(user.Lang(), s),
+	}
+
+	// статус уже изменен, поэтому ошибка отправки только логируется
+	if err = i.userUC.Notify(ctx, user, msg); err != nil {
+		logger.Errorf(ctx, "error send subscription msg: %v", err)
 	}
 
 	return nil
 }
+
+// description описание подписки для уведомления, без описания - призыв продлить подписку на языке lang
+func description(lang locale.Lang, s subscription.Subscription) string {
+	if s.Description == "" {
+		return lang.T(locale.RenewProduct)
+	}
+
+	return s.Description
+}
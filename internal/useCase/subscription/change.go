@@ -0,0 +1,131 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// ChangeSubscription изменяет подписку по запросу администратора или системы: отзывает, приостанавливает,
+// возобновляет или продлевает ее. Изменение сохраняется в журнал, пользователь получает уведомление
+func (i *Implementation) ChangeSubscription(ctx context.Context,
+	req subscription.ChangeRequest,
+) (subscription.Subscription, error) {
+	s, err := i.subscriptionRepo.GetSubscription(ctx, req.SubscriptionID)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	c, err := subscription.NewChange(s, req, time.Now().UTC())
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	c, err = i.subscriptionRepo.ApplyChange(ctx, c)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	s.State = c.State.To
+	s.Deadline = c.NewDeadline
+	s.PausedAt = c.PausedAt
+
+	if err = i.eventPublisher.Publish(ctx, changedEvent(s, c)); err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	// изменение уже применено, поэтому ошибки уведомления и доступа в чат только логируются,
+	// доступ без действующей подписки в любом случае отзовет фоновый процесс
+	if err = i.notifyChange(ctx, s, c); err != nil {
+		logger.Errorf(ctx, "error send subscription change msg: %v", err)
+	}
+
+	if s.AccessChatID != 0 {
+		if s.State == subscription.ActiveState {
+			err = i.grantChatAccess(ctx, s)
+		} else {
+			err = i.revokeChatAccess(ctx)
+		}
+		if err != nil {
+			logger.Errorf(ctx, "error update chat access: %v", err)
+		}
+	}
+
+	return s, nil
+}
+
+// AddBonusDays продлевает на days дней действующую подписку пользователя с самым поздним окончанием.
+// Если действующей подписки нет, возвращается ошибка NotFound
+func (i *Implementation) AddBonusDays(ctx context.Context,
+	userID domainUser.ID,
+	days int,
+	reason string,
+) (subscription.Subscription, error) {
+	subs, err := i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
+		Pagination: &primitives.Pagination{
+			Num: 1,
+		},
+		Filters: &subscription.Filters{
+			UserID:   userID,
+			Statuses: []subscription.State{subscription.ActiveState, subscription.GraceState},
+		},
+	})
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	if len(subs) == 0 {
+		return subscription.Subscription{}, custom_errors.NewNotFoundError(errors.New("no active subscription"))
+	}
+
+	return i.ChangeSubscription(ctx, subscription.ChangeRequest{
+		SubscriptionID: subs[0].ID,
+		Action:         subscription.ExtendAction,
+		Days:           days,
+		Reason:         reason,
+	})
+}
+
+// GetChanges возвращает последние изменения подписки администраторами и системой
+func (i *Implementation) GetChanges(ctx context.Context, id subscription.ID) ([]subscription.Change, error) {
+	return i.subscriptionRepo.GetChanges(ctx, id, maxProcessingItems)
+}
+
+// notifyChange уведомляет пользователя об изменении подписки администратором
+func (i *Implementation) notifyChange(ctx context.Context, s subscription.Subscription, c subscription.Change) error {
+	user, err := i.userUC.GetUser(ctx, s.UserID)
+	if err != nil {
+		return err
+	}
+
+	lang := user.Lang()
+	var title, status string
+	switch c.Action {
+	case subscription.RevokeAction:
+		title = lang.T(locale.RevokedTitle)
+		status = lang.T(locale.RevokedStatus)
+	case subscription.PauseAction:
+		title = lang.T(locale.PausedTitle)
+		status = lang.T(locale.PausedStatus)
+	case subscription.ResumeAction:
+		title = lang.T(locale.ResumedTitle)
+		status = lang.T(locale.ActiveUntil, s.Deadline.Format(consts.DateTimeLayout))
+	case subscription.ExtendAction:
+		title = lang.T(locale.ExtendedTitle)
+		status = lang.T(locale.ActiveUntil, s.Deadline.Format(consts.DateTimeLayout))
+	}
+
+	return i.userUC.Notify(ctx, user, communication.Message{
+		Title:       title,
+		Description: lang.T(locale.ChangeText, description(lang, s), status, c.Reason),
+	})
+}
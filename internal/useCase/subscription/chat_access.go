@@ -0,0 +1,91 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/consts"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+)
+
+// grantChatAccess выдает подписчику одноразовую ссылку-приглашение в чат подписки.
+// Ссылка выдается один раз на подписку, повторный вызов ничего не делает.
+// Если доступ был отозван, например при приостановке, выдается новая ссылка
+func (i *Implementation) grantChatAccess(ctx context.Context, s subscription.Subscription) error {
+	a, err := i.subscriptionRepo.GetChatAccess(ctx, s.ID)
+	if err == nil && !a.IsRevoked() {
+		return nil
+	}
+	if err != nil && !errors.Is(err, custom_errors.ErrorNotFound) {
+		return err
+	}
+
+	user, err := i.userUC.GetUser(ctx, s.UserID)
+	if err != nil {
+		return err
+	}
+
+	link, err := i.chatClient.CreateInviteLink(ctx, s.AccessChatID, s.Deadline)
+	if err != nil {
+		return err
+	}
+
+	err = i.subscriptionRepo.SaveChatAccess(ctx, subscription.ChatAccess{
+		SubscriptionID: s.ID,
+		ChatID:         s.AccessChatID,
+		UserID:         s.UserID,
+		// ID личного чата с ботом совпадает с ID пользователя в телеграм
+		TelegramUserID: user.Contact.TelegramBotChatID,
+		InviteLink:     link,
+	})
+	if err != nil {
+		return err
+	}
+
+	// ссылка сохранена и доступна в карточке подписки, поэтому ошибка отправки не прерывает выдачу заказа
+	lang := user.Lang()
+	err = i.userUC.Notify(ctx, user, communication.Message{
+		Title:       lang.T(locale.ChatAccessTitle),
+		Description: lang.T(locale.ChatAccessText, link, s.Deadline.Format(consts.DateTimeLayout)),
+	})
+	if err != nil {
+		logger.Errorf(ctx, "error send chat invite link: %v", err)
+	}
+
+	return nil
+}
+
+// GetChatAccess возвращает доступ в чат, выданный по подписке
+func (i *Implementation) GetChatAccess(ctx context.Context, id subscription.ID) (subscription.ChatAccess, error) {
+	return i.subscriptionRepo.GetChatAccess(ctx, id)
+}
+
+// revokeChatAccess исключает из чатов участников без действующей подписки
+func (i *Implementation) revokeChatAccess(ctx context.Context) error {
+	accesses, err := i.subscriptionRepo.GetChatAccessToRevoke(ctx, maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range accesses {
+		if err = i.chatClient.RemoveChatMember(ctx, a.ChatID, a.TelegramUserID); err != nil {
+			logger.Errorf(ctx, "error remove chat member: %v", err)
+			continue
+		}
+
+		// неиспользованная ссылка не должна пережить подписку
+		if err = i.chatClient.RevokeInviteLink(ctx, a.ChatID, a.InviteLink); err != nil {
+			logger.Errorf(ctx, "error revoke chat invite link: %v", err)
+		}
+
+		if err = i.subscriptionRepo.MarkChatAccessRevoked(ctx, a.SubscriptionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package subscription
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
+)
+
+// subscriptionEvent собирает событие жизненного цикла подписки.
+// ID события детерминирован, поэтому повторная обработка не порождает дублей.
+// После продления подписка может повторно истечь или попасть в льготный период, поэтому ID включает окончание подписки
+func subscriptionEvent(eventType string, s subscription.Subscription) communication.Event {
+	return communication.Event{
+		ID:         fmt.Sprintf("%s:%s:%d", eventType, s.ID, s.Deadline.Unix()),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data: map[string]string{
+			"subscription_id": s.ID.String(),
+			"user_id":         s.UserID.String(),
+			"order_id":        s.OrderID.String(),
+			"product_id":      s.ProductID.String(),
+			"tariff_id":       s.TariffID.String(),
+			"deadline":        s.Deadline.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// renewedEvent собирает событие продления подписки
+func renewedEvent(s subscription.Subscription, r subscription.Renewal) communication.Event {
+	e := subscriptionEvent(subscription.RenewedEvent, s)
+	e.ID = fmt.Sprintf("%s:%s", subscription.RenewedEvent, r.ID)
+	e.Data["renewal_id"] = r.ID.String()
+	e.Data["renewal_order_id"] = r.OrderID.String()
+	e.Data["renewal_tariff_id"] = r.TariffID.String()
+	e.Data["previous_deadline"] = r.PreviousDeadline.UTC().Format(time.RFC3339)
+
+	return e
+}
+
+// changedEvent собирает событие изменения подписки администратором
+func changedEvent(s subscription.Subscription, c subscription.Change) communication.Event {
+	eventType := c.Action.Event()
+	e := subscriptionEvent(eventType, s)
+	e.ID = fmt.Sprintf("%s:%s", eventType, c.ID)
+	e.Data["change_id"] = c.ID.String()
+	e.Data["admin_id"] = c.AdminID.String()
+	e.Data["reason"] = c.Reason
+	e.Data["previous_deadline"] = c.PreviousDeadline.UTC().Format(time.RFC3339)
+
+	return e
+}
+
+// graceStartedEvent собирает событие начала льготного периода
+func graceStartedEvent(s subscription.Subscription) communication.Event {
+	e := subscriptionEvent(subscription.GraceStartedEvent, s)
+	e.Data["grace_until"] = s.GraceUntil.UTC().Format(time.RFC3339)
+
+	return e
+}
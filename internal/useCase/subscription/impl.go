@@ -2,11 +2,17 @@ package subscription
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	domainOrder "github.com/kdv2001/onlySubscription/internal/domain/order"
+	"github.com/kdv2001/onlySubscription/internal/domain/primitives"
 	domainProducts "github.com/kdv2001/onlySubscription/internal/domain/products"
 	"github.com/kdv2001/onlySubscription/internal/domain/subscription"
 	domainUser "github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 type subscriptionRepo interface {
@@ -16,36 +22,224 @@ type subscriptionRepo interface {
 		subID subscription.ID,
 		changeState subscription.ChangeState,
 	) error
+	GetSubscriptionsForReminder(ctx context.Context,
+		state subscription.State,
+		offset time.Duration,
+		num uint64,
+	) ([]subscription.Subscription, error)
+	MarkReminderSent(ctx context.Context, id subscription.ID, state subscription.State, offset time.Duration) error
+	StartGrace(ctx context.Context, id subscription.ID, until time.Time) error
+	GetSubscription(ctx context.Context, id subscription.ID) (subscription.Subscription, error)
+	ExtendSubscription(ctx context.Context, r subscription.Renewal) (subscription.Renewal, error)
+	GetRenewals(ctx context.Context, id subscription.ID) ([]subscription.Renewal, error)
+	GetRenewalByOrder(ctx context.Context, orderID domainOrder.ID) (subscription.Renewal, error)
+	SaveChatAccess(ctx context.Context, a subscription.ChatAccess) error
+	GetChatAccess(ctx context.Context, id subscription.ID) (subscription.ChatAccess, error)
+	GetChatAccessToRevoke(ctx context.Context, num uint64) ([]subscription.ChatAccess, error)
+	MarkChatAccessRevoked(ctx context.Context, id subscription.ID) error
+	ApplyChange(ctx context.Context, c subscription.Change) (subscription.Change, error)
+	GetChanges(ctx context.Context, id subscription.ID, num uint64) ([]subscription.Change, error)
 }
 
-type communicationClient interface {
-	SendMessage(ctx context.Context, message communication.Message) error
+type chatClient interface {
+	CreateInviteLink(ctx context.Context, chatID int64, expireAt time.Time) (string, error)
+	RevokeInviteLink(ctx context.Context, chatID int64, link string) error
+	RemoveChatMember(ctx context.Context, chatID int64, userID int64) error
+}
+
+type eventPublisher interface {
+	Publish(ctx context.Context, e communication.Event) error
 }
 
 type userUC interface {
 	GetUser(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	Notify(ctx context.Context, u domainUser.User, msg communication.Message) error
 }
 
 type Implementation struct {
-	communicationClient communicationClient
-	subscriptionRepo    subscriptionRepo
-	userUC              userUC
+	chatClient       chatClient
+	eventPublisher   eventPublisher
+	subscriptionRepo subscriptionRepo
+	userUC           userUC
+	// reminderOffsets за сколько до окончания подписки напоминать о продлении, по возрастанию
+	reminderOffsets []time.Duration
+	// gracePeriod льготный период после окончания подписки, 0 - подписка истекает сразу
+	gracePeriod time.Duration
 }
 
-func NewImplementation(communicationClient communicationClient,
+func NewImplementation(chatClient chatClient,
+	eventPublisher eventPublisher,
 	subscriptionRepo subscriptionRepo,
 	userUC userUC,
+	reminderOffsets []time.Duration,
+	gracePeriod time.Duration,
 ) *Implementation {
+	offsets := slices.Clone(reminderOffsets)
+	slices.Sort(offsets)
+
 	return &Implementation{
-		communicationClient: communicationClient,
-		subscriptionRepo:    subscriptionRepo,
-		userUC:              userUC,
+		chatClient:       chatClient,
+		eventPublisher:   eventPublisher,
+		subscriptionRepo: subscriptionRepo,
+		userUC:           userUC,
+		reminderOffsets:  offsets,
+		gracePeriod:      gracePeriod,
+	}
+}
+
+// Subscribe оформляет подписку по оплаченному заказу.
+// Если у пользователя уже есть активная, приостановленная подписка на продукт или подписка в льготном периоде,
+// она продлевается от текущего окончания, иначе создается новая подписка
+func (i *Implementation) Subscribe(ctx context.Context, p subscription.Purchase) (subscription.Subscription, error) {
+	renewal, err := i.subscriptionRepo.GetRenewalByOrder(ctx, p.OrderID)
+	if err == nil {
+		// заказ уже учтен как продление
+		return i.subscriptionRepo.GetSubscription(ctx, renewal.SubscriptionID)
+	}
+	if !errors.Is(err, custom_errors.ErrorNotFound) {
+		return subscription.Subscription{}, err
 	}
+
+	// подписка могла измениться между выборкой и продлением, тогда выборка повторяется
+	for range maxRenewAttempts {
+		s, renewed, err := i.renew(ctx, p)
+		if errors.Is(err, subscription.ErrSubscriptionChanged) {
+			continue
+		}
+		if err != nil || renewed {
+			return s, err
+		}
+
+		return i.create(ctx, p)
+	}
+
+	return subscription.Subscription{}, custom_errors.NewInternalError(subscription.ErrSubscriptionChanged)
+}
+
+// maxRenewAttempts кол-во попыток продлить подписку, изменяемую параллельно
+const maxRenewAttempts = 3
+
+// renew продлевает подписку пользователя на продукт, если она есть. renewed=false - продлевать нечего
+func (i *Implementation) renew(ctx context.Context,
+	p subscription.Purchase,
+) (s subscription.Subscription, renewed bool, err error) {
+	active, err := i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
+		Pagination: &primitives.Pagination{
+			Num: 1,
+		},
+		Filters: &subscription.Filters{
+			UserID:    p.UserID,
+			ProductID: p.ProductID,
+			Statuses:  subscription.RenewableStates,
+		},
+	})
+	if err != nil {
+		return subscription.Subscription{}, false, err
+	}
+
+	if len(active) == 0 || active[0].OrderID == p.OrderID {
+		return subscription.Subscription{}, false, nil
+	}
+
+	renewal, err := i.subscriptionRepo.ExtendSubscription(ctx, subscription.NewRenewal(active[0], p, time.Now().UTC()))
+	if err != nil {
+		return subscription.Subscription{}, false, err
+	}
+
+	s, err = i.subscriptionRepo.GetSubscription(ctx, renewal.SubscriptionID)
+	if err != nil {
+		return subscription.Subscription{}, false, err
+	}
+
+	if err = i.eventPublisher.Publish(ctx, renewedEvent(s, renewal)); err != nil {
+		return subscription.Subscription{}, false, err
+	}
+
+	return s, true, nil
+}
+
+// create создает новую подписку по оплаченному заказу
+func (i *Implementation) create(ctx context.Context, p subscription.Purchase) (subscription.Subscription, error) {
+	now := time.Now().UTC()
+	s := subscription.Subscription{
+		UserID:       p.UserID,
+		OrderID:      p.OrderID,
+		TariffID:     p.TariffID,
+		ProductID:    p.ProductID,
+		AccessChatID: p.AccessChatID,
+		State:        subscription.ActiveState,
+		Deadline:     p.Period.AddTo(now),
+		Description:  p.Description,
+	}
+	id, err := i.subscriptionRepo.CreateSubscription(ctx, s)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	s, err = i.subscriptionRepo.GetSubscription(ctx, subscription.NewID(id.String()))
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	// продление действующей подписки доступ не меняет, ссылка выдается только новой подписке
+	if s.AccessChatID != 0 {
+		if err = i.grantChatAccess(ctx, s); err != nil {
+			return subscription.Subscription{}, err
+		}
+	}
+
+	if err = i.eventPublisher.Publish(ctx, subscriptionEvent(subscription.CreatedEvent, s)); err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	return s, nil
+}
+
+// GetSubscription возвращает подписку
+func (i *Implementation) GetSubscription(ctx context.Context, id subscription.ID) (subscription.Subscription, error) {
+	return i.subscriptionRepo.GetSubscription(ctx, id)
+}
+
+// GetUserSubscriptions возвращает активные и истекшие подписки пользователя, начиная с самых поздних
+func (i *Implementation) GetUserSubscriptions(ctx context.Context,
+	userID domainUser.ID,
+	pagination primitives.Pagination,
+) ([]subscription.Subscription, error) {
+	if pagination.Num == 0 || pagination.Num > 15 {
+		return nil, custom_errors.NewBadRequestError(errors.New("invalid pagination"))
+	}
+
+	return i.subscriptionRepo.GetSubscriptions(ctx, subscription.RequestList{
+		Pagination: &pagination,
+		Filters: &subscription.Filters{
+			UserID: userID,
+		},
+	})
+}
+
+// GetUserSubscription возвращает подписку пользователя
+func (i *Implementation) GetUserSubscription(ctx context.Context,
+	id subscription.ID,
+	userID domainUser.ID,
+) (subscription.Subscription, error) {
+	s, err := i.subscriptionRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	if s.UserID != userID {
+		return subscription.Subscription{}, custom_errors.NewForbiddenError(errors.New("not user subscription"))
+	}
+
+	return s, nil
+}
+
+// GetRenewals возвращает историю продлений подписки
+func (i *Implementation) GetRenewals(ctx context.Context, id subscription.ID) ([]subscription.Renewal, error) {
+	return i.subscriptionRepo.GetRenewals(ctx, id)
 }
 
-// CreateSubscription создает подписку
-func (i *Implementation) CreateSubscription(ctx context.Context, s subscription.Subscription) error {
-	s.State = subscription.ActiveState
-	_, err := i.subscriptionRepo.CreateSubscription(ctx, s)
-	return err
+// GetRenewalByOrder возвращает продление, оформленное заказом
+func (i *Implementation) GetRenewalByOrder(ctx context.Context, orderID domainOrder.ID) (subscription.Renewal, error) {
+	return i.subscriptionRepo.GetRenewalByOrder(ctx, orderID)
 }
@@ -2,23 +2,48 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/locale"
 	domain "github.com/kdv2001/onlySubscription/internal/domain/user"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
 )
 
 type userRepo interface {
-	RegisterTelegram(ctx context.Context, telegramBotLogin domain.TelegramBotRegister) (domain.ID, error)
+	RegisterTelegram(ctx context.Context, telegramBotLogin domain.TelegramBotRegister) (domain.ID, bool, error)
 	GetUserByTelegramID(ctx context.Context, user domain.TelegramID) (domain.User, error)
 	GetUser(ctx context.Context, user domain.ID) (domain.User, error)
+	SetRole(ctx context.Context, id domain.ID, role domain.Role) error
+	GetStaff(ctx context.Context) ([]domain.User, error)
+	Ban(ctx context.Context, id domain.ID, ban domain.Ban) error
+	Unban(ctx context.Context, id domain.ID, adminID domain.ID) error
+	SetLanguage(ctx context.Context, id domain.ID, lang locale.Lang) error
+	SetTelegramLanguage(ctx context.Context, id domain.ID, code string) error
+	MarkUnreachable(ctx context.Context, id domain.ID) error
+	Anonymize(ctx context.Context, id domain.ID) error
+}
+
+type communicationClient interface {
+	SendMessage(ctx context.Context, message communication.Message) error
 }
 
 type Implementation struct {
-	userRepo userRepo
+	userRepo            userRepo
+	communicationClient communicationClient
+	// ownerTelegramID владелец из конфигурации, пустой - не задан
+	ownerTelegramID domain.TelegramID
 }
 
-func NewImplementation(authRepo userRepo) *Implementation {
+func NewImplementation(authRepo userRepo,
+	communicationClient communicationClient,
+	ownerTelegramID domain.TelegramID,
+) *Implementation {
 	return &Implementation{
-		userRepo: authRepo,
+		userRepo:            authRepo,
+		communicationClient: communicationClient,
+		ownerTelegramID:     ownerTelegramID,
 	}
 }
 
@@ -27,10 +52,27 @@ type Register struct {
 	Password string
 }
 
-// RegisterByTelegramID регистрирует пользователя по данным telegram
+// RegisterByTelegramID регистрирует пользователя по данным telegram. Повторная регистрация обновляет чат,
+// данные профиля и возобновляет доставку сообщений. created=true - пользователь зарегистрирован впервые
 func (a *Implementation) RegisterByTelegramID(ctx context.Context,
-	telegramBotLogin domain.TelegramBotRegister) (domain.ID, error) {
-	return a.userRepo.RegisterTelegram(ctx, telegramBotLogin)
+	telegramBotLogin domain.TelegramBotRegister) (u domain.User, created bool, err error) {
+	id, created, err := a.userRepo.RegisterTelegram(ctx, telegramBotLogin)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+
+	if a.isOwner(telegramBotLogin.TelegramID) {
+		if err = a.userRepo.SetRole(ctx, id, domain.OwnerRole); err != nil {
+			return domain.User{}, false, err
+		}
+	}
+
+	u, err = a.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+
+	return u, created, nil
 }
 
 // GetUserByTelegramID возвращает пользователя по telegramID
@@ -43,3 +85,151 @@ func (a *Implementation) GetUserByTelegramID(ctx context.Context,
 func (a *Implementation) GetUser(ctx context.Context, id domain.ID) (domain.User, error) {
 	return a.userRepo.GetUser(ctx, id)
 }
+
+// isOwner возвращает признак владельца из конфигурации
+func (a *Implementation) isOwner(tgID domain.TelegramID) bool {
+	return a.ownerTelegramID.String() != "" && a.ownerTelegramID == tgID
+}
+
+// BootstrapOwner назначает роль владельца пользователю из конфигурации.
+// Незарегистрированный владелец получит роль при регистрации
+func (a *Implementation) BootstrapOwner(ctx context.Context) error {
+	if a.ownerTelegramID.String() == "" {
+		return nil
+	}
+
+	u, err := a.userRepo.GetUserByTelegramID(ctx, a.ownerTelegramID)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if u.Role == domain.OwnerRole {
+		return nil
+	}
+
+	return a.userRepo.SetRole(ctx, u.ID, domain.OwnerRole)
+}
+
+// SetRole назначает роль пользователю с telegram ID tgID от имени пользователя actorID.
+// Свою роль и роль владельца из конфигурации изменить нельзя
+func (a *Implementation) SetRole(ctx context.Context,
+	actorID domain.ID,
+	tgID domain.TelegramID,
+	role domain.Role,
+) (domain.User, error) {
+	actor, err := a.userRepo.GetUser(ctx, actorID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if !actor.Role.Can(domain.RolesPermission) {
+		return domain.User{}, custom_errors.NewForbiddenError(errors.New("not enough rights to set role"))
+	}
+
+	target, err := a.userRepo.GetUserByTelegramID(ctx, tgID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if target.ID == actor.ID {
+		return domain.User{}, custom_errors.NewForbiddenError(errors.New("can not change own role"))
+	}
+
+	if a.isOwner(tgID) {
+		return domain.User{}, custom_errors.NewForbiddenError(errors.New("can not change configured owner role"))
+	}
+
+	if err = a.userRepo.SetRole(ctx, target.ID, role); err != nil {
+		return domain.User{}, err
+	}
+	target.Role = role
+
+	return target, nil
+}
+
+// GetStaff возвращает пользователей с ролями в панели администрирования
+func (a *Implementation) GetStaff(ctx context.Context) ([]domain.User, error) {
+	return a.userRepo.GetStaff(ctx)
+}
+
+// BanUser блокирует пользователя по запросу администратора.
+// Сотрудников с ролью заблокировать нельзя, сначала нужно снять роль
+func (a *Implementation) BanUser(ctx context.Context, req domain.BanRequest) (domain.User, error) {
+	ban, err := domain.NewBan(req, time.Now().UTC())
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	target, err := a.userRepo.GetUserByTelegramID(ctx, req.TelegramID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if target.Role != domain.CustomerRole || a.isOwner(req.TelegramID) {
+		return domain.User{}, custom_errors.NewForbiddenError(errors.New("can not ban staff user"))
+	}
+
+	if err = a.userRepo.Ban(ctx, target.ID, ban); err != nil {
+		return domain.User{}, err
+	}
+	target.State = domain.BlockedState
+	target.Ban = ban
+
+	return target, nil
+}
+
+// UnbanUser снимает блокировку пользователя
+func (a *Implementation) UnbanUser(ctx context.Context, adminID domain.ID, tgID domain.TelegramID) (domain.User, error) {
+	target, err := a.userRepo.GetUserByTelegramID(ctx, tgID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err = a.userRepo.Unban(ctx, target.ID, adminID); err != nil {
+		return domain.User{}, err
+	}
+	target.State = domain.VerifiedState
+	target.Ban = domain.Ban{}
+
+	return target, nil
+}
+
+// SetLanguage сохраняет выбранный пользователем язык интерфейса, пустой - как в Telegram
+func (a *Implementation) SetLanguage(ctx context.Context, id domain.ID, lang locale.Lang) error {
+	return a.userRepo.SetLanguage(ctx, id, lang)
+}
+
+// SyncTelegramLanguage сохраняет код языка клиента Telegram, если он изменился.
+// По сохраненному коду выбирается язык уведомлений, отправляемых вне ответа на действие
+func (a *Implementation) SyncTelegramLanguage(ctx context.Context, u domain.User, code string) error {
+	if code == "" || code == u.TelegramLanguage {
+		return nil
+	}
+
+	return a.userRepo.SetTelegramLanguage(ctx, u.ID, code)
+}
+
+// Notify отправляет сообщение пользователю в чат с ботом.
+// Пользователю, заблокировавшему бота, сообщения не отправляются: при ответе 403 он отмечается
+// недоступным, и доставка возобновляется только после повторной команды /start
+func (a *Implementation) Notify(ctx context.Context, u domain.User, msg communication.Message) error {
+	if u.IsUnreachable() {
+		return nil
+	}
+
+	msg.ChatID = u.Contact.TelegramBotChatID
+	err := a.communicationClient.SendMessage(ctx, msg)
+	if errors.Is(err, communication.ErrUnreachable) {
+		return a.userRepo.MarkUnreachable(ctx, u.ID)
+	}
+
+	return err
+}
+
+// Anonymize удаляет данные telegram пользователя, заказы и подписки остаются привязаны к обезличенному ID
+func (a *Implementation) Anonymize(ctx context.Context, id domain.ID) error {
+	return a.userRepo.Anonymize(ctx, id)
+}
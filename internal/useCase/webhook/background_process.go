@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/webhook"
+	"github.com/kdv2001/onlySubscription/pkg/logger"
+	"github.com/kdv2001/onlySubscription/pkg/parallel"
+)
+
+// RunBackgroundProcess запускает фоновые процессы
+func (i *Implementation) RunBackgroundProcess(ctx context.Context, wg *sync.WaitGroup) error {
+	go parallel.BackgroundPeriodProcess(ctx, wg, 10*time.Second, i.deliverEvents)
+	return nil
+}
+
+// maxProcessingItems кол-во элементов в выборке для обработки
+const maxProcessingItems = 30
+
+// errUnknownEndpoint получатель удален из настроек
+var errUnknownEndpoint = errors.New("endpoint is not configured")
+
+// deliverEvents отправляет события, время попытки которых наступило.
+// Неудачные попытки повторяются с экспоненциально растущей задержкой
+func (i *Implementation) deliverEvents(ctx context.Context) error {
+	deliveries, err := i.webhookRepo.GetDueDeliveries(ctx, maxProcessingItems)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		sender, ok := i.endpoints[d.Endpoint]
+		if !ok {
+			err = errUnknownEndpoint
+		} else {
+			err = sender.SendEvent(ctx, d.Event)
+		}
+
+		if err == nil {
+			logger.Infof(ctx, "webhook delivered: delivery %s, endpoint %s, event %s",
+				d.ID, d.Endpoint, d.Event.ID)
+			if err = i.webhookRepo.MarkDelivered(ctx, d.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		attempts := d.Attempts + 1
+		status := webhook.PendingStatus
+		if attempts >= webhook.MaxAttempts || errors.Is(err, errUnknownEndpoint) {
+			status = webhook.FailedStatus
+		}
+
+		logger.Errorf(ctx, "webhook delivery failed: delivery %s, endpoint %s, event %s, attempt %d: %v",
+			d.ID, d.Endpoint, d.Event.ID, attempts, err)
+
+		err = i.webhookRepo.MarkAttemptFailed(ctx, d.ID, status, err.Error(),
+			time.Now().UTC().Add(webhook.RetryDelay(attempts)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
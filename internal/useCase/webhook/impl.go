@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kdv2001/onlySubscription/internal/domain/communication"
+	"github.com/kdv2001/onlySubscription/internal/domain/webhook"
+	custom_errors "github.com/kdv2001/onlySubscription/pkg/errors"
+)
+
+type webhookRepo interface {
+	CreateDeliveries(ctx context.Context, e communication.Event, endpoints []string) error
+	GetDueDeliveries(ctx context.Context, num uint64) ([]webhook.Delivery, error)
+	MarkDelivered(ctx context.Context, id webhook.DeliveryID) error
+	MarkAttemptFailed(ctx context.Context,
+		id webhook.DeliveryID,
+		status webhook.Status,
+		lastError string,
+		nextAttemptAt time.Time,
+	) error
+	ReplayDelivery(ctx context.Context, id webhook.DeliveryID) error
+	GetDelivery(ctx context.Context, id webhook.DeliveryID) (webhook.Delivery, error)
+	GetDeliveries(ctx context.Context, r webhook.RequestList) (webhook.DeliveriesPage, error)
+}
+
+type eventSender interface {
+	SendEvent(ctx context.Context, e communication.Event) error
+}
+
+// Endpoint получатель событий
+type Endpoint struct {
+	// Name уникальное название получателя, сохраняется в журнале доставок
+	Name string
+	// Sender клиент отправки событий получателю
+	Sender eventSender
+}
+
+type Implementation struct {
+	webhookRepo webhookRepo
+	endpoints   map[string]eventSender
+	names       []string
+}
+
+func NewImplementation(webhookRepo webhookRepo, endpoints []Endpoint) *Implementation {
+	i := &Implementation{
+		webhookRepo: webhookRepo,
+		endpoints:   make(map[string]eventSender, len(endpoints)),
+		names:       make([]string, 0, len(endpoints)),
+	}
+
+	for _, e := range endpoints {
+		i.endpoints[e.Name] = e.Sender
+		i.names = append(i.names, e.Name)
+	}
+
+	return i
+}
+
+// Publish ставит событие в очередь доставки всем получателям.
+// ID события обязателен: повторная публикация события с тем же ID игнорируется
+func (i *Implementation) Publish(ctx context.Context, e communication.Event) error {
+	if len(i.names) == 0 {
+		return nil
+	}
+
+	if e.ID == "" {
+		return custom_errors.NewBadRequestError(errors.New("empty event id"))
+	}
+
+	return i.webhookRepo.CreateDeliveries(ctx, e, i.names)
+}
+
+// GetDeliveries возвращает журнал доставок
+func (i *Implementation) GetDeliveries(ctx context.Context, r webhook.RequestList) (webhook.DeliveriesPage, error) {
+	if r.Pagination == nil || r.Pagination.Num == 0 || r.Pagination.Num > 15 {
+		return webhook.DeliveriesPage{}, custom_errors.NewBadRequestError(errors.New("invalid pagination"))
+	}
+
+	return i.webhookRepo.GetDeliveries(ctx, r)
+}
+
+// GetDelivery возвращает доставку
+func (i *Implementation) GetDelivery(ctx context.Context, id webhook.DeliveryID) (webhook.Delivery, error) {
+	return i.webhookRepo.GetDelivery(ctx, id)
+}
+
+// ReplayDelivery повторно отправляет событие получателю
+func (i *Implementation) ReplayDelivery(ctx context.Context, id webhook.DeliveryID) error {
+	d, err := i.webhookRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := i.endpoints[d.Endpoint]; !ok {
+		return custom_errors.NewBadRequestError(errors.New("endpoint is not configured")).
+			AddDetails(d.Endpoint)
+	}
+
+	return i.webhookRepo.ReplayDelivery(ctx, id)
+}
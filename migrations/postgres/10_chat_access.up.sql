@@ -0,0 +1,20 @@
+-- приватный канал или группа, доступ в которые выдает подписка на продукт
+alter table products
+    add column if not exists access_chat_id bigint;
+
+alter table subscription
+    add column if not exists access_chat_id bigint;
+
+-- выданные по подпискам ссылки-приглашения
+create table if not exists chat_access
+(
+    subscription_id  uuid primary key,
+    chat_id          bigint                      NOT NULL,
+    user_id          uuid                        NOT NULL,
+    telegram_user_id bigint                      NOT NULL,
+    invite_link      text                        NOT NULL,
+    created_at       timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    revoked_at       timestamp WITHOUT TIME ZONE
+);
+
+create index if not exists chat_access_not_revoked_idx on chat_access (chat_id) where revoked_at is null;
@@ -0,0 +1,20 @@
+-- доставки событий на внешние вебхуки, одна строка на пару событие-получатель
+create table if not exists webhook_deliveries
+(
+    id              uuid primary key,
+    event_id        text                        NOT NULL,
+    endpoint        text                        NOT NULL,
+    event_type      text                        NOT NULL,
+    occurred_at     timestamp WITHOUT TIME ZONE NOT NULL,
+    data            jsonb                       NOT NULL DEFAULT '{}',
+    status          text                        NOT NULL,
+    attempts        integer                     NOT NULL DEFAULT 0,
+    next_attempt_at timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    last_error      text                        NOT NULL DEFAULT '',
+    created_at      timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at      timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    delivered_at    timestamp WITHOUT TIME ZONE,
+    unique (event_id, endpoint)
+);
+
+create index if not exists webhook_deliveries_due_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
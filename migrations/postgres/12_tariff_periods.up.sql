@@ -0,0 +1,15 @@
+-- календарный период подписки тарифа вместо длительности в формате time.Duration
+alter table tariffs
+    add column if not exists period_count integer,
+    add column if not exists period_unit  text;
+
+-- прежние длительности переводятся в дни с округлением вверх, например 720h0m0s - 30 дней
+update tariffs
+set period_count = ceil(coalesce(substring(subscription_period from '^(\d+)h')::numeric, 0) / 24)::integer,
+    period_unit  = 'day'
+where period_count is null;
+
+alter table tariffs
+    alter column period_count set not null,
+    alter column period_unit set not null,
+    alter column subscription_period drop not null;
@@ -0,0 +1,19 @@
+alter table subscription
+    add column if not exists paused_at timestamp WITHOUT TIME ZONE;
+
+-- журнал изменений подписок администраторами
+create table if not exists subscription_changes
+(
+    id                uuid primary key,
+    subscription_id   uuid                        NOT NULL,
+    action            text                        NOT NULL,
+    reason            text                        NOT NULL,
+    admin_id          uuid                        NOT NULL,
+    from_state        text                        NOT NULL,
+    to_state          text                        NOT NULL,
+    previous_deadline timestamp WITHOUT TIME ZONE NOT NULL,
+    new_deadline      timestamp WITHOUT TIME ZONE NOT NULL,
+    created_at        timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+create index if not exists subscription_changes_subscription_id_idx on subscription_changes (subscription_id);
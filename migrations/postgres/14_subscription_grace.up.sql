@@ -0,0 +1,12 @@
+alter table subscription
+    add column if not exists grace_until timestamp WITHOUT TIME ZONE;
+
+-- напоминания отправляются отдельно до окончания подписки и до окончания льготного периода
+alter table subscription_reminders
+    add column if not exists state text NOT NULL DEFAULT 'active';
+
+alter table subscription_reminders
+    drop constraint if exists subscription_reminders_pkey;
+
+alter table subscription_reminders
+    add primary key (subscription_id, state, offset_seconds);
@@ -0,0 +1,4 @@
+alter table users
+    add column if not exists role text NOT NULL DEFAULT '';
+
+create index if not exists users_role_idx on users (role) where role <> '';
@@ -0,0 +1,15 @@
+-- блокировки пользователей, снятая блокировка остается в истории
+create table if not exists user_bans
+(
+    id         uuid primary key,
+    user_id    uuid                        NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    reason     text                        NOT NULL,
+    until      timestamp WITHOUT TIME ZONE,
+    admin_id   uuid                        NOT NULL,
+    created_at timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    lifted_at  timestamp WITHOUT TIME ZONE,
+    lifted_by  uuid
+);
+
+create index if not exists user_bans_active_idx on user_bans (user_id) where lifted_at is null;
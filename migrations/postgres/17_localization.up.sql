@@ -0,0 +1,16 @@
+-- language выбранный пользователем язык, пустая строка - как в настройках Telegram
+alter table users
+    add column if not exists language          text NOT NULL DEFAULT '',
+    add column if not exists telegram_language text NOT NULL DEFAULT '';
+
+-- переводы названия и описания продукта, без перевода используется исходный текст
+create table if not exists product_translations
+(
+    product_id  uuid NOT NULL,
+    FOREIGN KEY (product_id) REFERENCES products (id),
+    lang        text NOT NULL,
+    name        text NOT NULL,
+    description text NOT NULL,
+    updated_at  timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    PRIMARY KEY (product_id, lang)
+);
@@ -0,0 +1,45 @@
+-- реферальные коды пользователей, создаются при первом открытии статистики приглашений
+create table if not exists referral_codes
+(
+    user_id    uuid primary key,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    code       text                        NOT NULL UNIQUE,
+    created_at timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+-- приглашения, пользователь может быть приглашен только один раз
+create table if not exists referrals
+(
+    referred_id uuid primary key,
+    FOREIGN KEY (referred_id) REFERENCES users (id),
+    referrer_id uuid                        NOT NULL,
+    FOREIGN KEY (referrer_id) REFERENCES users (id),
+    created_at  timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+create index if not exists referrals_referrer_idx on referrals (referrer_id);
+
+-- награды пригласившим, за каждый оплаченный заказ приглашенного начисляется не более одной награды
+create table if not exists referral_rewards
+(
+    id               uuid primary key,
+    referrer_id      uuid                        NOT NULL,
+    FOREIGN KEY (referrer_id) REFERENCES users (id),
+    referred_id      uuid                        NOT NULL,
+    order_id         uuid                        NOT NULL UNIQUE,
+    kind             text                        NOT NULL,
+    days             integer                     NOT NULL DEFAULT 0,
+    discount_percent integer                     NOT NULL DEFAULT 0,
+    state            text                        NOT NULL,
+    subscription_id  uuid,
+    used_order_id    uuid,
+    created_at       timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    applied_at       timestamp WITHOUT TIME ZONE
+);
+
+create index if not exists referral_rewards_referrer_idx on referral_rewards (referrer_id, state);
+create index if not exists referral_rewards_used_order_idx on referral_rewards (used_order_id);
+
+-- изменения подписки без администратора вносит система, например бонусные дни за приглашение
+alter table subscription_changes
+    alter column admin_id drop not null;
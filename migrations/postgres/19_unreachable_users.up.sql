@@ -0,0 +1,3 @@
+-- unreachable_at время, когда пользователь заблокировал бота, null - сообщения доставляются
+alter table users
+    add column if not exists unreachable_at timestamp WITHOUT TIME ZONE;
@@ -0,0 +1,5 @@
+-- данные профиля telegram, обновляются при каждой команде /start
+alter table auth_bot_telegram
+    add column if not exists username   text NOT NULL DEFAULT '',
+    add column if not exists first_name text NOT NULL DEFAULT '',
+    add column if not exists last_name  text NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+alter table inventory
+-- post_sale_attempts кол-во неудачных попыток выполнить обработчики реализации
+    add column if not exists post_sale_attempts        integer NOT NULL DEFAULT 0,
+-- post_sale_next_attempt_at время следующей попытки, null - при первой обработке
+    add column if not exists post_sale_next_attempt_at timestamp WITHOUT TIME ZONE;
@@ -0,0 +1,58 @@
+-- в 1_init таблица подписок по ошибке объявлена как products и не создается
+create table if not exists subscription
+(
+    id          uuid primary key,
+    user_id     uuid                        NOT NULL,
+    order_id    uuid                        NOT NULL unique,
+    description text                        NOT NULL,
+    created_at  timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at  timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    state       text                        NOT NULL,
+    deadline    timestamp WITHOUT TIME ZONE NOT NULL
+);
+
+create table if not exists tariffs
+(
+    id                  uuid primary key,
+    product_id          uuid references products (id) NOT NULL,
+    name                text                          NOT NULL,
+    price               decimal                       NOT NULL DEFAULT (0),
+    currency            varchar                       NOT NULL,
+    subscription_period text                          NOT NULL,
+    created_at          timestamp WITHOUT TIME ZONE   NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at          timestamp WITHOUT TIME ZONE   NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+-- record_status поле для состояния самой записи активна/удалена
+    record_status       text                          NOT NULL default ('')
+);
+
+create index if not exists tariffs_product_id_idx on tariffs (product_id);
+
+-- переносим цену и период существующих продуктов в тариф с тем же ID,
+-- чтобы по нему можно было восстановить тариф старых заказов
+insert into tariffs (id, product_id, name, price, currency, subscription_period)
+select id, id, name, price, 'XTR', subscription_period
+from products
+on conflict do nothing;
+
+alter table products
+    drop column if exists price,
+    drop column if exists subscription_period;
+
+alter table orders
+    add column if not exists tariff_id uuid;
+
+-- orders.product_id хранит ID единицы инвентаря
+update orders
+set tariff_id = inventory.product_id
+from inventory
+where inventory.id = orders.product_id
+  and orders.tariff_id is null;
+
+alter table subscription
+    add column if not exists tariff_id uuid;
+
+update subscription
+set tariff_id = orders.tariff_id
+from orders
+where orders.id = subscription.order_id
+  and subscription.tariff_id is null;
@@ -0,0 +1,19 @@
+alter table products
+    add column if not exists publish_from      timestamp WITHOUT TIME ZONE,
+    add column if not exists publish_until     timestamp WITHOUT TIME ZONE,
+-- publish_announced признак отправленного администраторам уведомления о начале публикации
+    add column if not exists publish_announced boolean NOT NULL DEFAULT (false);
+
+create table if not exists tariff_sales
+(
+    id         uuid primary key,
+    tariff_id  uuid references tariffs (id) NOT NULL,
+    price      decimal                      NOT NULL,
+    starts_at  timestamp WITHOUT TIME ZONE  NOT NULL,
+    ends_at    timestamp WITHOUT TIME ZONE  NOT NULL,
+    created_at timestamp WITHOUT TIME ZONE  NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+-- announced признак отправленного администраторам уведомления о начале распродажи
+    announced  boolean                      NOT NULL DEFAULT (false)
+);
+
+create index if not exists tariff_sales_tariff_id_idx on tariff_sales (tariff_id, starts_at);
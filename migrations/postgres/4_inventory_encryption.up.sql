@@ -0,0 +1,9 @@
+-- полезная нагрузка шифруется конвертным шифрованием:
+-- payload_data зашифрована ключом данных, payload_key - ключ данных,
+-- зашифрованный мастер-ключом payload_key_id.
+-- description остается только у записей, созданных до включения шифрования,
+-- и очищается командой ротации ключей
+alter table inventory
+    add column if not exists payload_key_id text,
+    add column if not exists payload_key    bytea,
+    add column if not exists payload_data   bytea;
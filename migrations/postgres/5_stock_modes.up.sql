@@ -0,0 +1,7 @@
+alter table products
+    add column if not exists stock_mode  text   NOT NULL default ('unique'),
+-- stock_limit максимальное кол-во продаж для режима capped
+    add column if not exists stock_limit bigint NOT NULL default (0);
+
+-- общая полезная нагрузка продукта хранится в inventory со статусом shared, не более одной на продукт
+create unique index if not exists inventory_shared_product_idx on inventory (product_id) where status = 'shared';
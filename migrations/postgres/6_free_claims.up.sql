@@ -0,0 +1,9 @@
+-- бесплатные и пробные продукты выдаются пользователю один раз
+create table if not exists free_claims
+(
+    user_id    uuid                        NOT NULL,
+    product_id uuid                        NOT NULL,
+    order_id   uuid                        NOT NULL,
+    created_at timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    primary key (user_id, product_id)
+);
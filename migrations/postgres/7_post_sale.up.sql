@@ -0,0 +1,7 @@
+alter table inventory
+-- delivered_at время подтвержденной выдачи покупателю
+    add column if not exists delivered_at      timestamp WITHOUT TIME ZONE,
+-- realized_at время перевода в статус realized
+    add column if not exists realized_at       timestamp WITHOUT TIME ZONE,
+-- payload_purged_at время удаления полезной нагрузки после реализации
+    add column if not exists payload_purged_at timestamp WITHOUT TIME ZONE;
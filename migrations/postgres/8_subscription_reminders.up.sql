@@ -0,0 +1,8 @@
+-- отправленные напоминания о скором окончании подписки, offset_seconds - за сколько секунд до окончания
+create table if not exists subscription_reminders
+(
+    subscription_id uuid                        NOT NULL,
+    offset_seconds  bigint                      NOT NULL,
+    sent_at         timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    primary key (subscription_id, offset_seconds)
+);
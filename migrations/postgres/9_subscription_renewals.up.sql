@@ -0,0 +1,24 @@
+alter table subscription
+    add column if not exists product_id uuid;
+
+update subscription
+set product_id = tariffs.product_id
+from tariffs
+where tariffs.id = subscription.tariff_id
+  and subscription.product_id is null;
+
+create index if not exists subscription_user_product_idx on subscription (user_id, product_id);
+
+-- история продлений подписки, заказ продления привязан через order_id
+create table if not exists subscription_renewals
+(
+    id                uuid primary key,
+    subscription_id   uuid                        NOT NULL,
+    order_id          uuid                        NOT NULL unique,
+    tariff_id         uuid                        NOT NULL,
+    previous_deadline timestamp WITHOUT TIME ZONE NOT NULL,
+    new_deadline      timestamp WITHOUT TIME ZONE NOT NULL,
+    created_at        timestamp WITHOUT TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+create index if not exists subscription_renewals_subscription_id_idx on subscription_renewals (subscription_id);
@@ -0,0 +1,359 @@
+// Package callback упаковывает данные inline-кнопок в лимит Telegram в 64 байта:
+// - данные начинаются с названия действия, по нему выбирается обработчик;
+// - аргументы типизированы: UUID упаковывается в 22 символа, числа - в base36;
+// - кнопка может иметь срок действия, после которого данные не принимаются;
+// - данные, не помещающиеся в лимит, хранятся в памяти процесса под коротким токеном.
+//
+// Формат: <действие>[:<срок действия>:<аргумент>...], кнопка без аргументов и срока - только действие.
+// Данные под токеном: <действие>:*<токен>.
+package callback
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxLength максимальная длина данных кнопки в Telegram
+	MaxLength = 64
+	// separator разделитель действия, срока действия и аргументов
+	separator = ":"
+	// encodedPrefix признак аргумента, упакованного в base64
+	encodedPrefix = "~"
+	// numberBase основание упаковки чисел
+	numberBase = 36
+	// tokenPrefix признак данных, сохраненных под токеном
+	tokenPrefix = "*"
+	// tokenBytes кол-во случайных байт токена
+	tokenBytes = 9
+	// storedTTL срок хранения данных под токеном у кнопки без срока действия
+	storedTTL = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidData ошибка разбора данных кнопки
+	ErrInvalidData = errors.New("invalid callback data")
+	// ErrExpired ошибка истекшего срока действия кнопки
+	ErrExpired = errors.New("callback data expired")
+	// ErrMissingArg ошибка отсутствующего аргумента
+	ErrMissingArg = errors.New("callback argument is missing")
+	// ErrTooLong ошибка данных, которые не помещаются в лимит даже под токеном
+	ErrTooLong = errors.New("callback data is too long")
+)
+
+// Builder собирает данные кнопки
+type Builder struct {
+	action    string
+	expiresAt time.Time
+	args      []string
+}
+
+// New начинает данные кнопки действия action
+func New(action string) *Builder {
+	return &Builder{
+		action: action,
+	}
+}
+
+// ID добавляет идентификатор: UUID упаковывается, остальные строки кодируются целиком
+func (b *Builder) ID(id string) *Builder {
+	if u, err := uuid.Parse(id); err == nil {
+		b.args = append(b.args, base64.RawURLEncoding.EncodeToString(u[:]))
+		return b
+	}
+
+	b.args = append(b.args, encode(id))
+	return b
+}
+
+// Int добавляет целое число
+func (b *Builder) Int(n int64) *Builder {
+	b.args = append(b.args, strconv.FormatInt(n, numberBase))
+	return b
+}
+
+// Str добавляет короткую строку, например код языка
+func (b *Builder) Str(s string) *Builder {
+	if isSafe(s) {
+		b.args = append(b.args, s)
+		return b
+	}
+
+	b.args = append(b.args, encode(s))
+	return b
+}
+
+// ExpiresIn ограничивает срок действия кнопки
+func (b *Builder) ExpiresIn(d time.Duration) *Builder {
+	b.expiresAt = time.Now().Add(d)
+	return b
+}
+
+// Build возвращает данные кнопки. Данные длиннее MaxLength сохраняются в памяти процесса под коротким токеном
+// до окончания срока действия кнопки, а у кнопки без срока - на storedTTL.
+// После перезапуска такие кнопки считаются истекшими
+func (b *Builder) Build() (string, error) {
+	data := b.pack()
+	if len(data) <= MaxLength {
+		return data, nil
+	}
+
+	expiresAt := b.expiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(storedTTL)
+	}
+
+	token, err := stored.put(data, expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	data = b.action + separator + tokenPrefix + token
+	if len(data) > MaxLength {
+		stored.delete(token)
+		return "", fmt.Errorf("%w: %s", ErrTooLong, b.action)
+	}
+
+	return data, nil
+}
+
+// String возвращает данные кнопки, см. Build. Действие, не помещающееся в лимит, - ошибка в коде, поэтому паника
+func (b *Builder) String() string {
+	data, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func (b *Builder) pack() string {
+	if len(b.args) == 0 && b.expiresAt.IsZero() {
+		return b.action
+	}
+
+	var expires string
+	if !b.expiresAt.IsZero() {
+		expires = strconv.FormatInt(b.expiresAt.Unix(), numberBase)
+	}
+
+	return strings.Join(append([]string{b.action, expires}, b.args...), separator)
+}
+
+// Data разобранные данные кнопки
+type Data struct {
+	// Action действие
+	Action string
+	args   []string
+}
+
+// ActionOf возвращает действие из данных кнопки без разбора аргументов
+func ActionOf(data string) string {
+	action, _, _ := strings.Cut(data, separator)
+	return action
+}
+
+// Parse разбирает данные кнопки и проверяет срок действия
+func Parse(data string) (Data, error) {
+	parts := strings.Split(data, separator)
+	if parts[0] == "" {
+		return Data{}, ErrInvalidData
+	}
+
+	if len(parts) == 1 {
+		return Data{Action: parts[0]}, nil
+	}
+
+	if len(parts) == 2 && strings.HasPrefix(parts[1], tokenPrefix) {
+		full, ok := stored.get(strings.TrimPrefix(parts[1], tokenPrefix))
+		if !ok || ActionOf(full) != parts[0] {
+			return Data{}, ErrExpired
+		}
+
+		return Parse(full)
+	}
+
+	if parts[1] != "" {
+		expiresAt, err := strconv.ParseInt(parts[1], numberBase, 64)
+		if err != nil {
+			return Data{}, fmt.Errorf("%w: %w", ErrInvalidData, err)
+		}
+
+		if time.Now().Unix() > expiresAt {
+			return Data{}, ErrExpired
+		}
+	}
+
+	return Data{
+		Action: parts[0],
+		args:   parts[2:],
+	}, nil
+}
+
+// Len возвращает кол-во аргументов
+func (d Data) Len() int {
+	return len(d.args)
+}
+
+// ID возвращает идентификатор из аргумента i
+func (d Data) ID(i int) (string, error) {
+	arg, err := d.arg(i)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(arg, encodedPrefix) {
+		return decode(arg)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(arg)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	u, err := uuid.FromBytes(b)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	return u.String(), nil
+}
+
+// Int возвращает целое число из аргумента i
+func (d Data) Int(i int) (int64, error) {
+	arg, err := d.arg(i)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(arg, numberBase, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	return n, nil
+}
+
+// Str возвращает строку из аргумента i
+func (d Data) Str(i int) (string, error) {
+	arg, err := d.arg(i)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(arg, encodedPrefix) {
+		return decode(arg)
+	}
+
+	return arg, nil
+}
+
+func (d Data) arg(i int) (string, error) {
+	if i < 0 || i >= len(d.args) {
+		return "", fmt.Errorf("%w: %d", ErrMissingArg, i)
+	}
+
+	return d.args[i], nil
+}
+
+// storage данные кнопок, сохраненные под токенами
+type storage struct {
+	mu      sync.Mutex
+	entries map[string]storedEntry
+}
+
+type storedEntry struct {
+	data      string
+	expiresAt time.Time
+}
+
+// stored хранилище данных кнопок процесса
+var stored = &storage{
+	entries: make(map[string]storedEntry),
+}
+
+// put сохраняет данные до expiresAt и возвращает токен, заодно удаляя истекшие записи
+func (s *storage) put(data string, expiresAt time.Time) (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[token] = storedEntry{
+		data:      data,
+		expiresAt: expiresAt,
+	}
+
+	return token, nil
+}
+
+// get возвращает данные по токену, ok=false - токен неизвестен или истек
+func (s *storage) get(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[token]
+	if !ok || time.Now().After(e.expiresAt) {
+		return "", false
+	}
+
+	return e.data, true
+}
+
+func (s *storage) delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, token)
+}
+
+func encode(s string) string {
+	return encodedPrefix + base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decode(arg string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(arg, encodedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	return string(b), nil
+}
+
+// isSafe возвращает признак строки, которую можно передать без кодирования
+func isSafe(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	if strings.HasPrefix(s, encodedPrefix) {
+		return false
+	}
+
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,151 @@
+package callback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Parse(t *testing.T) {
+	t.Parallel()
+	id := "0b7c2a2e-5d3f-4d1a-9a51-6f3f2d1c8e44"
+	data := New("get_product_for_edit").ID(id).Int(12).Str("keep").ExpiresIn(time.Hour).String()
+	if len(data) > MaxLength {
+		t.Fatalf("String() length = %d, want <= %d", len(data), MaxLength)
+	}
+
+	d, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if d.Action != "get_product_for_edit" || ActionOf(data) != d.Action {
+		t.Errorf("Parse() action = %s, ActionOf() = %s", d.Action, ActionOf(data))
+	}
+
+	if got, err := d.ID(0); err != nil || got != id {
+		t.Errorf("ID(0) = %s, %v, want %s", got, err, id)
+	}
+
+	if got, err := d.Int(1); err != nil || got != 12 {
+		t.Errorf("Int(1) = %d, %v, want 12", got, err)
+	}
+
+	if got, err := d.Str(2); err != nil || got != "keep" {
+		t.Errorf("Str(2) = %s, %v, want keep", got, err)
+	}
+
+	if _, err = d.Int(3); !errors.Is(err, ErrMissingArg) {
+		t.Errorf("Int(3) error = %v, want %v", err, ErrMissingArg)
+	}
+}
+
+func TestBuilder_PlainAction(t *testing.T) {
+	t.Parallel()
+	if got := New("menu").String(); got != "menu" {
+		t.Errorf("String() = %s, want menu", got)
+	}
+
+	d, err := Parse("menu")
+	if err != nil || d.Action != "menu" || d.Len() != 0 {
+		t.Errorf("Parse() = %+v, %v", d, err)
+	}
+}
+
+func TestBuilder_EncodedArgs(t *testing.T) {
+	t.Parallel()
+	id := "42"
+	text := "a:b?c"
+	d, err := Parse(New("action").ID(id).Str(text).String())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got, err := d.ID(0); err != nil || got != id {
+		t.Errorf("ID(0) = %s, %v, want %s", got, err, id)
+	}
+
+	if got, err := d.Str(1); err != nil || got != text {
+		t.Errorf("Str(1) = %s, %v, want %s", got, err, text)
+	}
+}
+
+func TestParse_Expired(t *testing.T) {
+	t.Parallel()
+	data := New("delete_item").ID("42").ExpiresIn(-time.Minute).String()
+	if _, err := Parse(data); !errors.Is(err, ErrExpired) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{"", ":1:2", "action:!:1"} {
+		if _, err := Parse(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("Parse(%q) error = %v, want %v", data, err, ErrInvalidData)
+		}
+	}
+
+	d, err := Parse("action::" + strings.Repeat("!", 4))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if _, err = d.ID(0); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("ID(0) error = %v, want %v", err, ErrInvalidData)
+	}
+}
+
+func TestBuilder_LongData(t *testing.T) {
+	t.Parallel()
+	id := strings.Repeat("long-id-", 10)
+	data, err := New("edit_text").ID(id).Str("keep").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(data) > MaxLength {
+		t.Fatalf("Build() length = %d, want <= %d", len(data), MaxLength)
+	}
+
+	if ActionOf(data) != "edit_text" {
+		t.Errorf("ActionOf() = %s, want edit_text", ActionOf(data))
+	}
+
+	d, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got, err := d.ID(0); err != nil || got != id {
+		t.Errorf("ID(0) = %s, %v, want %s", got, err, id)
+	}
+
+	if got, err := d.Str(1); err != nil || got != "keep" {
+		t.Errorf("Str(1) = %s, %v, want keep", got, err)
+	}
+}
+
+func TestBuilder_LongDataExpired(t *testing.T) {
+	t.Parallel()
+	data, err := New("edit_text").ID(strings.Repeat("x", MaxLength)).ExpiresIn(-time.Minute).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if _, err = Parse(data); !errors.Is(err, ErrExpired) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrExpired)
+	}
+
+	if _, err = Parse("edit_text:" + tokenPrefix + "unknown"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Parse(unknown token) error = %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestBuilder_TooLongAction(t *testing.T) {
+	t.Parallel()
+	if _, err := New(strings.Repeat("a", MaxLength)).ID("42").Build(); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Build() error = %v, want %v", err, ErrTooLong)
+	}
+}
@@ -0,0 +1,221 @@
+// Package dialog реализует пошаговое заполнение форм:
+// - форма описывается списком шагов, каждый шаг - один вопрос и проверка ответа;
+// - состояние заполнения хранится отдельно для каждого пользователя и истекает без ответов;
+// - пользователь может вернуться к предыдущему шагу или отменить заполнение.
+package dialog
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SkipValue ответ, пропускающий необязательный шаг
+const SkipValue = "-"
+
+var (
+	// ErrNoSession у пользователя нет незавершенной формы
+	ErrNoSession = errors.New("no active dialog")
+	// ErrFirstStep вернуться назад с первого шага нельзя
+	ErrFirstStep = errors.New("dialog is at the first step")
+	// ErrEmptyValue ответ не заполнен
+	ErrEmptyValue = errors.New("empty value")
+)
+
+// Step шаг формы
+type Step struct {
+	// Field ключ, под которым сохраняется ответ
+	Field string
+	// Title вопрос пользователю, отображающий код может хранить здесь ключ перевода
+	Title string
+	// Hint подсказка формата ответа, может быть пустой. Как и Title, может быть ключом перевода
+	Hint string
+	// Optional шаг можно пропустить ответом SkipValue, ответ сохраняется пустым
+	Optional bool
+	// Validate проверяет ответ, nil - подходит любой непустой ответ
+	Validate func(value string) error
+}
+
+// Form форма из последовательных шагов
+type Form struct {
+	// Name уникальное название формы
+	Name string
+	// Title заголовок формы для пользователя или ключ его перевода
+	Title string
+	// Steps шаги в порядке заполнения
+	Steps []Step
+}
+
+// Session состояние заполнения формы пользователем
+type Session struct {
+	// Form заполняемая форма
+	Form *Form
+	// Step номер текущего шага, равен кол-ву шагов после заполнения формы
+	Step int
+	// Values ответы по ключам шагов
+	Values map[string]string
+	// Params параметры, переданные при запуске формы, например ID продукта
+	Params map[string]string
+	// UpdatedAt время последнего действия
+	UpdatedAt time.Time
+}
+
+// Current возвращает текущий шаг
+func (s Session) Current() Step {
+	return s.Form.Steps[s.Step]
+}
+
+// Done возвращает признак заполнения всех шагов
+func (s Session) Done() bool {
+	return s.Step >= len(s.Form.Steps)
+}
+
+// Value возвращает ответ шага field
+func (s Session) Value(field string) string {
+	return s.Values[field]
+}
+
+// Param возвращает параметр запуска формы
+func (s Session) Param(key string) string {
+	return s.Params[key]
+}
+
+// Store хранилище незавершенных форм в памяти
+type Store struct {
+	mu       sync.Mutex
+	sessions map[int64]Session
+	// ttl время жизни формы без ответов
+	ttl time.Duration
+}
+
+// NewStore создает хранилище, форма без ответов дольше ttl считается отмененной
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		sessions: make(map[int64]Session),
+		ttl:      ttl,
+	}
+}
+
+// Start начинает заполнение формы пользователем key, незавершенная форма пользователя отменяется
+func (s *Store) Start(key int64, form *Form, params map[string]string) Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.removeExpired(now)
+
+	session := Session{
+		Form:      form,
+		Values:    make(map[string]string, len(form.Steps)),
+		Params:    params,
+		UpdatedAt: now,
+	}
+	s.sessions[key] = session
+
+	return session
+}
+
+// Get возвращает незавершенную форму пользователя
+func (s *Store) Get(key int64) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(key, time.Now())
+}
+
+// Answer сохраняет ответ на текущий шаг и переходит к следующему.
+// При ошибке проверки шаг не меняется. После последнего шага форма удаляется из хранилища,
+// возвращенная сессия содержит все ответы
+func (s *Store) Answer(key int64, answer string) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session, ok := s.get(key, now)
+	if !ok {
+		return Session{}, ErrNoSession
+	}
+
+	step := session.Current()
+	value := strings.TrimSpace(answer)
+	switch {
+	case step.Optional && value == SkipValue:
+		value = ""
+	case value == "":
+		return session, ErrEmptyValue
+	case step.Validate != nil:
+		if err := step.Validate(value); err != nil {
+			return session, err
+		}
+	}
+
+	session.Values[step.Field] = value
+	session.Step++
+	session.UpdatedAt = now
+	if session.Done() {
+		delete(s.sessions, key)
+		return session, nil
+	}
+	s.sessions[key] = session
+
+	return session, nil
+}
+
+// Back возвращает пользователя к предыдущему шагу, его ответ сбрасывается
+func (s *Store) Back(key int64) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session, ok := s.get(key, now)
+	if !ok {
+		return Session{}, ErrNoSession
+	}
+
+	if session.Step == 0 {
+		return session, ErrFirstStep
+	}
+
+	session.Step--
+	delete(session.Values, session.Current().Field)
+	session.UpdatedAt = now
+	s.sessions[key] = session
+
+	return session, nil
+}
+
+// Cancel отменяет незавершенную форму пользователя
+func (s *Store) Cancel(key int64) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.get(key, time.Now())
+	delete(s.sessions, key)
+
+	return session, ok
+}
+
+// get возвращает неистекшую форму, вызывается под блокировкой
+func (s *Store) get(key int64, now time.Time) (Session, bool) {
+	session, ok := s.sessions[key]
+	if !ok {
+		return Session{}, false
+	}
+
+	if now.Sub(session.UpdatedAt) > s.ttl {
+		delete(s.sessions, key)
+		return Session{}, false
+	}
+
+	return session, true
+}
+
+// removeExpired удаляет брошенные формы, вызывается под блокировкой
+func (s *Store) removeExpired(now time.Time) {
+	for key, session := range s.sessions {
+		if now.Sub(session.UpdatedAt) > s.ttl {
+			delete(s.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package dialog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errNotNumber = errors.New("not a number")
+
+func newTestForm() *Form {
+	return &Form{
+		Name:  "product",
+		Title: "Новый продукт",
+		Steps: []Step{
+			{Field: "name", Title: "Название"},
+			{Field: "description", Title: "Описание", Optional: true},
+			{
+				Field: "price",
+				Title: "Цена",
+				Validate: func(value string) error {
+					for _, r := range value {
+						if r < '0' || r > '9' {
+							return errNotNumber
+						}
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestStore_Answer(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	store.Start(1, newTestForm(), map[string]string{"product_id": "42"})
+
+	if _, err := store.Answer(1, "  "); !errors.Is(err, ErrEmptyValue) {
+		t.Fatalf("Answer(empty) error = %v, want %v", err, ErrEmptyValue)
+	}
+
+	s, err := store.Answer(1, " Курс ")
+	if err != nil || s.Step != 1 {
+		t.Fatalf("Answer(name) = step %d, %v, want step 1", s.Step, err)
+	}
+
+	s, err = store.Answer(1, SkipValue)
+	if err != nil || s.Step != 2 {
+		t.Fatalf("Answer(skip) = step %d, %v, want step 2", s.Step, err)
+	}
+
+	s, err = store.Answer(1, "abc")
+	if !errors.Is(err, errNotNumber) || s.Step != 2 {
+		t.Fatalf("Answer(invalid) = step %d, %v, want step 2, %v", s.Step, err, errNotNumber)
+	}
+
+	s, err = store.Answer(1, "100")
+	if err != nil || !s.Done() {
+		t.Fatalf("Answer(price) = done %v, %v, want done", s.Done(), err)
+	}
+
+	if s.Value("name") != "Курс" || s.Value("description") != "" || s.Value("price") != "100" {
+		t.Errorf("Values = %v", s.Values)
+	}
+
+	if s.Param("product_id") != "42" {
+		t.Errorf("Param(product_id) = %s, want 42", s.Param("product_id"))
+	}
+
+	if _, ok := store.Get(1); ok {
+		t.Errorf("Get() after done ok = true, want false")
+	}
+
+	if _, err = store.Answer(1, "100"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Answer() after done error = %v, want %v", err, ErrNoSession)
+	}
+}
+
+func TestStore_SkipRequiredStep(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	store.Start(1, newTestForm(), nil)
+
+	// пропустить можно только необязательный шаг, на обязательном SkipValue - обычный ответ
+	s, err := store.Answer(1, SkipValue)
+	if err != nil || s.Value("name") != SkipValue {
+		t.Errorf("Answer(skip) = %q, %v, want %q", s.Value("name"), err, SkipValue)
+	}
+}
+
+func TestStore_Back(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	store.Start(1, newTestForm(), nil)
+
+	if _, err := store.Back(1); !errors.Is(err, ErrFirstStep) {
+		t.Fatalf("Back() on first step error = %v, want %v", err, ErrFirstStep)
+	}
+
+	if _, err := store.Answer(1, "Курс"); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+
+	s, err := store.Back(1)
+	if err != nil || s.Step != 0 {
+		t.Fatalf("Back() = step %d, %v, want step 0", s.Step, err)
+	}
+
+	if _, ok := s.Values["name"]; ok {
+		t.Errorf("Back() kept answer of the previous step")
+	}
+
+	if _, err = store.Back(2); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Back() without session error = %v, want %v", err, ErrNoSession)
+	}
+}
+
+func TestStore_Cancel(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	form := newTestForm()
+	store.Start(1, form, nil)
+
+	s, ok := store.Cancel(1)
+	if !ok || s.Form != form {
+		t.Fatalf("Cancel() = %v, %v, want form", s.Form, ok)
+	}
+
+	if _, ok = store.Get(1); ok {
+		t.Errorf("Get() after cancel ok = true, want false")
+	}
+
+	if _, ok = store.Cancel(1); ok {
+		t.Errorf("Cancel() twice ok = true, want false")
+	}
+}
+
+func TestStore_Restart(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	store.Start(1, newTestForm(), nil)
+	if _, err := store.Answer(1, "Курс"); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+
+	store.Start(1, newTestForm(), nil)
+	s, ok := store.Get(1)
+	if !ok || s.Step != 0 || len(s.Values) != 0 {
+		t.Errorf("Get() after restart = step %d, values %v, %v", s.Step, s.Values, ok)
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	t.Parallel()
+	store := NewStore(time.Hour)
+	store.Start(1, newTestForm(), nil)
+	store.Start(2, newTestForm(), nil)
+
+	// форма без ответов дольше ttl считается отмененной
+	store.mu.Lock()
+	for key, s := range store.sessions {
+		s.UpdatedAt = s.UpdatedAt.Add(-2 * time.Hour)
+		store.sessions[key] = s
+	}
+	store.mu.Unlock()
+
+	if _, ok := store.Get(1); ok {
+		t.Errorf("Get() expired ok = true, want false")
+	}
+
+	if _, err := store.Answer(2, "Курс"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Answer() expired error = %v, want %v", err, ErrNoSession)
+	}
+
+	store.Start(3, newTestForm(), nil)
+	store.mu.Lock()
+	left := len(store.sessions)
+	store.mu.Unlock()
+	if left != 1 {
+		t.Errorf("sessions after Start = %d, want 1", left)
+	}
+}
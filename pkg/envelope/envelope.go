@@ -0,0 +1,179 @@
+// Package envelope реализует конвертное шифрование:
+// - данные шифруются одноразовым ключом данных (AES-256-GCM);
+// - ключ данных шифруется мастер-ключом из связки ключей;
+// - ротация мастер-ключа сводится к перешифрованию ключей данных.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// keySize размер ключа AES-256
+const keySize = 32
+
+var (
+	// ErrUnknownKey мастер-ключ отсутствует в связке
+	ErrUnknownKey = errors.New("unknown master key")
+	// ErrInvalidKey некорректный мастер-ключ
+	ErrInvalidKey = errors.New("invalid master key")
+)
+
+// Sealed зашифрованные данные
+type Sealed struct {
+	// KeyID идентификатор мастер-ключа, которым зашифрован ключ данных
+	KeyID string
+	// EncryptedKey зашифрованный ключ данных
+	EncryptedKey []byte
+	// Data зашифрованные данные
+	Data []byte
+}
+
+// Keyring связка мастер-ключей
+type Keyring struct {
+	keys     map[string][]byte
+	activeID string
+}
+
+// NewKeyring создает связку ключей из ключей в base64.
+// activeID - ключ, которым шифруются новые данные
+func NewKeyring(keys map[string]string, activeID string) (*Keyring, error) {
+	k := &Keyring{
+		keys:     make(map[string][]byte, len(keys)),
+		activeID: activeID,
+	}
+
+	for id, encoded := range keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidKey, id, err)
+		}
+
+		if len(key) != keySize {
+			return nil, fmt.Errorf("%w %s: key must be %d bytes", ErrInvalidKey, id, keySize)
+		}
+
+		k.keys[id] = key
+	}
+
+	if _, ok := k.keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, activeID)
+	}
+
+	return k, nil
+}
+
+// ActiveKeyID возвращает идентификатор активного мастер-ключа
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Seal шифрует данные новым ключом данных
+func (k *Keyring) Seal(plaintext []byte) (Sealed, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, err
+	}
+
+	data, err := encrypt(dataKey, plaintext)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	encryptedKey, err := encrypt(k.keys[k.activeID], dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{
+		KeyID:        k.activeID,
+		EncryptedKey: encryptedKey,
+		Data:         data,
+	}, nil
+}
+
+// Open расшифровывает данные
+func (k *Keyring) Open(sealed Sealed) ([]byte, error) {
+	dataKey, err := k.openDataKey(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(dataKey, sealed.Data)
+}
+
+// Rewrap перешифровывает ключ данных активным мастер-ключом,
+// сами данные при этом не изменяются
+func (k *Keyring) Rewrap(sealed Sealed) (Sealed, error) {
+	if sealed.KeyID == k.activeID {
+		return sealed, nil
+	}
+
+	dataKey, err := k.openDataKey(sealed)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	encryptedKey, err := encrypt(k.keys[k.activeID], dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{
+		KeyID:        k.activeID,
+		EncryptedKey: encryptedKey,
+		Data:         sealed.Data,
+	}, nil
+}
+
+func (k *Keyring) openDataKey(sealed Sealed) ([]byte, error) {
+	masterKey, ok := k.keys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, sealed.KeyID)
+	}
+
+	return decrypt(masterKey, sealed.EncryptedKey)
+}
+
+// encrypt шифрует AES-GCM, nonce помещается в начало результата
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decrypt расшифровывает результат encrypt
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
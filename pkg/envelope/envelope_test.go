@@ -0,0 +1,131 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, keySize))
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	t.Parallel()
+	k, err := NewKeyring(map[string]string{"v1": testKey(1)}, "v1")
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	plaintext := []byte("secret-license-key")
+	sealed, err := k.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	if bytes.Contains(sealed.Data, plaintext) {
+		t.Errorf("Seal() data contains plaintext")
+	}
+
+	got, err := k.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() = %s, want %s", got, plaintext)
+	}
+}
+
+func TestKeyring_Rewrap(t *testing.T) {
+	t.Parallel()
+	oldRing, err := NewKeyring(map[string]string{"v1": testKey(1)}, "v1")
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	sealed, err := oldRing.Seal([]byte("payload"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	newRing, err := NewKeyring(map[string]string{"v1": testKey(1), "v2": testKey(2)}, "v2")
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	rewrapped, err := newRing.Rewrap(sealed)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+
+	if rewrapped.KeyID != "v2" {
+		t.Errorf("Rewrap() keyID = %s, want v2", rewrapped.KeyID)
+	}
+
+	if !bytes.Equal(rewrapped.Data, sealed.Data) {
+		t.Errorf("Rewrap() changed data")
+	}
+
+	onlyNew, err := NewKeyring(map[string]string{"v2": testKey(2)}, "v2")
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	got, err := onlyNew.Open(rewrapped)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if string(got) != "payload" {
+		t.Errorf("Open() = %s, want payload", got)
+	}
+
+	if _, err = onlyNew.Open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		keys     map[string]string
+		activeID string
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			keys:     map[string]string{"v1": testKey(1)},
+			activeID: "v1",
+		},
+		{
+			name:     "unknown active key",
+			keys:     map[string]string{"v1": testKey(1)},
+			activeID: "v2",
+			wantErr:  ErrUnknownKey,
+		},
+		{
+			name:     "short key",
+			keys:     map[string]string{"v1": base64.StdEncoding.EncodeToString([]byte("short"))},
+			activeID: "v1",
+			wantErr:  ErrInvalidKey,
+		},
+		{
+			name:     "not base64",
+			keys:     map[string]string{"v1": "%%%"},
+			activeID: "v1",
+			wantErr:  ErrInvalidKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewKeyring(tt.keys, tt.activeID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewKeyring() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}